@@ -1,62 +1,240 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	_ "time/tzdata" // Restaurant time zones, which the image does not ship
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"github.com/restaurant_ordering_service/internal/api"
+	"github.com/restaurant_ordering_service/internal/config"
 	"github.com/restaurant_ordering_service/internal/db"
+	"github.com/restaurant_ordering_service/internal/grpcapi"
 	"github.com/restaurant_ordering_service/internal/kafka"
+	"github.com/restaurant_ordering_service/internal/metrics"
 	"github.com/restaurant_ordering_service/internal/middleware"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/orderstream"
+	"github.com/restaurant_ordering_service/internal/repository/postgres"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
+	"github.com/shared/health"
+	"github.com/shared/httpmw"
+	"github.com/shared/logging"
+	"github.com/shared/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"google.golang.org/grpc"
 )
 
+// publisherBacklogThreshold is the number of unpublished order events above
+// which the service reports itself as degraded
+const publisherBacklogThreshold = 100
+
+// serviceName identifies this service in exported spans
+const serviceName = "restaurant-service"
+
 func main() {
 	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+	envErr := godotenv.Load()
+
+	// Set up structured logging once the log levels are known
+	logging.Setup()
+	logger := logging.For(logging.ComponentApp)
+	if envErr != nil {
+		logger.Info("No .env file found, using environment variables")
+	}
+
+	// Load and validate the configuration before touching any dependency
+	cfg, err := config.Load()
+	if err != nil {
+		logging.Fatal(logger, "Invalid configuration", "error", err)
+	}
+	logger.Info("Effective configuration", cfg.Summary()...)
+
+	// "migrate" runs schema migrations without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			logging.Fatal(logger, "Migration failed", "error", err)
+		}
+		return
 	}
 
-	// Set default values for environment variables if not set
-	if os.Getenv("PORT") == "" {
-		os.Setenv("PORT", "8080")
+	// "service-token" prints a token for calling the gRPC API
+	if len(os.Args) > 1 && os.Args[1] == "service-token" {
+		if err := runServiceToken(cfg, os.Args[2:]); err != nil {
+			logging.Fatal(logger, "Could not create a service token", "error", err)
+		}
+		return
 	}
-	if os.Getenv("JWT_SECRET") == "" {
-		os.Setenv("JWT_SECRET", "your-secret-key")
+
+	// Initialize tracing before anything that creates spans
+	shutdownTracing, err := tracing.Init(context.Background(), serviceName)
+	if err != nil {
+		logging.Fatal(logger, "Failed to initialize tracing", "error", err)
 	}
 
 	// Initialize database connection
-	db.InitDB()
+	db.InitDB(cfg.Database)
 
-	// Create tables and seed data
-	db.CreateTables()
-	db.SeedData()
+	// Bring the schema up to date unless migrations are run separately
+	if cfg.MigrateOnStart {
+		migrateOnStart(cfg)
+	}
 
 	// Initialize Kafka
-	kafka.InitKafka()
-	defer kafka.CloseKafka()
+	kafka.InitKafka(cfg.Kafka)
 
 	// Set up Gin router
-	router := gin.Default()
-
-	// Define API routes
-	router.POST("/auth", api.AuthHandler)
-	router.GET("/food-items", api.GetFoodItemsHandler)
-
-	// Protected routes
-	authorized := router.Group("/")
-	authorized.Use(middleware.AuthMiddleware())
-	{
-		authorized.GET("/profile", api.GetUserProfileHandler)
-		authorized.POST("/orders", api.PlaceOrderHandler)
-		authorized.POST("/transactions", api.HandleTransactionHandler)
-	}
-
-	// Start the server
-	port := os.Getenv("PORT")
-	log.Printf("Restaurant Ordering Service starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(httpmw.RequestID())
+	router.Use(otelgin.Middleware(serviceName))
+	router.Use(metrics.Middleware())
+	router.Use(httpmw.AccessLog())
+	router.Use(apperrors.Middleware())
+
+	// Prometheus metrics
+	metrics.RegisterDB(db.DB)
+	metrics.RegisterKafka()
+
+	// Build the service layer on top of the Postgres repositories. The REST
+	// handlers and the gRPC server share it.
+	repos := postgres.NewRepos(db.DB, cfg.Database.QueryTimeout)
+	uow := postgres.NewUnitOfWork(db.DB, cfg.Database.QueryTimeout, cfg.Database.TxTimeout)
+	restaurants := service.NewRestaurants(uow, repos.Restaurants)
+	inventory := service.NewInventory(uow, repos.Inventory, repos.Menu, repos.Alerts, kafka.PublishInventoryEventsAsync)
+	menu := service.NewMenu(repos.Menu, repos.Restaurants, repos.Inventory)
+	orders := service.NewOrders(uow, repos.Orders, inventory, kafka.PublishOrderEventAsync)
+
+	// Fan the order events of every replica out to the order status streams
+	// and, once paid, to the kitchen displays, and the inventory events out
+	// to the stock alert streams
+	hub := orderstream.NewHub(cfg.Stream)
+	tickets := orderstream.NewTicketFeed(cfg.Stream.MaxConnections)
+	alerts := orderstream.NewAlertFeed(cfg.Stream.MaxConnections)
+	kitchen := service.NewKitchen(repos.Kitchen, tickets.Publish)
+	streamCtx, stopStream := context.WithCancel(context.Background())
+	defer stopStream()
+	kafka.InitEventStream(streamCtx, kafka.StreamHandlers{
+		Order: func(event models.OrderEvent) {
+			hub.Publish(event)
+			kitchen.HandleOrderEvent(streamCtx, event)
+		},
+		Inventory: alerts.Publish,
+	})
+
+	// Set up the gRPC server and its HTTP gateway
+	grpcAPI := grpcapi.NewServer(restaurants, menu, orders, hub, cfg.DefaultRestaurant)
+	grpcServer, grpcHealth := grpcapi.NewGRPCServer(cfg.GRPC, grpcAPI)
+	grpcListener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.GRPC.Port))
+	if err != nil {
+		logging.Fatal(logger, "Failed to listen for gRPC", "port", cfg.GRPC.Port, "error", err)
+	}
+	gatewayCtx, stopGateway := context.WithCancel(context.Background())
+	defer stopGateway()
+	gateway, err := grpcapi.Gateway(gatewayCtx, "localhost:"+strconv.Itoa(cfg.GRPC.Port))
+	if err != nil {
+		logging.Fatal(logger, "Failed to set up the gRPC gateway", "error", err)
+	}
+
+	// Register the handlers together with their OpenAPI description
+	api.RegisterRoutes(router, api.Handlers{
+		Auth:        api.NewAuthHandler(repos.Users, cfg.JWTSecret.Reveal()),
+		Restaurants: api.NewRestaurantHandler(restaurants),
+		Menu:        api.NewMenuHandler(menu),
+		Orders:      api.NewOrderHandler(orders),
+		Events:      api.NewEventsHandler(orders, hub, cfg.Stream.HeartbeatInterval),
+		Kitchen:     api.NewKitchenHandler(kitchen, tickets, cfg.Stream.HeartbeatInterval),
+		Inventory:   api.NewInventoryHandler(inventory, alerts, cfg.Stream.HeartbeatInterval),
+		Readiness: health.ReadinessHandler(
+			health.DatabaseCheck(db.DB),
+			health.KafkaBrokerCheck(kafka.Ping),
+			health.PublisherBacklogCheck(kafka.PendingPublishes, publisherBacklogThreshold),
+		),
+		Metrics:             metrics.Handler(),
+		RequireAuth:         middleware.AuthMiddleware(cfg.JWTSecret.Reveal()),
+		RestaurantFromPath:  middleware.RestaurantFromPath(restaurants.Resolve),
+		RestaurantFromToken: middleware.RestaurantFromToken(restaurants.Resolve),
+		DefaultRestaurant:   middleware.DefaultRestaurant(restaurants.Resolve, cfg.DefaultRestaurant),
+		Gateway:             gin.WrapH(gateway),
+	}, cfg.API.Versions)
+
+	// Start the servers
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.Port),
+		Handler: router,
+	}
+
+	go func() {
+		logger.Info("Restaurant Ordering Service starting", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal(logger, "Failed to start server", "error", err)
+		}
+	}()
+
+	go func() {
+		logger.Info("gRPC server starting", "port", cfg.GRPC.Port)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logging.Fatal(logger, "Failed to start gRPC server", "error", err)
+		}
+	}()
+
+	// Wait for a termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	logger.Info("Shutting down, waiting for in-flight work", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting requests and drain the in-flight ones. Status streams,
+	// including the gRPC ones relayed by the gateway, are ended first by
+	// closing the hub since they would otherwise stay open until the timeout.
+	grpcHealth.Shutdown()
+	hub.Close()
+	tickets.Close()
+	alerts.Close()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error shutting down server", "error", err)
+	}
+	stopGateway()
+	stopGRPC(shutdownCtx, grpcServer)
+
+	// Stop feeding the streams, flush pending order events, then release the
+	// database pool
+	stopStream()
+	kafka.CloseEventStream(shutdownCtx)
+	kafka.CloseKafka(shutdownCtx)
+	db.CloseDB()
+
+	// Export the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Error shutting down tracing", "error", err)
+	}
+
+	logger.Info("Restaurant Ordering Service stopped")
+}
+
+// stopGRPC lets in-flight calls finish, cancelling those still running when
+// ctx ends
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
 	}
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/restaurant_ordering_service/internal/db"
+	"github.com/restaurant_ordering_service/internal/migrations"
+	"github.com/shared/logging"
+	"github.com/shared/migrate"
+)
+
+const migrateUsage = `usage: main migrate [-dry-run] <command>
+
+commands:
+` + migrate.Commands + `
+  seed        insert development seed data (dev profile only)
+
+flags:
+  -dry-run    print the SQL that up, down or to would run without executing it`
+
+// runMigrate implements the "migrate" subcommand
+func runMigrate(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() {}
+	dryRun := flags.Bool("dry-run", false, "")
+	if err := flags.Parse(args); err != nil {
+		return errors.New(migrateUsage)
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db.InitDB(cfg.Database)
+	defer db.CloseDB()
+
+	if args[0] == "seed" {
+		if cfg.Profile != config.ProfileDev {
+			return fmt.Errorf("seed data can only be loaded with APP_ENV=%s", config.ProfileDev)
+		}
+		db.SeedData()
+		return nil
+	}
+
+	migrator, err := migrations.New(db.DB)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		migrator.DryRun(os.Stdout)
+	}
+
+	err = migrate.Run(context.Background(), migrator, args, os.Stdout)
+	if errors.Is(err, migrate.ErrUsage) {
+		return errors.New(migrateUsage)
+	}
+	return err
+}
+
+// migrateOnStart applies pending migrations before serving traffic, and
+// loads seed data in the dev profile
+func migrateOnStart(cfg config.Config) {
+	logger := logging.For(logging.ComponentDB)
+
+	migrator, err := migrations.New(db.DB)
+	if err != nil {
+		logging.Fatal(logger, "Failed to load migrations", "error", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		logging.Fatal(logger, "Failed to apply migrations", "error", err)
+	}
+
+	if cfg.Profile == config.ProfileDev {
+		db.SeedData()
+	}
+}
@@ -0,0 +1,16 @@
+// Command openapi-gen writes the OpenAPI document of the service and the Go
+// client generated from it. With -check it fails instead if either file is
+// out of date, so that CI catches routes changed without regenerating.
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/api"
+	"github.com/shared/openapi"
+)
+
+func main() {
+	// Register the routes without handlers; only their description is needed
+	gin.SetMode(gin.ReleaseMode)
+	openapi.Main(api.RegisterRoutes(gin.New(), api.Handlers{}, nil).Document(), api.LatestVersion)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/restaurant_ordering_service/internal/grpcapi"
+	"github.com/restaurant_ordering_service/pkg/servicetoken"
+)
+
+const serviceTokenUsage = `usage: main service-token [-ttl DURATION] SERVICE
+
+Prints a token that lets SERVICE call the internal gRPC API, for example
+from grpcurl or a test script. Services should mint their own tokens with
+the servicetoken package instead.`
+
+// runServiceToken implements the "service-token" subcommand
+func runServiceToken(cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("service-token", flag.ContinueOnError)
+	ttl := flags.Duration("ttl", time.Hour, "how long the token is valid")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New(serviceTokenUsage)
+	}
+
+	token, err := servicetoken.Sign([]byte(cfg.GRPC.ServiceTokenSecret.Reveal()), flags.Arg(0), grpcapi.Audience, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
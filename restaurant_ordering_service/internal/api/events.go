@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/orderstream"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/logging"
+	"golang.org/x/net/websocket"
+)
+
+// sseRetry is how long EventSource clients wait before reconnecting
+const sseRetry = 3 * time.Second
+
+// EventsHandler streams the status of an order to its owner over
+// Server-Sent Events or WebSocket
+type EventsHandler struct {
+	orders    *service.Orders
+	hub       *orderstream.Hub
+	heartbeat time.Duration
+}
+
+// NewEventsHandler creates an EventsHandler sending a heartbeat to idle
+// streams every heartbeat
+func NewEventsHandler(orders *service.Orders, hub *orderstream.Hub, heartbeat time.Duration) *EventsHandler {
+	return &EventsHandler{orders: orders, hub: hub, heartbeat: heartbeat}
+}
+
+// orderStream is an order status stream being served to a client
+type orderStream struct {
+	sub     *orderstream.Subscription
+	pending []models.OrderStatusEvent // Sent before the live events
+	sent    map[string]bool           // IDs of the events the client has
+	final   bool                      // The order will not change again
+}
+
+// SSE streams the status of an order as Server-Sent Events. The current
+// status is sent first unless the client resumes with Last-Event-ID, in
+// which case only the events it missed are sent. The stream ends after a
+// final status; a client that already has it is answered with 204 so that
+// EventSource stops reconnecting.
+func (h *EventsHandler) SSE(c *gin.Context) {
+	// EventSource sends Last-Event-ID when reconnecting, but cannot set it on
+	// the first connection
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	stream, ok := h.open(c, lastEventID)
+	if !ok {
+		return
+	}
+	defer stream.sub.Close()
+
+	if stream.final && len(stream.pending) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	metrics.OrderStreams.WithLabelValues("sse").Inc()
+	defer metrics.OrderStreams.WithLabelValues("sse").Dec()
+
+	sse, err := startSSE(c, h.heartbeat)
+	if err != nil {
+		return
+	}
+	err = h.run(c.Request.Context(), stream,
+		func(event models.OrderStatusEvent) error { return sse.event(event.EventID, "status", event) },
+		sse.heartbeat,
+	)
+	if err != nil {
+		logging.For(logging.ComponentHTTP).DebugContext(c.Request.Context(), "Order status stream ended", "transport", "sse", "error", err)
+	}
+}
+
+// WebSocket streams the status of an order over a WebSocket as JSON
+// messages of type status or heartbeat. Clients resume with the
+// last_event_id query parameter. The connection is closed after a final
+// status.
+func (h *EventsHandler) WebSocket(c *gin.Context) {
+	stream, ok := h.open(c, c.Query("last_event_id"))
+	if !ok {
+		return
+	}
+	defer stream.sub.Close()
+
+	// The origin is not checked since clients authenticate with a token
+	// rather than cookies, so other sites cannot act on their behalf
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		metrics.OrderStreams.WithLabelValues("websocket").Inc()
+		defer metrics.OrderStreams.WithLabelValues("websocket").Dec()
+
+		// The connection is hijacked, so reading is the only way to notice
+		// that the client went away. Messages from the client are ignored.
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		go func() {
+			defer cancel()
+			var discard []byte
+			for websocket.Message.Receive(ws, &discard) == nil {
+			}
+		}()
+
+		send := func(message models.StreamMessage) error {
+			if err := ws.SetWriteDeadline(time.Now().Add(h.heartbeat)); err != nil {
+				return err
+			}
+			return websocket.JSON.Send(ws, message)
+		}
+		err := h.run(ctx, stream,
+			func(event models.OrderStatusEvent) error {
+				return send(models.StreamMessage{Type: "status", Event: &event})
+			},
+			func() error { return send(models.StreamMessage{Type: "heartbeat"}) },
+		)
+		if err != nil {
+			logging.For(logging.ComponentHTTP).DebugContext(ctx, "Order status stream ended", "transport", "websocket", "error", err)
+		}
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// open subscribes to the order in the path on behalf of the authenticated
+// user and works out what the client missed. It reports false after
+// attaching the error if the stream cannot be served.
+func (h *EventsHandler) open(c *gin.Context, lastEventID string) (*orderStream, bool) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || orderID < 1 {
+		c.Error(errInvalidOrderID())
+		return nil, false
+	}
+	userID := c.MustGet("user_id").(int)
+
+	// Subscribe before reading the order so that a change in between is
+	// not missed
+	sub, replay, resumed, err := h.hub.Subscribe(orderID, userID, lastEventID)
+	if err != nil {
+		c.Error(err)
+		return nil, false
+	}
+	order, err := h.orders.GetOwned(c.Request.Context(), userID, orderID)
+	if err != nil {
+		sub.Close()
+		c.Error(err)
+		return nil, false
+	}
+
+	stream := &orderStream{sub: sub, sent: make(map[string]bool), final: service.IsFinal(order.Status)}
+	if lastEventID != "" {
+		stream.sent[lastEventID] = true
+	}
+
+	// A client that is not resuming starts from the current status. A final
+	// status is always sent in case the event has not reached the hub yet;
+	// duplicates are skipped either way.
+	events := replay
+	if !resumed || stream.final {
+		events = append(events, models.OrderStatusEvent{
+			EventID:   order.StatusEventID(),
+			OrderID:   order.ID,
+			Status:    order.Status,
+			Timestamp: time.Now().Unix(),
+		})
+	}
+	for _, event := range events {
+		if !stream.sent[event.EventID] && !slices.ContainsFunc(stream.pending, func(e models.OrderStatusEvent) bool { return e.EventID == event.EventID }) {
+			stream.pending = append(stream.pending, event)
+		}
+	}
+	return stream, true
+}
+
+// run sends the pending and then the live events of stream, with a
+// heartbeat whenever it has been idle, until the order reaches a final
+// status, the hub ends the subscription or ctx is done
+func (h *EventsHandler) run(ctx context.Context, stream *orderStream, send func(models.OrderStatusEvent) error, heartbeat func() error) error {
+	deliver := func(event models.OrderStatusEvent) (bool, error) {
+		if stream.sent[event.EventID] {
+			return false, nil
+		}
+		stream.sent[event.EventID] = true
+		if err := send(event); err != nil {
+			return false, err
+		}
+		return service.IsFinal(event.Status), nil
+	}
+
+	for _, event := range stream.pending {
+		if final, err := deliver(event); final || err != nil {
+			return err
+		}
+	}
+	if stream.final {
+		return nil
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-stream.sub.Events():
+			if !ok {
+				return errStreamEnded
+			}
+			if final, err := deliver(event); final || err != nil {
+				return err
+			}
+			ticker.Reset(h.heartbeat)
+		case <-ticker.C:
+			if err := heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// errStreamEnded reports a stream ended by the hub, after which the client
+// should reconnect and resume
+var errStreamEnded = errors.New("stream ended by the server")
+
+// sseWriter writes Server-Sent Events to a client
+type sseWriter struct {
+	c       *gin.Context
+	rc      *http.ResponseController
+	timeout time.Duration
+}
+
+// startSSE responds with an event stream, telling the client how long to
+// wait before reconnecting. Each later write must finish within timeout, so
+// that a client that stopped reading cannot hold the handler forever.
+func startSSE(c *gin.Context, timeout time.Duration) (*sseWriter, error) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Keep proxies from buffering the events
+	c.Status(http.StatusOK)
+
+	w := &sseWriter{c: c, rc: http.NewResponseController(c.Writer), timeout: timeout}
+	return w, w.write("retry: %d\n\n", sseRetry.Milliseconds())
+}
+
+// event sends data encoded as JSON in an event of the given type. Events
+// without an ID are not resumed after.
+func (w *sseWriter) event(id, name string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		return w.write("id: %s\nevent: %s\ndata: %s\n\n", id, name, encoded)
+	}
+	return w.write("event: %s\ndata: %s\n\n", name, encoded)
+}
+
+// heartbeat sends a comment, which keeps proxies from closing an idle stream
+func (w *sseWriter) heartbeat() error {
+	return w.write(": heartbeat\n\n")
+}
+
+// write sends raw stream data and flushes it to the client
+func (w *sseWriter) write(format string, args ...any) error {
+	if err := w.rc.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	if _, err := fmt.Fprintf(w.c.Writer, format, args...); err != nil {
+		return err
+	}
+	return w.rc.Flush()
+}
@@ -1,76 +1,71 @@
 package api
 
 import (
-	"database/sql"
-	"log"
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/restaurant_ordering_service/internal/db"
-	"github.com/restaurant_ordering_service/internal/kafka"
 	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
 )
 
-// AuthHandler handles user authentication
-func AuthHandler(c *gin.Context) {
+// AuthHandler serves authentication and the user profile
+type AuthHandler struct {
+	users     repository.UserRepo
+	jwtSecret string
+}
+
+// NewAuthHandler creates an AuthHandler issuing tokens signed with jwtSecret
+func NewAuthHandler(users repository.UserRepo, jwtSecret string) *AuthHandler {
+	return &AuthHandler{users: users, jwtSecret: jwtSecret}
+}
+
+// Login handles user authentication
+func (h *AuthHandler) Login(c *gin.Context) {
 	var loginRequest models.LoginRequest
 	if err := c.ShouldBindJSON(&loginRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
+		c.Error(apperrors.FromBinding(err))
 		return
 	}
 
 	// Find the user in the database
-	var user models.User
-	err := db.DB.QueryRow(
-		"SELECT id, username, password, email, address FROM users WHERE username = $1",
-		loginRequest.Username,
-	).Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Address)
-
+	user, err := h.users.GetByUsername(c.Request.Context(), loginRequest.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Invalid username or password",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Error(apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid username or password"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Database error",
-		})
+		c.Error(err)
 		return
 	}
 
 	// Check if the password is correct
 	if user.Password != loginRequest.Password {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
-			Success: false,
-			Message: "Invalid username or password",
-		})
+		c.Error(apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid username or password"))
 		return
 	}
 
 	// Create a JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"role":     user.Role,
 		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token expires in 24 hours
-	})
+	}
+	if user.RestaurantID != 0 {
+		claims["restaurant_id"] = user.RestaurantID
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenString, err := token.SignedString([]byte(h.jwtSecret))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not generate token",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
@@ -84,407 +79,234 @@ func AuthHandler(c *gin.Context) {
 	})
 }
 
-// GetFoodItemsHandler returns a list of food items
-func GetFoodItemsHandler(c *gin.Context) {
-	rows, err := db.DB.Query("SELECT id, name, price, quantity FROM food_items")
+// Profile returns the profile of the authenticated user
+func (h *AuthHandler) Profile(c *gin.Context) {
+	// Get the user ID from the token
+	userID := c.MustGet("user_id").(int)
+
+	// Get the user from the database
+	user, err := h.users.GetByID(c.Request.Context(), userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error retrieving food items",
-		})
-		return
-	}
-	defer rows.Close()
-
-	var foodItems []models.FoodItem
-	for rows.Next() {
-		var item models.FoodItem
-		if err := rows.Scan(&item.ID, &item.Name, &item.Price, &item.Quantity); err != nil {
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Error scanning food items",
-			})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Error(apperrors.NotFound(apperrors.CodeUserNotFound, "User not found"))
 			return
 		}
-		foodItems = append(foodItems, item)
+		c.Error(err)
+		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Message: "Food items retrieved successfully",
-		Data:    foodItems,
+		Message: "User profile retrieved successfully",
+		Data:    user,
 	})
 }
 
-// GetUserProfileHandler returns the profile of the authenticated user
-func GetUserProfileHandler(c *gin.Context) {
-	// Get the user ID from the token
-	userID := c.MustGet("user_id").(int)
+// RestaurantHandler serves the restaurants
+type RestaurantHandler struct {
+	restaurants *service.Restaurants
+}
 
-	// Get the user from the database
-	var user models.User
-	err := db.DB.QueryRow(
-		"SELECT id, username, email, address FROM users WHERE id = $1",
-		userID,
-	).Scan(&user.ID, &user.Username, &user.Email, &user.Address)
+// NewRestaurantHandler creates a RestaurantHandler
+func NewRestaurantHandler(restaurants *service.Restaurants) *RestaurantHandler {
+	return &RestaurantHandler{restaurants: restaurants}
+}
 
+// List returns every restaurant
+func (h *RestaurantHandler) List(c *gin.Context) {
+	restaurants, err := h.restaurants.List(c.Request.Context())
 	if err != nil {
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.APIResponse{
-				Success: false,
-				Message: "User not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Database error",
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
-		Message: "User profile retrieved successfully",
-		Data:    user,
+		Message: "Restaurants retrieved successfully",
+		Data:    restaurants,
 	})
 }
 
-// PlaceOrderHandler handles placing an order
-func PlaceOrderHandler(c *gin.Context) {
-	var orderRequest models.OrderRequest
-	if err := c.ShouldBindJSON(&orderRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
-		return
-	}
+// Get returns the restaurant in the path with its settings
+func (h *RestaurantHandler) Get(c *gin.Context) {
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Restaurant retrieved successfully",
+		Data:    restaurantOf(c),
+	})
+}
 
-	// Get the user ID from the token
-	userID := c.MustGet("user_id").(int)
+// MenuHandler serves the food items and bundles
+type MenuHandler struct {
+	menu *service.Menu
+}
+
+// NewMenuHandler creates a MenuHandler
+func NewMenuHandler(menu *service.Menu) *MenuHandler {
+	return &MenuHandler{menu: menu}
+}
 
-	// Start a transaction
-	tx, err := db.DB.Begin()
+// List returns the food items of the restaurant, flagging those that cannot
+// be ordered now
+func (h *MenuHandler) List(c *gin.Context) {
+	foodItems, err := h.menu.List(c.Request.Context(), restaurantOf(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not start transaction",
-		})
+		c.Error(err)
 		return
 	}
 
-	// Calculate total price and check if items exist
-	var totalPrice float64
-	foodItems := make(map[int]string) // Map to store food item names for event publishing
-
-	for _, item := range orderRequest.Items {
-		var price float64
-		var name string
-		err := tx.QueryRow(
-			"SELECT price, name FROM food_items WHERE id = $1",
-			item.FoodItemID,
-		).Scan(&price, &name)
-
-		if err != nil {
-			tx.Rollback()
-			if err == sql.ErrNoRows {
-				c.JSON(http.StatusNotFound, models.APIResponse{
-					Success: false,
-					Message: "Food item not found: " + strconv.Itoa(item.FoodItemID),
-				})
-				return
-			}
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Database error",
-			})
-			return
-		}
-
-		totalPrice += price * float64(item.Quantity)
-		foodItems[item.FoodItemID] = name
-	}
-
-	// Create the order
-	var orderID int
-	err = tx.QueryRow(
-		"INSERT INTO orders (user_id, total_price, status) VALUES ($1, $2, $3) RETURNING id",
-		userID, totalPrice, "pending",
-	).Scan(&orderID)
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Food items retrieved successfully",
+		Data:    foodItems,
+	})
+}
 
+// ListAll returns every food item of the restaurant of a staff member,
+// including those hidden from customers while sold out
+func (h *MenuHandler) ListAll(c *gin.Context) {
+	foodItems, err := h.menu.ListAll(c.Request.Context(), restaurantOf(c))
 	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not create order",
-		})
+		c.Error(err)
 		return
 	}
 
-	// Create order items
-	for _, item := range orderRequest.Items {
-		_, err := tx.Exec(
-			"INSERT INTO order_items (order_id, food_item_id, quantity) VALUES ($1, $2, $3)",
-			orderID, item.FoodItemID, item.Quantity,
-		)
-
-		if err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Could not create order items",
-			})
-			return
-		}
-	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Food items retrieved successfully",
+		Data:    foodItems,
+	})
+}
 
-	// Commit the transaction
-	err = tx.Commit()
+// Bundles returns the bundles of the restaurant, flagging those that cannot
+// be ordered now
+func (h *MenuHandler) Bundles(c *gin.Context) {
+	bundles, err := h.menu.Bundles(c.Request.Context(), restaurantOf(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not commit transaction",
-		})
+		c.Error(err)
 		return
 	}
 
-	// Construct the order object for the response
-	order := models.Order{
-		ID:         orderID,
-		UserID:     userID,
-		TotalPrice: totalPrice,
-		Status:     "pending",
-	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Bundles retrieved successfully",
+		Data:    bundles,
+	})
+}
 
-	// Publish order event to Kafka
-	orderItems := make([]models.OrderItem, 0, len(orderRequest.Items))
-	for _, item := range orderRequest.Items {
-		orderItems = append(orderItems, models.OrderItem{
-			OrderID:    orderID,
-			FoodItemID: item.FoodItemID,
-			Quantity:   item.Quantity,
-		})
-	}
-	order.OrderItems = orderItems
+// OrderHandler serves placing and paying for orders
+type OrderHandler struct {
+	orders *service.Orders
+}
 
-	go func() {
-		if err := kafka.PublishOrderEvent(order, foodItems); err != nil {
-			log.Printf("Failed to publish order event: %v", err)
-		}
-	}()
+// NewOrderHandler creates an OrderHandler
+func NewOrderHandler(orders *service.Orders) *OrderHandler {
+	return &OrderHandler{orders: orders}
+}
+
+// Place handles placing an order
+func (h *OrderHandler) Place(c *gin.Context) {
+	order, ok := h.place(c)
+	if !ok {
+		return
+	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Order placed successfully",
-		Data: gin.H{
-			"order_id":    orderID,
-			"total_price": totalPrice,
+		Data: models.OrderPlacedResponse{
+			OrderID:    order.ID,
+			TotalPrice: order.TotalPrice,
 		},
 	})
 }
 
-// HandleTransactionHandler handles a transaction for an order
-func HandleTransactionHandler(c *gin.Context) {
-	var transactionRequest models.TransactionRequest
-	if err := c.ShouldBindJSON(&transactionRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
+// PlaceV2 handles placing an order in version 2 of the API, which responds
+// with the created order
+func (h *OrderHandler) PlaceV2(c *gin.Context) {
+	order, ok := h.place(c)
+	if !ok {
 		return
 	}
 
-	// Start a transaction
-	tx, err := db.DB.Begin()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not start transaction",
-		})
-		return
-	}
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Order placed successfully",
+		Data:    order,
+	})
+}
 
-	// Get order details to verify ownership
-	var userID int
-	var status string
-	var orderItems []struct {
-		FoodItemID int
-		Quantity   int
-		Name       string
+// place creates the order described by the request at the restaurant of
+// the route. It reports false after attaching the error if the order was
+// not placed.
+func (h *OrderHandler) place(c *gin.Context) (models.Order, bool) {
+	var orderRequest models.OrderRequest
+	if err := c.ShouldBindJSON(&orderRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return models.Order{}, false
 	}
 
-	err = tx.QueryRow(
-		"SELECT user_id, status FROM orders WHERE id = $1",
-		transactionRequest.OrderID,
-	).Scan(&userID, &status)
+	// Get the user ID from the token
+	userID := c.MustGet("user_id").(int)
 
+	order, err := h.orders.Place(c.Request.Context(), userID, restaurantOf(c), orderRequest)
 	if err != nil {
-		tx.Rollback()
-		if err == sql.ErrNoRows {
-			c.JSON(http.StatusNotFound, models.APIResponse{
-				Success: false,
-				Message: "Order not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Database error",
-		})
-		return
-	}
-
-	// Ensure the order belongs to the authenticated user
-	authenticatedUserID := c.MustGet("user_id").(int)
-	if userID != authenticatedUserID {
-		tx.Rollback()
-		c.JSON(http.StatusForbidden, models.APIResponse{
-			Success: false,
-			Message: "You do not have permission to process this order",
-		})
-		return
+		c.Error(err)
+		return models.Order{}, false
 	}
+	return order, true
+}
 
-	// Ensure the order is in 'pending' status
-	if status != "pending" {
-		tx.Rollback()
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Order is not in pending status",
-		})
+// Get returns an order of the authenticated user with its items
+func (h *OrderHandler) Get(c *gin.Context) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || orderID < 1 {
+		c.Error(errInvalidOrderID())
 		return
 	}
 
-	// Get order items
-	rows, err := tx.Query(
-		"SELECT oi.food_item_id, oi.quantity, fi.name FROM order_items oi JOIN food_items fi ON oi.food_item_id = fi.id WHERE oi.order_id = $1",
-		transactionRequest.OrderID,
-	)
+	order, err := h.orders.GetOwned(c.Request.Context(), c.MustGet("user_id").(int), orderID)
 	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not retrieve order items",
-		})
+		c.Error(err)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var item struct {
-			FoodItemID int
-			Quantity   int
-			Name       string
-		}
-		if err := rows.Scan(&item.FoodItemID, &item.Quantity, &item.Name); err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Error scanning order items",
-			})
-			return
-		}
-		orderItems = append(orderItems, item)
-	}
-
-	// Update food item quantities
-	foodItems := make(map[int]string) // For Kafka event
-	for _, item := range orderItems {
-		result, err := tx.Exec(
-			"UPDATE food_items SET quantity = quantity - $1 WHERE id = $2 AND quantity >= $1",
-			item.Quantity, item.FoodItemID,
-		)
-		if err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Error updating food item quantities",
-			})
-			return
-		}
-
-		// Check if any rows were affected
-		rowsAffected, err := result.RowsAffected()
-		if err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, models.APIResponse{
-				Success: false,
-				Message: "Error checking affected rows",
-			})
-			return
-		}
-		if rowsAffected == 0 {
-			tx.Rollback()
-			c.JSON(http.StatusBadRequest, models.APIResponse{
-				Success: false,
-				Message: "Not enough quantity for food item: " + item.Name,
-			})
-			return
-		}
-
-		foodItems[item.FoodItemID] = item.Name
-	}
 
-	// Update order status
-	_, err = tx.Exec(
-		"UPDATE orders SET status = $1 WHERE id = $2",
-		"completed", transactionRequest.OrderID,
-	)
-	if err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error updating order status",
-		})
-		return
-	}
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Order retrieved successfully",
+		Data:    order,
+	})
+}
 
-	// Commit the transaction
-	err = tx.Commit()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not commit transaction",
-		})
+// Pay handles a transaction for an order, taking its items out of stock and
+// completing it
+func (h *OrderHandler) Pay(c *gin.Context) {
+	var transactionRequest models.TransactionRequest
+	if err := c.ShouldBindJSON(&transactionRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
 		return
 	}
 
-	// Get the full order details for Kafka
-	var order models.Order
-	order.ID = transactionRequest.OrderID
-	order.UserID = userID
-	order.Status = "completed"
-
-	// Get total price
-	err = db.DB.QueryRow(
-		"SELECT total_price FROM orders WHERE id = $1",
-		transactionRequest.OrderID,
-	).Scan(&order.TotalPrice)
-	if err != nil {
-		log.Printf("Error getting order total price: %v", err)
-	}
+	authenticatedUserID := c.MustGet("user_id").(int)
 
-	// Convert order items for Kafka message
-	var orderItemsForEvent []models.OrderItem
-	for _, item := range orderItems {
-		orderItemsForEvent = append(orderItemsForEvent, models.OrderItem{
-			OrderID:    transactionRequest.OrderID,
-			FoodItemID: item.FoodItemID,
-			Quantity:   item.Quantity,
-		})
+	if _, err := h.orders.Pay(c.Request.Context(), authenticatedUserID, transactionRequest.OrderID); err != nil {
+		c.Error(err)
+		return
 	}
-	order.OrderItems = orderItemsForEvent
-
-	// Publish completed order event to Kafka asynchronously
-	go func() {
-		if err := kafka.PublishOrderEvent(order, foodItems); err != nil {
-			log.Printf("Failed to publish order completion event: %v", err)
-		}
-	}()
 
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Transaction completed successfully",
 	})
 }
+
+// restaurantOf returns the restaurant the request is for, as resolved by
+// the tenant middleware of the route
+func restaurantOf(c *gin.Context) models.Restaurant {
+	return c.MustGet("restaurant").(models.Restaurant)
+}
+
+// errInvalidOrderID reports an order ID path parameter that is not an ID
+func errInvalidOrderID() error {
+	return apperrors.Validation(models.FieldError{Field: "id", Message: "must be a positive integer"})
+}
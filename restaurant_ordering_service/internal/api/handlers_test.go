@@ -0,0 +1,206 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/middleware"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository/memory"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
+	"github.com/shared/response"
+)
+
+const testSecret = "test-secret"
+
+// testServer serves the routes over the in-memory repositories
+type testServer struct {
+	router     *gin.Engine
+	store      *memory.Store
+	restaurant models.Restaurant
+	foodItem   models.FoodItem
+}
+
+// newTestServer creates a server with a restaurant, two customers and a
+// food item with quantity in stock
+func newTestServer(t *testing.T, quantity int) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &testServer{store: memory.NewStore()}
+	s.restaurant = s.store.AddRestaurant(models.Restaurant{Slug: "main", Name: "Main"})
+	s.store.AddUser(models.User{Username: "alice", Password: "alice-password"})
+	s.store.AddUser(models.User{Username: "bob", Password: "bob-password"})
+	s.foodItem = s.store.AddFoodItem(models.FoodItem{RestaurantID: s.restaurant.ID, Name: "Dosa", Price: 10, Quantity: quantity})
+
+	repos := s.store.Repos()
+	restaurants := service.NewRestaurants(s.store, repos.Restaurants)
+	inventory := service.NewInventory(s.store, repos.Inventory, repos.Menu, repos.Alerts, func(context.Context, []models.InventoryEvent) {})
+	orders := service.NewOrders(s.store, repos.Orders, inventory, func(context.Context, models.Order, map[int]string) {})
+
+	s.router = gin.New()
+	s.router.Use(apperrors.Middleware())
+	RegisterRoutes(s.router, Handlers{
+		Auth:               NewAuthHandler(repos.Users, testSecret),
+		Restaurants:        NewRestaurantHandler(restaurants),
+		Menu:               NewMenuHandler(service.NewMenu(repos.Menu, repos.Restaurants, repos.Inventory)),
+		Orders:             NewOrderHandler(orders),
+		RequireAuth:        middleware.AuthMiddleware(testSecret),
+		RestaurantFromPath: middleware.RestaurantFromPath(restaurants.Resolve),
+		DefaultRestaurant:  middleware.DefaultRestaurant(restaurants.Resolve, s.restaurant.Slug),
+	}, nil)
+	return s
+}
+
+// do sends a request with body encoded as JSON, authenticated with token
+// unless it is empty, and decodes the response envelope
+func (s *testServer) do(t *testing.T, method, path, token string, body any) (int, response.APIResponse) {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var resp response.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+// login returns a token for the user
+func (s *testServer) login(t *testing.T, username, password string) string {
+	t.Helper()
+
+	status, resp := s.do(t, http.MethodPost, "/v1/auth", "", models.LoginRequest{Username: username, Password: password})
+	if status != http.StatusOK {
+		t.Fatalf("login as %s: status %d, %+v", username, status, resp.Error)
+	}
+	return resp.Data.(map[string]any)["token"].(string)
+}
+
+// errorCode returns the error code of a response, if it failed
+func errorCode(resp response.APIResponse) string {
+	if resp.Error == nil {
+		return ""
+	}
+	return resp.Error.Code
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	s := newTestServer(t, 10)
+
+	status, resp := s.do(t, http.MethodPost, "/v1/auth", "", models.LoginRequest{Username: "alice", Password: "wrong"})
+	if status != http.StatusUnauthorized || errorCode(resp) != string(apperrors.CodeInvalidCredentials) {
+		t.Errorf("status %d, code %q, want 401 %s", status, errorCode(resp), apperrors.CodeInvalidCredentials)
+	}
+}
+
+func TestProtectedRouteRequiresToken(t *testing.T) {
+	s := newTestServer(t, 10)
+
+	status, resp := s.do(t, http.MethodGet, "/v1/profile", "", nil)
+	if status != http.StatusUnauthorized || errorCode(resp) != string(apperrors.CodeUnauthorized) {
+		t.Errorf("status %d, code %q, want 401 %s", status, errorCode(resp), apperrors.CodeUnauthorized)
+	}
+}
+
+func TestPlaceAndPayOrder(t *testing.T) {
+	s := newTestServer(t, 10)
+	alice := s.login(t, "alice", "alice-password")
+	bob := s.login(t, "bob", "bob-password")
+	request := models.OrderRequest{Items: []models.OrderItemRequest{{FoodItemID: s.foodItem.ID, Quantity: 2}}}
+
+	status, resp := s.do(t, http.MethodPost, "/v1/restaurants/main/orders", alice, request)
+	if status != http.StatusOK {
+		t.Fatalf("place: status %d, %+v", status, resp.Error)
+	}
+	orderID := int(resp.Data.(map[string]any)["order_id"].(float64))
+
+	// Only the customer who placed the order may see it or pay for it
+	status, resp = s.do(t, http.MethodGet, "/v1/orders/"+strconv.Itoa(orderID), bob, nil)
+	if status != http.StatusForbidden || errorCode(resp) != string(apperrors.CodeOrderNotOwned) {
+		t.Errorf("get as another user: status %d, code %q, want 403 %s", status, errorCode(resp), apperrors.CodeOrderNotOwned)
+	}
+	status, resp = s.do(t, http.MethodPost, "/v1/transactions", bob, models.TransactionRequest{OrderID: orderID})
+	if status != http.StatusForbidden || errorCode(resp) != string(apperrors.CodeOrderNotOwned) {
+		t.Errorf("pay as another user: status %d, code %q, want 403 %s", status, errorCode(resp), apperrors.CodeOrderNotOwned)
+	}
+
+	status, resp = s.do(t, http.MethodPost, "/v1/transactions", alice, models.TransactionRequest{OrderID: orderID})
+	if status != http.StatusOK {
+		t.Fatalf("pay: status %d, %+v", status, resp.Error)
+	}
+	status, resp = s.do(t, http.MethodPost, "/v1/transactions", alice, models.TransactionRequest{OrderID: orderID})
+	if status != http.StatusBadRequest || errorCode(resp) != string(apperrors.CodeOrderNotPending) {
+		t.Errorf("pay again: status %d, code %q, want 400 %s", status, errorCode(resp), apperrors.CodeOrderNotPending)
+	}
+
+	status, resp = s.do(t, http.MethodGet, "/v1/orders/"+strconv.Itoa(orderID), alice, nil)
+	if status != http.StatusOK || resp.Data.(map[string]any)["status"] != "completed" {
+		t.Errorf("get: status %d, data %v, want a completed order", status, resp.Data)
+	}
+}
+
+func TestPlaceOrderV2RespondsWithOrder(t *testing.T) {
+	s := newTestServer(t, 10)
+	alice := s.login(t, "alice", "alice-password")
+
+	status, resp := s.do(t, http.MethodPost, "/v2/orders", alice, models.OrderRequest{
+		Items: []models.OrderItemRequest{{FoodItemID: s.foodItem.ID, Quantity: 1}},
+	})
+	if status != http.StatusCreated {
+		t.Fatalf("status %d, %+v, want 201", status, resp.Error)
+	}
+	order := resp.Data.(map[string]any)
+	if order["status"] != "pending" || order["restaurant_id"] != float64(s.restaurant.ID) {
+		t.Errorf("order %v, want a pending order at restaurant %d", order, s.restaurant.ID)
+	}
+}
+
+func TestPlaceOrderRejectsInvalidRequests(t *testing.T) {
+	s := newTestServer(t, 1)
+	alice := s.login(t, "alice", "alice-password")
+
+	tests := []struct {
+		name    string
+		request models.OrderRequest
+		status  int
+		code    apperrors.Code
+	}{
+		{"quantity below one", models.OrderRequest{
+			Items: []models.OrderItemRequest{{FoodItemID: s.foodItem.ID, Quantity: -1}},
+		}, http.StatusBadRequest, apperrors.CodeValidationFailed},
+		{"unknown food item", models.OrderRequest{
+			Items: []models.OrderItemRequest{{FoodItemID: 999, Quantity: 1}},
+		}, http.StatusNotFound, apperrors.CodeFoodItemNotFound},
+		{"more than in stock", models.OrderRequest{
+			Items: []models.OrderItemRequest{{FoodItemID: s.foodItem.ID, Quantity: 2}},
+		}, http.StatusBadRequest, apperrors.CodeOutOfStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := s.do(t, http.MethodPost, "/v1/orders", alice, tt.request)
+			if status != tt.status || errorCode(resp) != string(tt.code) {
+				t.Errorf("status %d, code %q, want %d %s", status, errorCode(resp), tt.status, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+// Hours returns the weekly hours of the restaurant and its upcoming
+// exceptions
+func (h *RestaurantHandler) Hours(c *gin.Context) {
+	hours, err := h.restaurants.Hours(c.Request.Context(), restaurantOf(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Opening hours retrieved successfully",
+		Data:    hours,
+	})
+}
+
+// Availability tells whether the restaurant takes orders now
+func (h *RestaurantHandler) Availability(c *gin.Context) {
+	availability, err := h.restaurants.Availability(c.Request.Context(), restaurantOf(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Availability retrieved successfully",
+		Data:    availability,
+	})
+}
+
+// SetHours replaces the weekly hours and last-order cutoff of the
+// restaurant of the staff member
+func (h *RestaurantHandler) SetHours(c *gin.Context) {
+	var hoursRequest models.HoursRequest
+	if err := c.ShouldBindJSON(&hoursRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	hours, err := h.restaurants.SetHours(c.Request.Context(), restaurantOf(c), hoursRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Opening hours updated successfully",
+		Data:    hours,
+	})
+}
+
+// SetException sets the hours of the restaurant of the staff member on the
+// date in the path, or closes it that day
+func (h *RestaurantHandler) SetException(c *gin.Context) {
+	var exceptionRequest models.HoursExceptionRequest
+	if err := c.ShouldBindJSON(&exceptionRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	exception, err := h.restaurants.SetException(c.Request.Context(), restaurantOf(c), c.Param("date"), exceptionRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Opening hours exception saved successfully",
+		Data:    exception,
+	})
+}
+
+// DeleteException restores the weekly hours of the restaurant of the staff
+// member on the date in the path
+func (h *RestaurantHandler) DeleteException(c *gin.Context) {
+	if err := h.restaurants.DeleteException(c.Request.Context(), restaurantOf(c), c.Param("date")); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Opening hours exception deleted successfully",
+	})
+}
+
+// Pause stops the restaurant of the staff member from taking orders
+func (h *RestaurantHandler) Pause(c *gin.Context) {
+	var pauseRequest models.PauseRequest
+	if err := c.ShouldBindJSON(&pauseRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	availability, err := h.restaurants.Pause(c.Request.Context(), restaurantOf(c), pauseRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ordering paused",
+		Data:    availability,
+	})
+}
+
+// Resume lets the restaurant of the staff member take orders again
+func (h *RestaurantHandler) Resume(c *gin.Context) {
+	availability, err := h.restaurants.Resume(c.Request.Context(), restaurantOf(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ordering resumed",
+		Data:    availability,
+	})
+}
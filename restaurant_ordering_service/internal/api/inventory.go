@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/orderstream"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
+	"github.com/shared/logging"
+)
+
+// Bounds of the number of stock movements and alerts returned at once
+const (
+	defaultMovements = 50
+	maxMovements     = 200
+	defaultAlerts    = 50
+	maxAlerts        = 200
+)
+
+// InventoryHandler serves the ingredients, stock, recipes and stock alerts
+// of the restaurant of a staff member
+type InventoryHandler struct {
+	inventory *service.Inventory
+	alerts    *orderstream.AlertFeed
+	heartbeat time.Duration
+}
+
+// NewInventoryHandler creates an InventoryHandler streaming the new stock
+// alerts of alerts, with a heartbeat every heartbeat
+func NewInventoryHandler(inventory *service.Inventory, alerts *orderstream.AlertFeed, heartbeat time.Duration) *InventoryHandler {
+	return &InventoryHandler{inventory: inventory, alerts: alerts, heartbeat: heartbeat}
+}
+
+// Ingredients returns the ingredients of the restaurant with their stock
+func (h *InventoryHandler) Ingredients(c *gin.Context) {
+	ingredients, err := h.inventory.Ingredients(c.Request.Context(), restaurantOf(c).ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Ingredients retrieved successfully",
+		Data:    ingredients,
+	})
+}
+
+// CreateIngredient adds an ingredient to the restaurant
+func (h *InventoryHandler) CreateIngredient(c *gin.Context) {
+	var ingredientRequest models.IngredientRequest
+	if err := c.ShouldBindJSON(&ingredientRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	userID := c.MustGet("user_id").(int)
+	ingredient, err := h.inventory.CreateIngredient(c.Request.Context(), restaurantOf(c).ID, userID, ingredientRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.APIResponse{
+		Success: true,
+		Message: "Ingredient created successfully",
+		Data:    ingredient,
+	})
+}
+
+// SetThreshold changes the low-stock threshold of the ingredient in the path
+func (h *InventoryHandler) SetThreshold(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var thresholdRequest models.ThresholdRequest
+	if err := c.ShouldBindJSON(&thresholdRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	ingredient, err := h.inventory.SetThreshold(c.Request.Context(), restaurantOf(c).ID, id, thresholdRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Low-stock threshold updated successfully",
+		Data:    ingredient,
+	})
+}
+
+// Adjust restocks, writes off or corrects the stock of the ingredient in the
+// path
+func (h *InventoryHandler) Adjust(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var adjustmentRequest models.StockAdjustmentRequest
+	if err := c.ShouldBindJSON(&adjustmentRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	userID := c.MustGet("user_id").(int)
+	ingredient, err := h.inventory.Adjust(c.Request.Context(), restaurantOf(c).ID, userID, id, adjustmentRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock adjusted successfully",
+		Data:    ingredient,
+	})
+}
+
+// Movements returns the latest stock movements of the restaurant, of the
+// ingredient in the ingredient_id query parameter if given, at most limit
+func (h *InventoryHandler) Movements(c *gin.Context) {
+	ingredientID, ok := queryInt(c, "ingredient_id", 0, 1, 0)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", defaultMovements, 1, maxMovements)
+	if !ok {
+		return
+	}
+
+	movements, err := h.inventory.Movements(c.Request.Context(), restaurantOf(c).ID, ingredientID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock movements retrieved successfully",
+		Data:    movements,
+	})
+}
+
+// Recipes returns the recipes of the food items and modifiers of the
+// restaurant
+func (h *InventoryHandler) Recipes(c *gin.Context) {
+	recipes, err := h.inventory.Recipes(c.Request.Context(), restaurantOf(c).ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Recipes retrieved successfully",
+		Data:    recipes,
+	})
+}
+
+// SetFoodItemRecipe replaces the recipe of the food item in the path
+func (h *InventoryHandler) SetFoodItemRecipe(c *gin.Context) {
+	h.setRecipe(c, h.inventory.SetFoodItemRecipe)
+}
+
+// SetModifierRecipe replaces the recipe of the modifier in the path
+func (h *InventoryHandler) SetModifierRecipe(c *gin.Context) {
+	h.setRecipe(c, h.inventory.SetModifierRecipe)
+}
+
+// setRecipe replaces a recipe of the thing in the path with set
+func (h *InventoryHandler) setRecipe(c *gin.Context, set func(ctx context.Context, restaurantID, id int, request models.RecipeRequest) (models.Recipe, error)) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var recipeRequest models.RecipeRequest
+	if err := c.ShouldBindJSON(&recipeRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	recipe, err := set(c.Request.Context(), restaurantOf(c).ID, id, recipeRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Recipe updated successfully",
+		Data:    recipe,
+	})
+}
+
+// SetStockSettings changes the low-stock threshold of the food item in the
+// path and whether it is hidden while sold out
+func (h *InventoryHandler) SetStockSettings(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var settingsRequest models.StockSettingsRequest
+	if err := c.ShouldBindJSON(&settingsRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	foodItem, err := h.inventory.SetStockSettings(c.Request.Context(), restaurantOf(c).ID, id, settingsRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock settings updated successfully",
+		Data:    foodItem,
+	})
+}
+
+// RestockFoodItem adds stock to the food item in the path or to one of its
+// variants
+func (h *InventoryHandler) RestockFoodItem(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+	var restockRequest models.RestockRequest
+	if err := c.ShouldBindJSON(&restockRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	foodItem, err := h.inventory.RestockFoodItem(c.Request.Context(), restaurantOf(c).ID, id, restockRequest)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Food item restocked successfully",
+		Data:    foodItem,
+	})
+}
+
+// Alerts returns the latest open stock alerts of the restaurant, or all of
+// them when the all query parameter is true, at most limit
+func (h *InventoryHandler) Alerts(c *gin.Context) {
+	all, err := strconv.ParseBool(c.DefaultQuery("all", "false"))
+	if err != nil {
+		c.Error(apperrors.Validation(models.FieldError{Field: "all", Message: "must be true or false"}))
+		return
+	}
+	limit, ok := queryInt(c, "limit", defaultAlerts, 1, maxAlerts)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.inventory.Alerts(c.Request.Context(), restaurantOf(c).ID, all, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock alerts retrieved successfully",
+		Data:    alerts,
+	})
+}
+
+// Acknowledge closes the stock alert in the path
+func (h *InventoryHandler) Acknowledge(c *gin.Context) {
+	id, ok := pathID(c)
+	if !ok {
+		return
+	}
+
+	userID := c.MustGet("user_id").(int)
+	alert, err := h.inventory.Acknowledge(c.Request.Context(), restaurantOf(c).ID, userID, id)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Stock alert acknowledged successfully",
+		Data:    alert,
+	})
+}
+
+// AlertStream streams the events of the new stock alerts of the restaurant
+// as Server-Sent Events until the client disconnects
+func (h *InventoryHandler) AlertStream(c *gin.Context) {
+	restaurantID := restaurantOf(c).ID
+
+	sub, err := h.alerts.Subscribe()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	metrics.OrderStreams.WithLabelValues("alerts").Inc()
+	defer metrics.OrderStreams.WithLabelValues("alerts").Dec()
+
+	sse, err := startSSE(c, h.heartbeat)
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Items():
+			if !ok {
+				return
+			}
+			if event.RestaurantID != restaurantID {
+				continue
+			}
+			if err := sse.event("", "alert", event); err != nil {
+				logging.For(logging.ComponentHTTP).DebugContext(c.Request.Context(), "Stock alert stream ended", "error", err)
+				return
+			}
+			ticker.Reset(h.heartbeat)
+		case <-ticker.C:
+			if err := sse.heartbeat(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// pathID reads a positive ID from the path. It reports false after
+// attaching the error if the ID is invalid.
+func pathID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 1 {
+		c.Error(apperrors.Validation(models.FieldError{Field: "id", Message: "must be a positive integer"}))
+		return 0, false
+	}
+	return id, true
+}
+
+// queryInt reads an integer query parameter between least and most, or at
+// least least if most is 0, defaulting to fallback when it is absent. It
+// reports false after attaching the error if the value is invalid.
+func queryInt(c *gin.Context, name string, fallback, least, most int) (int, bool) {
+	raw, ok := c.GetQuery(name)
+	if !ok {
+		return fallback, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < least || (most != 0 && value > most) {
+		message := "must be an integer of at least " + strconv.Itoa(least)
+		if most != 0 {
+			message = "must be an integer from " + strconv.Itoa(least) + " to " + strconv.Itoa(most)
+		}
+		c.Error(apperrors.Validation(models.FieldError{Field: name, Message: message}))
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/orderstream"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
+	"github.com/shared/logging"
+)
+
+// KitchenHandler serves the kitchen display
+type KitchenHandler struct {
+	kitchen   *service.Kitchen
+	tickets   *orderstream.TicketFeed
+	heartbeat time.Duration
+}
+
+// NewKitchenHandler creates a KitchenHandler streaming the new tickets of
+// tickets, with a heartbeat every heartbeat
+func NewKitchenHandler(kitchen *service.Kitchen, tickets *orderstream.TicketFeed, heartbeat time.Duration) *KitchenHandler {
+	return &KitchenHandler{kitchen: kitchen, tickets: tickets, heartbeat: heartbeat}
+}
+
+// Queue returns the active tickets of the restaurant grouped by station,
+// optionally of the station in the station query parameter only
+func (h *KitchenHandler) Queue(c *gin.Context) {
+	stations, err := h.kitchen.Queue(c.Request.Context(), restaurantOf(c).ID, c.Query("station"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Kitchen queue retrieved successfully",
+		Data:    stations,
+	})
+}
+
+// StartItem marks an item as being prepared
+func (h *KitchenHandler) StartItem(c *gin.Context) {
+	h.updateItem(c, h.kitchen.StartItem, "Item started")
+}
+
+// FinishItem marks an item as done
+func (h *KitchenHandler) FinishItem(c *gin.Context) {
+	h.updateItem(c, h.kitchen.FinishItem, "Item done")
+}
+
+// SetPriority changes the priority of a ticket
+func (h *KitchenHandler) SetPriority(c *gin.Context) {
+	orderID, ok := pathTicketID(c)
+	if !ok {
+		return
+	}
+	var priorityRequest models.TicketPriorityRequest
+	if err := c.ShouldBindJSON(&priorityRequest); err != nil {
+		c.Error(apperrors.FromBinding(err))
+		return
+	}
+
+	ticket, err := h.kitchen.SetPriority(c.Request.Context(), restaurantOf(c).ID, orderID, priorityRequest.Priority)
+	h.respondTicket(c, ticket, err, "Ticket priority changed")
+}
+
+// Bump takes a ticket off the queue
+func (h *KitchenHandler) Bump(c *gin.Context) {
+	orderID, ok := pathTicketID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.kitchen.Bump(c.Request.Context(), restaurantOf(c).ID, orderID)
+	h.respondTicket(c, ticket, err, "Ticket bumped")
+}
+
+// Recall puts a bumped ticket back on the queue
+func (h *KitchenHandler) Recall(c *gin.Context) {
+	orderID, ok := pathTicketID(c)
+	if !ok {
+		return
+	}
+	ticket, err := h.kitchen.Recall(c.Request.Context(), restaurantOf(c).ID, orderID)
+	h.respondTicket(c, ticket, err, "Ticket recalled")
+}
+
+// PrepTimes returns the average preparation time of each food item
+func (h *KitchenHandler) PrepTimes(c *gin.Context) {
+	prepTimes, err := h.kitchen.PrepTimes(c.Request.Context(), restaurantOf(c).ID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Preparation times retrieved successfully",
+		Data:    prepTimes,
+	})
+}
+
+// Stream sends each new ticket of the restaurant as a Server-Sent Event of
+// type ticket, with only the items of the station in the station query
+// parameter if given.
+// Displays load the queue when they connect and after reconnecting, since
+// tickets are not replayed.
+func (h *KitchenHandler) Stream(c *gin.Context) {
+	restaurantID, station := restaurantOf(c).ID, c.Query("station")
+
+	sub, err := h.tickets.Subscribe()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer sub.Close()
+
+	metrics.OrderStreams.WithLabelValues("kitchen").Inc()
+	defer metrics.OrderStreams.WithLabelValues("kitchen").Dec()
+
+	sse, err := startSSE(c, h.heartbeat)
+	if err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ticket, ok := <-sub.Items():
+			if !ok {
+				return
+			}
+			if ticket.RestaurantID != restaurantID {
+				continue
+			}
+			if station != "" {
+				if ticket, ok = service.ForStation(ticket, station); !ok {
+					continue
+				}
+			}
+			if err := sse.event("", "ticket", ticket); err != nil {
+				logging.For(logging.ComponentHTTP).DebugContext(c.Request.Context(), "Kitchen ticket stream ended", "error", err)
+				return
+			}
+			ticker.Reset(h.heartbeat)
+		case <-ticker.C:
+			if err := sse.heartbeat(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// updateItem applies an action to the item in the path
+func (h *KitchenHandler) updateItem(c *gin.Context, action func(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error), message string) {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || itemID < 1 {
+		c.Error(apperrors.Validation(models.FieldError{Field: "id", Message: "must be a positive integer"}))
+		return
+	}
+
+	item, err := action(c.Request.Context(), restaurantOf(c).ID, itemID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    item,
+	})
+}
+
+// respondTicket responds with a ticket after an action on it
+func (h *KitchenHandler) respondTicket(c *gin.Context, ticket models.KitchenTicket, err error, message string) {
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: message,
+		Data:    ticket,
+	})
+}
+
+// pathTicketID reads the ticket ID, which is the order ID, from the path. It
+// reports false after attaching the error if the ID is invalid.
+func pathTicketID(c *gin.Context) (int, bool) {
+	orderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || orderID < 1 {
+		c.Error(errInvalidOrderID())
+		return 0, false
+	}
+	return orderID, true
+}
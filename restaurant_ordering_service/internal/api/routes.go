@@ -0,0 +1,511 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/middleware"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/health"
+	"github.com/shared/httpmw"
+	"github.com/shared/openapi"
+)
+
+// Info describes the API in the OpenAPI document
+var Info = openapi.Info{
+	Title:       "Restaurant Ordering Service",
+	Version:     "1.0.0",
+	Description: "Browse the menu, place orders and pay for them.",
+}
+
+// Handlers holds everything the routes are served by
+type Handlers struct {
+	Auth                *AuthHandler
+	Restaurants         *RestaurantHandler
+	Menu                *MenuHandler
+	Orders              *OrderHandler
+	Events              *EventsHandler
+	Kitchen             *KitchenHandler
+	Inventory           *InventoryHandler
+	Readiness           gin.HandlerFunc
+	Metrics             gin.HandlerFunc
+	RequireAuth         gin.HandlerFunc // Verifies the JWT of protected routes
+	RestaurantFromPath  gin.HandlerFunc // Resolves the restaurant in the :restaurant path parameter
+	RestaurantFromToken gin.HandlerFunc // Resolves the restaurant of the staff member in the JWT
+	DefaultRestaurant   gin.HandlerFunc // Resolves the default restaurant for routes that predate restaurants
+	Gateway             gin.HandlerFunc // Serves the HTTP mappings of the gRPC API
+}
+
+// restaurantRef documents the restaurant path parameter
+var restaurantRef = []openapi.Param{{Name: "restaurant", Description: "Restaurant ID or slug"}}
+
+// exceptionDate documents the date path parameter of opening hours
+// exceptions
+var exceptionDate = []openapi.Param{{Name: "date", Description: "Date in the restaurant's timezone, such as 2025-12-25"}}
+
+// orderID documents the order ID path parameter
+var orderID = []openapi.Param{{Name: "id", Type: "integer", Description: "Order ID"}}
+
+// ticketID documents the kitchen ticket ID path parameter
+var ticketID = []openapi.Param{{Name: "id", Type: "integer", Description: "Order ID of the ticket"}}
+
+// itemID documents the order item ID path parameter
+var itemID = []openapi.Param{{Name: "id", Type: "integer", Description: "Order item ID"}}
+
+// ingredientID documents the ingredient ID path parameter
+var ingredientID = []openapi.Param{{Name: "id", Type: "integer", Description: "Ingredient ID"}}
+
+// foodItemID documents the food item ID path parameter
+var foodItemID = []openapi.Param{{Name: "id", Type: "integer", Description: "Food item ID"}}
+
+// modifierID documents the modifier ID path parameter
+var modifierID = []openapi.Param{{Name: "id", Type: "integer", Description: "Modifier ID"}}
+
+// alertID documents the stock alert ID path parameter
+var alertID = []openapi.Param{{Name: "id", Type: "integer", Description: "Stock alert ID"}}
+
+// Unversioned names the routes without a version prefix. They serve version
+// 1 for clients that predate versioning.
+const Unversioned = "unversioned"
+
+// LatestVersion is the API version new clients should use
+const LatestVersion = "v2"
+
+// endpoint is an API route together with its handler
+type endpoint struct {
+	secured bool            // Requires a JWT
+	role    string          // Role the user must have, on secured endpoints
+	tenant  gin.HandlerFunc // Resolves the restaurant of the request, if it is for one
+	route   openapi.Route
+	handler gin.HandlerFunc
+}
+
+// apiVersion is an API version and the endpoints it serves
+type apiVersion struct {
+	name      string
+	endpoints func(h Handlers) []endpoint
+}
+
+// versions lists the served API versions. A new version starts from the
+// endpoints of the previous one and replaces those whose contract changed,
+// so that both run side by side.
+var versions = []apiVersion{
+	{name: Unversioned, endpoints: v1},
+	{name: "v1", endpoints: v1},
+	{name: "v2", endpoints: v2},
+}
+
+// RegisterRoutes registers every route of the service on router and
+// describes it in the returned OpenAPI registry. policies holds the
+// deprecation schedule of each API version.
+func RegisterRoutes(router *gin.Engine, h Handlers, policies map[string]config.VersionPolicy) *openapi.Registry {
+	registry := openapi.NewRegistry(Info)
+	ops := registry.Group(&router.RouterGroup)
+
+	// Health endpoints for Kubernetes probes
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/healthz", OperationID: "liveness", Tag: "Operations",
+		Summary: "Report whether the process is running",
+		Raw:     &openapi.RawResponse{ContentType: "application/json"},
+	}, health.LivenessHandler)
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/readyz", OperationID: "readiness", Tag: "Operations",
+		Summary: "Check the dependencies of the service",
+		Raw:     &openapi.RawResponse{ContentType: "application/json", Body: health.Report{}},
+		Errors:  []int{http.StatusServiceUnavailable},
+	}, h.Readiness)
+
+	// Prometheus metrics
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/metrics", OperationID: "metrics", Tag: "Operations",
+		Summary: "Export Prometheus metrics",
+		Raw:     &openapi.RawResponse{ContentType: "text/plain"},
+	}, h.Metrics)
+
+	// API documentation
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/openapi.json", OperationID: "openAPI", Tag: "Documentation",
+		Summary: "Get this OpenAPI document",
+		Raw:     &openapi.RawResponse{ContentType: "application/json"},
+	}, registry.SpecHandler())
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/docs", OperationID: "docs", Tag: "Documentation",
+		Summary: "Browse this document in Swagger UI",
+		Raw:     &openapi.RawResponse{ContentType: "text/html"},
+	}, registry.DocsHandler("openapi.json"))
+
+	// The internal gRPC API over HTTP. It is described by its proto file
+	// rather than this document, and authenticated by the gRPC server.
+	router.GET("/internal/*path", h.Gateway)
+
+	// The API in every version, plus the unversioned routes that old clients
+	// still call, each announcing its deprecation as configured
+	for _, version := range versions {
+		prefix, docVersion := "/"+version.name, version.name
+		if version.name == Unversioned {
+			prefix, docVersion = "/", ""
+		}
+		policy := policies[version.name]
+
+		group := router.Group(prefix)
+		group.Use(metrics.APIVersion(version.name), httpmw.Deprecation(policy))
+		authorized := group.Group("/")
+		authorized.Use(h.RequireAuth)
+
+		public := registry.Group(group).Version(docVersion, !policy.Deprecated.IsZero())
+		secured := registry.SecuredGroup(authorized).Version(docVersion, !policy.Deprecated.IsZero())
+		for _, e := range version.endpoints(h) {
+			var handlers []gin.HandlerFunc
+			if e.role != "" {
+				handlers = append(handlers, middleware.RequireRole(e.role))
+			}
+			if e.tenant != nil {
+				handlers = append(handlers, e.tenant)
+			}
+			handlers = append(handlers, e.handler)
+
+			if e.secured || e.role != "" {
+				secured.Handle(e.route, handlers...)
+			} else {
+				public.Handle(e.route, handlers...)
+			}
+		}
+	}
+
+	return registry
+}
+
+// v1 returns the endpoints of version 1 of the API
+func v1(h Handlers) []endpoint {
+	return []endpoint{
+		{route: openapi.Route{
+			Method: http.MethodPost, Path: "/auth", OperationID: "login", Tag: "Auth",
+			Summary:  "Exchange a username and password for a JWT",
+			Request:  models.LoginRequest{},
+			Response: models.LoginResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: h.Auth.Login},
+		{route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants", OperationID: "listRestaurants", Tag: "Restaurants",
+			Summary:  "List the restaurants",
+			Response: []models.Restaurant{},
+		}, handler: h.Restaurants.List},
+		{tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants/:restaurant", OperationID: "getRestaurant", Tag: "Restaurants",
+			Summary:  "Get a restaurant with its hours, currency and tax rate",
+			Params:   restaurantRef,
+			Response: models.Restaurant{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Restaurants.Get},
+		{tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants/:restaurant/hours", OperationID: "getRestaurantHours", Tag: "Restaurants",
+			Summary:  "Get the weekly opening hours of a restaurant and its upcoming exceptions",
+			Params:   restaurantRef,
+			Response: models.RestaurantHours{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Restaurants.Hours},
+		{tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants/:restaurant/availability", OperationID: "getRestaurantAvailability", Tag: "Restaurants",
+			Summary:  "Tell whether a restaurant takes orders now",
+			Params:   restaurantRef,
+			Response: models.Availability{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Restaurants.Availability},
+		{tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants/:restaurant/food-items", OperationID: "listRestaurantFoodItems", Tag: "Menu",
+			Summary:  "List the food items of a restaurant with their variants, modifiers, stock and whether they can be ordered now",
+			Params:   restaurantRef,
+			Response: []models.FoodItem{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Menu.List},
+		{tenant: h.DefaultRestaurant, route: openapi.Route{
+			Method: http.MethodGet, Path: "/food-items", OperationID: "listFoodItems", Tag: "Menu",
+			Summary:  "List the food items of the default restaurant with their variants, modifiers, stock and whether they can be ordered now",
+			Response: []models.FoodItem{},
+		}, handler: h.Menu.List},
+		{tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodGet, Path: "/restaurants/:restaurant/bundles", OperationID: "listRestaurantBundles", Tag: "Menu",
+			Summary:  "List the combo bundles of a restaurant with their slots, options and whether they can be ordered now",
+			Params:   restaurantRef,
+			Response: []models.Bundle{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Menu.Bundles},
+		{tenant: h.DefaultRestaurant, route: openapi.Route{
+			Method: http.MethodGet, Path: "/bundles", OperationID: "listBundles", Tag: "Menu",
+			Summary:  "List the combo bundles of the default restaurant with their slots, options and whether they can be ordered now",
+			Response: []models.Bundle{},
+		}, handler: h.Menu.Bundles},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/profile", OperationID: "getProfile", Tag: "Auth",
+			Summary:  "Get the profile of the authenticated user",
+			Response: models.User{},
+			Errors:   []int{http.StatusNotFound},
+		}, handler: h.Auth.Profile},
+		{secured: true, tenant: h.RestaurantFromPath, route: openapi.Route{
+			Method: http.MethodPost, Path: "/restaurants/:restaurant/orders", OperationID: "placeRestaurantOrder", Tag: "Orders",
+			Summary:  "Place an order of items and bundles at a restaurant, picking the variant and modifiers of each item",
+			Params:   restaurantRef,
+			Request:  models.OrderRequest{},
+			Response: models.OrderPlacedResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		}, handler: h.Orders.Place},
+		{secured: true, tenant: h.DefaultRestaurant, route: openapi.Route{
+			Method: http.MethodPost, Path: "/orders", OperationID: "placeOrder", Tag: "Orders",
+			Summary:  "Place an order of items and bundles at the default restaurant, picking the variant and modifiers of each item",
+			Request:  models.OrderRequest{},
+			Response: models.OrderPlacedResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		}, handler: h.Orders.Place},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/orders/:id", OperationID: "getOrder", Tag: "Orders",
+			Summary:  "Get an order of the authenticated user with its items",
+			Params:   orderID,
+			Response: models.Order{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Orders.Get},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/orders/:id/events", OperationID: "streamOrderEvents", Tag: "Orders",
+			Summary: "Stream the status changes of an order as Server-Sent Events, resuming after Last-Event-ID",
+			Params:  orderID,
+			Raw:     &openapi.RawResponse{ContentType: "text/event-stream", Body: models.OrderStatusEvent{}},
+			Errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		}, handler: h.Events.SSE},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/orders/:id/events/ws", OperationID: "watchOrderEvents", Tag: "Orders",
+			Summary: "Stream the status changes of an order over a WebSocket, resuming after last_event_id",
+			Params:  orderID,
+			Status:  http.StatusSwitchingProtocols,
+			Raw:     &openapi.RawResponse{ContentType: "application/json", Body: models.StreamMessage{}},
+			Errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		}, handler: h.Events.WebSocket},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodPost, Path: "/transactions", OperationID: "payOrder", Tag: "Orders",
+			Summary: "Pay for an order, taking its items out of stock",
+			Request: models.TransactionRequest{},
+			Errors:  []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Orders.Pay},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/kitchen/queue", OperationID: "getKitchenQueue", Tag: "Kitchen",
+			Summary:  "List the active tickets by station, of one station with ?station=",
+			Response: []models.KitchenStation{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Kitchen.Queue},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/kitchen/tickets/events", OperationID: "streamKitchenTickets", Tag: "Kitchen",
+			Summary: "Stream new tickets as Server-Sent Events, of one station with ?station=",
+			Raw:     &openapi.RawResponse{ContentType: "text/event-stream", Body: models.KitchenTicket{}},
+			Errors:  []int{http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		}, handler: h.Kitchen.Stream},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/kitchen/tickets/:id/priority", OperationID: "setTicketPriority", Tag: "Kitchen",
+			Summary:  "Change the priority of a ticket",
+			Params:   ticketID,
+			Request:  models.TicketPriorityRequest{},
+			Response: models.KitchenTicket{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Kitchen.SetPriority},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/kitchen/tickets/:id/bump", OperationID: "bumpTicket", Tag: "Kitchen",
+			Summary:  "Take a served ticket off the queue",
+			Params:   ticketID,
+			Response: models.KitchenTicket{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}, handler: h.Kitchen.Bump},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/kitchen/tickets/:id/recall", OperationID: "recallTicket", Tag: "Kitchen",
+			Summary:  "Put a bumped ticket back on the queue",
+			Params:   ticketID,
+			Response: models.KitchenTicket{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}, handler: h.Kitchen.Recall},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/kitchen/items/:id/start", OperationID: "startTicketItem", Tag: "Kitchen",
+			Summary:  "Mark an item of a ticket as started",
+			Params:   itemID,
+			Response: models.KitchenItem{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}, handler: h.Kitchen.StartItem},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/kitchen/items/:id/done", OperationID: "finishTicketItem", Tag: "Kitchen",
+			Summary:  "Mark a started item of a ticket as done",
+			Params:   itemID,
+			Response: models.KitchenItem{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}, handler: h.Kitchen.FinishItem},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/kitchen/prep-times", OperationID: "listPrepTimes", Tag: "Kitchen",
+			Summary:  "Get the average preparation time of each food item",
+			Response: []models.PrepTime{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Kitchen.PrepTimes},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/hours", OperationID: "setRestaurantHours", Tag: "Staff",
+			Summary:  "Replace the weekly opening hours and last-order cutoff of the restaurant",
+			Request:  models.HoursRequest{},
+			Response: models.RestaurantHours{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden},
+		}, handler: h.Restaurants.SetHours},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/hours/exceptions/:date", OperationID: "setHoursException", Tag: "Staff",
+			Summary:  "Set the opening hours of the restaurant on a date, or close it that day",
+			Params:   exceptionDate,
+			Request:  models.HoursExceptionRequest{},
+			Response: models.HoursException{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden},
+		}, handler: h.Restaurants.SetException},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodDelete, Path: "/staff/hours/exceptions/:date", OperationID: "deleteHoursException", Tag: "Staff",
+			Summary: "Restore the weekly opening hours of the restaurant on a date",
+			Params:  exceptionDate,
+			Errors:  []int{http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Restaurants.DeleteException},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/staff/pause", OperationID: "pauseOrdering", Tag: "Staff",
+			Summary:  "Stop the restaurant from taking orders for some minutes or until resumed",
+			Request:  models.PauseRequest{},
+			Response: models.Availability{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden},
+		}, handler: h.Restaurants.Pause},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodDelete, Path: "/staff/pause", OperationID: "resumeOrdering", Tag: "Staff",
+			Summary:  "Let the restaurant take orders again",
+			Response: models.Availability{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Restaurants.Resume},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/inventory/ingredients", OperationID: "listIngredients", Tag: "Inventory",
+			Summary:  "List the ingredients of the restaurant with their stock and whether it is low",
+			Response: []models.Ingredient{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Inventory.Ingredients},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/staff/inventory/ingredients", OperationID: "createIngredient", Tag: "Inventory",
+			Summary:  "Add an ingredient to the restaurant, recording its initial stock as a restock",
+			Request:  models.IngredientRequest{},
+			Response: models.Ingredient{},
+			Status:   http.StatusCreated,
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusConflict},
+		}, handler: h.Inventory.CreateIngredient},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/inventory/ingredients/:id/threshold", OperationID: "setLowStockThreshold", Tag: "Inventory",
+			Summary:  "Change the low-stock threshold of an ingredient",
+			Params:   ingredientID,
+			Request:  models.ThresholdRequest{},
+			Response: models.Ingredient{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.SetThreshold},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/staff/inventory/ingredients/:id/adjustments", OperationID: "adjustStock", Tag: "Inventory",
+			Summary:  "Restock, write off or correct the stock of an ingredient",
+			Params:   ingredientID,
+			Request:  models.StockAdjustmentRequest{},
+			Response: models.Ingredient{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.Adjust},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/inventory/movements", OperationID: "listStockMovements", Tag: "Inventory",
+			Summary:  "List the latest stock movements, newest first, of one ingredient with ?ingredient_id= and at most ?limit= (50, up to 200)",
+			Response: []models.StockMovement{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.Movements},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/recipes", OperationID: "listRecipes", Tag: "Inventory",
+			Summary:  "List the ingredients used by each food item and modifier",
+			Response: []models.Recipe{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Inventory.Recipes},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/recipes/food-items/:id", OperationID: "setFoodItemRecipe", Tag: "Inventory",
+			Summary:  "Replace the ingredients used by a portion of a food item",
+			Params:   foodItemID,
+			Request:  models.RecipeRequest{},
+			Response: models.Recipe{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.SetFoodItemRecipe},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/recipes/modifiers/:id", OperationID: "setModifierRecipe", Tag: "Inventory",
+			Summary:  "Replace the ingredients a modifier adds to a portion",
+			Params:   modifierID,
+			Request:  models.RecipeRequest{},
+			Response: models.Recipe{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.SetModifierRecipe},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/food-items", OperationID: "listStaffFoodItems", Tag: "Inventory",
+			Summary:  "List every food item of the restaurant, including those hidden while sold out",
+			Response: []models.FoodItem{},
+			Errors:   []int{http.StatusForbidden},
+		}, handler: h.Menu.ListAll},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPut, Path: "/staff/food-items/:id/stock-settings", OperationID: "setStockSettings", Tag: "Inventory",
+			Summary:  "Change the low-stock threshold of a food item and whether it is hidden while sold out",
+			Params:   foodItemID,
+			Request:  models.StockSettingsRequest{},
+			Response: models.FoodItem{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.SetStockSettings},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/staff/food-items/:id/restock", OperationID: "restockFoodItem", Tag: "Inventory",
+			Summary:  "Add stock to a food item or one of its variants, putting it back on the menu",
+			Params:   foodItemID,
+			Request:  models.RestockRequest{},
+			Response: models.FoodItem{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.RestockFoodItem},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/alerts", OperationID: "listStockAlerts", Tag: "Inventory",
+			Summary:  "List the latest open stock alerts, newest first, all of them with ?all=true and at most ?limit= (50, up to 200)",
+			Response: []models.StockAlert{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden},
+		}, handler: h.Inventory.Alerts},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodGet, Path: "/staff/alerts/events", OperationID: "streamStockAlerts", Tag: "Inventory",
+			Summary: "Stream new stock alerts as Server-Sent Events",
+			Raw:     &openapi.RawResponse{ContentType: "text/event-stream", Body: models.InventoryEvent{}},
+			Errors:  []int{http.StatusForbidden, http.StatusTooManyRequests, http.StatusServiceUnavailable},
+		}, handler: h.Inventory.AlertStream},
+		{secured: true, role: models.RoleKitchen, tenant: h.RestaurantFromToken, route: openapi.Route{
+			Method: http.MethodPost, Path: "/staff/alerts/:id/acknowledge", OperationID: "acknowledgeStockAlert", Tag: "Inventory",
+			Summary:  "Close a stock alert",
+			Params:   alertID,
+			Response: models.StockAlert{},
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound},
+		}, handler: h.Inventory.Acknowledge},
+	}
+}
+
+// v2 returns the endpoints of version 2 of the API, in which placing an
+// order responds with 201 and the created order
+func v2(h Handlers) []endpoint {
+	return replace(v1(h), endpoint{secured: true, tenant: h.RestaurantFromPath, route: openapi.Route{
+		Method: http.MethodPost, Path: "/restaurants/:restaurant/orders", OperationID: "placeRestaurantOrder", Tag: "Orders",
+		Summary:  "Place an order of items and bundles at a restaurant, picking the variant and modifiers of each item",
+		Params:   restaurantRef,
+		Request:  models.OrderRequest{},
+		Response: models.Order{},
+		Status:   http.StatusCreated,
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+	}, handler: h.Orders.PlaceV2}, endpoint{secured: true, tenant: h.DefaultRestaurant, route: openapi.Route{
+		Method: http.MethodPost, Path: "/orders", OperationID: "placeOrder", Tag: "Orders",
+		Summary:  "Place an order of items and bundles at the default restaurant, picking the variant and modifiers of each item",
+		Request:  models.OrderRequest{},
+		Response: models.Order{},
+		Status:   http.StatusCreated,
+		Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+	}, handler: h.Orders.PlaceV2})
+}
+
+// replace returns endpoints with those matching the method and path of a
+// replacement swapped for it
+func replace(endpoints []endpoint, replacements ...endpoint) []endpoint {
+	for _, replacement := range replacements {
+		for i, e := range endpoints {
+			if e.route.Method == replacement.route.Method && e.route.Path == replacement.route.Path {
+				endpoints[i] = replacement
+			}
+		}
+	}
+	return endpoints
+}
@@ -0,0 +1,478 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shared/httpmw"
+	"gopkg.in/yaml.v3"
+)
+
+// Profiles the service can run under. Insecure defaults are only accepted
+// in the dev profile.
+const (
+	ProfileDev        = "dev"
+	ProfileProduction = "production"
+)
+
+// minJWTSecretLength is the shortest JWT secret accepted outside dev
+const minJWTSecretLength = 32
+
+// insecureSecrets are well-known placeholder values that must never be used
+// outside dev
+var insecureSecrets = map[string]bool{
+	"your-secret-key": true,
+	"secret":          true,
+	"changeme":        true,
+}
+
+// Secret is a string that is masked whenever it is printed or logged
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "********"
+}
+
+// LogValue masks the secret in structured logs
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// Reveal returns the actual secret value
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// Config holds the complete service configuration
+type Config struct {
+	Profile           string         `yaml:"profile"`
+	Port              int            `yaml:"port"`
+	ShutdownTimeout   time.Duration  `yaml:"shutdown_timeout"`
+	MigrateOnStart    bool           `yaml:"migrate_on_start"`
+	JWTSecret         Secret         `yaml:"jwt_secret"`
+	DefaultRestaurant string         `yaml:"default_restaurant"` // ID or slug of the restaurant of routes that predate restaurants
+	Database          DatabaseConfig `yaml:"database"`
+	Kafka             KafkaConfig    `yaml:"kafka"`
+	API               APIConfig      `yaml:"api"`
+	GRPC              GRPCConfig     `yaml:"grpc"`
+	Stream            StreamConfig   `yaml:"stream"`
+}
+
+// DatabaseConfig holds the Postgres connection and pool settings
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	User     string `yaml:"user"`
+	Password Secret `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// QueryTimeout bounds every single repository call
+	QueryTimeout time.Duration `yaml:"query_timeout"`
+	// TxTimeout bounds a whole unit of work, including its queries
+	TxTimeout time.Duration `yaml:"tx_timeout"`
+}
+
+// DSN returns the lib/pq connection string
+func (d DatabaseConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		d.Host, d.Port, d.User, d.Password.Reveal(), d.Name, d.SSLMode)
+}
+
+// KafkaConfig holds the Kafka connection settings
+type KafkaConfig struct {
+	Brokers []string `yaml:"brokers"`
+}
+
+// GRPCConfig holds the settings of the internal gRPC API
+type GRPCConfig struct {
+	Port int `yaml:"port"`
+	// ServiceTokenSecret signs the tokens other services call the API with.
+	// It must differ from the JWT secret so user tokens are never accepted.
+	ServiceTokenSecret Secret `yaml:"service_token_secret"`
+	// AllowedServices lists the services that may call the API. Empty allows
+	// any service holding a valid token.
+	AllowedServices []string `yaml:"allowed_services"`
+}
+
+// StreamConfig holds the settings of the order status streams served over
+// SSE, WebSocket and gRPC
+type StreamConfig struct {
+	// MaxConnections bounds the open streams of each replica
+	MaxConnections int `yaml:"max_connections"`
+	// MaxConnectionsPerUser bounds the open streams of a single user on each
+	// replica
+	MaxConnectionsPerUser int `yaml:"max_connections_per_user"`
+	// HeartbeatInterval is how often idle streams are sent a heartbeat, so
+	// that proxies do not close them
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+	// HistoryRetention is how long events are kept for clients resuming with
+	// Last-Event-ID
+	HistoryRetention time.Duration `yaml:"history_retention"`
+}
+
+// APIVersions are the API versions whose deprecation can be configured.
+// Unversioned is the routes without a version prefix, kept for clients that
+// predate versioning.
+var APIVersions = []string{"unversioned", "v1", "v2"}
+
+// APIConfig holds the deprecation schedule of each API version
+type APIConfig struct {
+	Versions map[string]VersionPolicy `yaml:"versions"`
+}
+
+// VersionPolicy describes when an API version was deprecated and when it
+// will be removed
+type VersionPolicy = httpmw.VersionPolicy
+
+// defaults returns the configuration used when nothing else is set
+func defaults() Config {
+	return Config{
+		Profile:           ProfileProduction,
+		Port:              8080,
+		ShutdownTimeout:   15 * time.Second,
+		MigrateOnStart:    true,
+		DefaultRestaurant: "default",
+		Database: DatabaseConfig{
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			QueryTimeout:    5 * time.Second,
+			TxTimeout:       10 * time.Second,
+		},
+		Kafka: KafkaConfig{
+			Brokers: []string{"localhost:9092"},
+		},
+		GRPC: GRPCConfig{
+			Port: 9090,
+		},
+		Stream: StreamConfig{
+			MaxConnections:        1000,
+			MaxConnectionsPerUser: 5,
+			HeartbeatInterval:     15 * time.Second,
+			HistoryRetention:      15 * time.Minute,
+		},
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence,
+// built-in defaults, the YAML file named by CONFIG_FILE, environment
+// variables, and secret files named by <VAR>_FILE. The result is validated.
+func Load() (Config, error) {
+	cfg := defaults()
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	var errs []error
+	errs = append(errs, setString(&cfg.Profile, "APP_ENV"))
+	errs = append(errs, setInt(&cfg.Port, "PORT"))
+	errs = append(errs, setDuration(&cfg.ShutdownTimeout, "SHUTDOWN_TIMEOUT"))
+	errs = append(errs, setBool(&cfg.MigrateOnStart, "MIGRATE_ON_START"))
+	errs = append(errs, setSecret(&cfg.JWTSecret, "JWT_SECRET"))
+	errs = append(errs, setString(&cfg.DefaultRestaurant, "DEFAULT_RESTAURANT"))
+	errs = append(errs, setString(&cfg.Database.Host, "DB_HOST"))
+	errs = append(errs, setInt(&cfg.Database.Port, "DB_PORT"))
+	errs = append(errs, setString(&cfg.Database.User, "DB_USER"))
+	errs = append(errs, setSecret(&cfg.Database.Password, "DB_PASSWORD"))
+	errs = append(errs, setString(&cfg.Database.Name, "DB_NAME"))
+	errs = append(errs, setString(&cfg.Database.SSLMode, "DB_SSLMODE"))
+	errs = append(errs, setInt(&cfg.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS"))
+	errs = append(errs, setInt(&cfg.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS"))
+	errs = append(errs, setDuration(&cfg.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"))
+	errs = append(errs, setDuration(&cfg.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"))
+	errs = append(errs, setDuration(&cfg.Database.QueryTimeout, "DB_QUERY_TIMEOUT"))
+	errs = append(errs, setDuration(&cfg.Database.TxTimeout, "DB_TX_TIMEOUT"))
+	if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+		cfg.Kafka.Brokers = strings.Split(brokers, ",")
+	}
+	errs = append(errs, setInt(&cfg.GRPC.Port, "GRPC_PORT"))
+	errs = append(errs, setSecret(&cfg.GRPC.ServiceTokenSecret, "SERVICE_TOKEN_SECRET"))
+	if services := os.Getenv("GRPC_ALLOWED_SERVICES"); services != "" {
+		cfg.GRPC.AllowedServices = strings.Split(services, ",")
+	}
+	errs = append(errs, setInt(&cfg.Stream.MaxConnections, "STREAM_MAX_CONNECTIONS"))
+	errs = append(errs, setInt(&cfg.Stream.MaxConnectionsPerUser, "STREAM_MAX_CONNECTIONS_PER_USER"))
+	errs = append(errs, setDuration(&cfg.Stream.HeartbeatInterval, "STREAM_HEARTBEAT_INTERVAL"))
+	errs = append(errs, setDuration(&cfg.Stream.HistoryRetention, "STREAM_HISTORY_RETENTION"))
+	if cfg.API.Versions == nil {
+		cfg.API.Versions = make(map[string]VersionPolicy)
+	}
+	for _, version := range APIVersions {
+		policy := cfg.API.Versions[version]
+		prefix := "API_" + strings.ToUpper(version) + "_"
+		errs = append(errs, setTime(&policy.Deprecated, prefix+"DEPRECATED"))
+		errs = append(errs, setTime(&policy.Sunset, prefix+"SUNSET"))
+		errs = append(errs, setString(&policy.Link, prefix+"DEPRECATION_LINK"))
+		cfg.API.Versions[version] = policy
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Validate reports every problem with the configuration at once
+func (c Config) Validate() error {
+	var errs []error
+
+	if c.Profile != ProfileDev && c.Profile != ProfileProduction {
+		errs = append(errs, fmt.Errorf("APP_ENV must be %q or %q, got %q", ProfileDev, ProfileProduction, c.Profile))
+	}
+	if c.Port <= 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535, got %d", c.Port))
+	}
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if c.DefaultRestaurant == "" {
+		errs = append(errs, errors.New("DEFAULT_RESTAURANT is required"))
+	}
+	if c.Database.Host == "" {
+		errs = append(errs, errors.New("DB_HOST is required"))
+	}
+	if c.Database.User == "" {
+		errs = append(errs, errors.New("DB_USER is required"))
+	}
+	if c.Database.Name == "" {
+		errs = append(errs, errors.New("DB_NAME is required"))
+	}
+	if c.Database.MaxOpenConns <= 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS must be positive"))
+	}
+	if c.Database.MaxIdleConns < 0 || c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS"))
+	}
+	if c.Database.QueryTimeout <= 0 || c.Database.TxTimeout <= 0 {
+		errs = append(errs, errors.New("DB_QUERY_TIMEOUT and DB_TX_TIMEOUT must be positive"))
+	}
+	if len(c.Kafka.Brokers) == 0 || c.Kafka.Brokers[0] == "" {
+		errs = append(errs, errors.New("KAFKA_BROKERS is required"))
+	}
+
+	if c.GRPC.Port <= 0 || c.GRPC.Port > 65535 {
+		errs = append(errs, fmt.Errorf("GRPC_PORT must be between 1 and 65535, got %d", c.GRPC.Port))
+	} else if c.GRPC.Port == c.Port {
+		errs = append(errs, errors.New("GRPC_PORT must differ from PORT"))
+	}
+
+	if c.Stream.MaxConnections <= 0 {
+		errs = append(errs, errors.New("STREAM_MAX_CONNECTIONS must be positive"))
+	}
+	if c.Stream.MaxConnectionsPerUser <= 0 || c.Stream.MaxConnectionsPerUser > c.Stream.MaxConnections {
+		errs = append(errs, errors.New("STREAM_MAX_CONNECTIONS_PER_USER must be between 1 and STREAM_MAX_CONNECTIONS"))
+	}
+	if c.Stream.HeartbeatInterval <= 0 || c.Stream.HistoryRetention <= 0 {
+		errs = append(errs, errors.New("STREAM_HEARTBEAT_INTERVAL and STREAM_HISTORY_RETENTION must be positive"))
+	}
+
+	for version, policy := range c.API.Versions {
+		if !slices.Contains(APIVersions, version) {
+			errs = append(errs, fmt.Errorf("api.versions: unknown API version %q, expected one of %s", version, strings.Join(APIVersions, ", ")))
+			continue
+		}
+		if !policy.Sunset.IsZero() && policy.Deprecated.IsZero() {
+			errs = append(errs, fmt.Errorf("API_%s_SUNSET requires API_%s_DEPRECATED", strings.ToUpper(version), strings.ToUpper(version)))
+		}
+		if !policy.Sunset.IsZero() && policy.Sunset.Before(policy.Deprecated) {
+			errs = append(errs, fmt.Errorf("API_%s_SUNSET must not be before API_%s_DEPRECATED", strings.ToUpper(version), strings.ToUpper(version)))
+		}
+	}
+
+	if c.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT_SECRET is required"))
+	} else if c.Profile != ProfileDev {
+		if insecureSecrets[c.JWTSecret.Reveal()] {
+			errs = append(errs, errors.New("JWT_SECRET is a well-known placeholder; set a real secret or use APP_ENV=dev"))
+		} else if len(c.JWTSecret) < minJWTSecretLength {
+			errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d characters outside dev", minJWTSecretLength))
+		}
+	}
+	if c.GRPC.ServiceTokenSecret == "" {
+		errs = append(errs, errors.New("SERVICE_TOKEN_SECRET is required"))
+	} else if c.GRPC.ServiceTokenSecret == c.JWTSecret {
+		errs = append(errs, errors.New("SERVICE_TOKEN_SECRET must differ from JWT_SECRET"))
+	} else if c.Profile != ProfileDev {
+		if insecureSecrets[c.GRPC.ServiceTokenSecret.Reveal()] {
+			errs = append(errs, errors.New("SERVICE_TOKEN_SECRET is a well-known placeholder; set a real secret or use APP_ENV=dev"))
+		} else if len(c.GRPC.ServiceTokenSecret) < minJWTSecretLength {
+			errs = append(errs, fmt.Errorf("SERVICE_TOKEN_SECRET must be at least %d characters outside dev", minJWTSecretLength))
+		}
+	}
+	if c.Profile != ProfileDev && c.Database.Password == "" {
+		errs = append(errs, errors.New("DB_PASSWORD is required outside dev"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Summary returns the effective configuration with secrets masked, suitable
+// for logging at startup
+func (c Config) Summary() []any {
+	return []any{
+		"profile", c.Profile,
+		"port", c.Port,
+		"shutdown_timeout", c.ShutdownTimeout.String(),
+		"migrate_on_start", c.MigrateOnStart,
+		"jwt_secret", c.JWTSecret,
+		"default_restaurant", c.DefaultRestaurant,
+		"db_host", c.Database.Host,
+		"db_port", c.Database.Port,
+		"db_user", c.Database.User,
+		"db_password", c.Database.Password,
+		"db_name", c.Database.Name,
+		"db_sslmode", c.Database.SSLMode,
+		"db_max_open_conns", c.Database.MaxOpenConns,
+		"db_max_idle_conns", c.Database.MaxIdleConns,
+		"db_conn_max_lifetime", c.Database.ConnMaxLifetime.String(),
+		"db_conn_max_idle_time", c.Database.ConnMaxIdleTime.String(),
+		"db_query_timeout", c.Database.QueryTimeout.String(),
+		"db_tx_timeout", c.Database.TxTimeout.String(),
+		"kafka_brokers", strings.Join(c.Kafka.Brokers, ","),
+		"api_deprecations", c.API.deprecations(),
+		"grpc_port", c.GRPC.Port,
+		"service_token_secret", c.GRPC.ServiceTokenSecret,
+		"grpc_allowed_services", strings.Join(c.GRPC.AllowedServices, ","),
+		"stream_max_connections", c.Stream.MaxConnections,
+		"stream_max_connections_per_user", c.Stream.MaxConnectionsPerUser,
+		"stream_heartbeat_interval", c.Stream.HeartbeatInterval.String(),
+		"stream_history_retention", c.Stream.HistoryRetention.String(),
+	}
+}
+
+// deprecations summarizes the deprecated API versions, such as
+// "v1 deprecated 2025-01-01 sunset 2025-07-01"
+func (a APIConfig) deprecations() string {
+	var parts []string
+	for _, version := range APIVersions {
+		policy := a.Versions[version]
+		if policy.Deprecated.IsZero() {
+			continue
+		}
+		part := version + " deprecated " + policy.Deprecated.Format(time.DateOnly)
+		if !policy.Sunset.IsZero() {
+			part += " sunset " + policy.Sunset.Format(time.DateOnly)
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lookup returns the value of key, preferring the contents of the file named
+// by key_FILE so that secrets can be mounted rather than passed in the
+// environment
+func lookup(key string) (string, bool, error) {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", false, fmt.Errorf("reading %s_FILE: %w", key, err)
+		}
+		return strings.TrimSpace(string(data)), true, nil
+	}
+	value, ok := os.LookupEnv(key)
+	return value, ok && value != "", nil
+}
+
+func setString(target *string, key string) error {
+	value, ok, err := lookup(key)
+	if ok {
+		*target = value
+	}
+	return err
+}
+
+func setSecret(target *Secret, key string) error {
+	value, ok, err := lookup(key)
+	if ok {
+		*target = Secret(value)
+	}
+	return err
+}
+
+func setInt(target *int, key string) error {
+	value, ok, err := lookup(key)
+	if err != nil || !ok {
+		return err
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer, got %q", key, value)
+	}
+	*target = parsed
+	return nil
+}
+
+func setBool(target *bool, key string) error {
+	value, ok, err := lookup(key)
+	if err != nil || !ok {
+		return err
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("%s must be true or false, got %q", key, value)
+	}
+	*target = parsed
+	return nil
+}
+
+func setDuration(target *time.Duration, key string) error {
+	value, ok, err := lookup(key)
+	if err != nil || !ok {
+		return err
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration such as 15s, got %q", key, value)
+	}
+	*target = parsed
+	return nil
+}
+
+func setTime(target *time.Time, key string) error {
+	value, ok, err := lookup(key)
+	if err != nil || !ok {
+		return err
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		parsed, err = time.Parse(time.DateOnly, value)
+	}
+	if err != nil {
+		return fmt.Errorf("%s must be a date such as 2025-07-01 or an RFC 3339 time, got %q", key, value)
+	}
+	*target = parsed
+	return nil
+}
@@ -1,156 +1,165 @@
 package db
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"log"
-	"os"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/shared/logging"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 var DB *sql.DB
 
 // InitDB initializes the database connection
-func InitDB() {
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
+func InitDB(cfg config.DatabaseConfig) {
+	connStr := cfg.DSN()
 
 	var err error
-	DB, err = sql.Open("postgres", connStr)
+	// Open through otelsql so every query is recorded as a span
+	DB, err = otelsql.Open("postgres", connStr,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
 	if err != nil {
-		log.Printf("Failed to connect to database: %v", err)
-		return
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to connect to database", "error", err)
 	}
 
+	// Bound the pool so a burst of requests queues for a connection instead
+	// of exhausting the database
+	DB.SetMaxOpenConns(cfg.MaxOpenConns)
+	DB.SetMaxIdleConns(cfg.MaxIdleConns)
+	DB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	DB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
 	// Try to ping the database
 	maxRetries := 5
 	for i := 0; i < maxRetries; i++ {
-		err = DB.Ping()
+		err = ping(cfg.QueryTimeout)
 		if err == nil {
 			break
 		}
-		log.Printf("Failed to ping database (attempt %d/%d): %v", i+1, maxRetries, err)
+		logging.For(logging.ComponentDB).Warn("Failed to ping database", "attempt", i+1, "max_attempts", maxRetries, "error", err)
 		time.Sleep(2 * time.Second)
 	}
 
 	if err != nil {
-		log.Printf("Could not establish connection to database after %d attempts", maxRetries)
-		return
+		logging.Fatal(logging.For(logging.ComponentDB), "Could not establish connection to database", "attempts", maxRetries, "error", err)
 	}
 
-	log.Println("Successfully connected to database")
+	logging.For(logging.ComponentDB).Info("Successfully connected to database")
 }
 
-// CreateTables creates the necessary tables in the database
-func CreateTables() {
-	// Create Users table
-	_, err := DB.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			id SERIAL PRIMARY KEY,
-			username VARCHAR(50) UNIQUE NOT NULL,
-			password VARCHAR(100) NOT NULL,
-			email VARCHAR(100) UNIQUE NOT NULL,
-			address TEXT
-		)
-	`)
-	if err != nil {
-		log.Fatalf("Failed to create users table: %v", err)
-	}
-
-	// Create FoodItems table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS food_items (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(100) NOT NULL,
-			price NUMERIC(10,2) NOT NULL,
-			quantity INT NOT NULL
-		)
-	`)
-	if err != nil {
-		log.Fatalf("Failed to create food_items table: %v", err)
-	}
+// ping checks the connection, giving up after timeout
+func ping(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return DB.PingContext(ctx)
+}
 
-	// Create Orders table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS orders (
-			id SERIAL PRIMARY KEY,
-			user_id INT REFERENCES users(id),
-			total_price NUMERIC(10,2) NOT NULL,
-			status VARCHAR(20) NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)
-	`)
-	if err != nil {
-		log.Fatalf("Failed to create orders table: %v", err)
+// CloseDB closes the database connection pool
+func CloseDB() {
+	if DB == nil {
+		return
 	}
-
-	// Create OrderItems table
-	_, err = DB.Exec(`
-		CREATE TABLE IF NOT EXISTS order_items (
-			id SERIAL PRIMARY KEY,
-			order_id INT REFERENCES orders(id),
-			food_item_id INT REFERENCES food_items(id),
-			quantity INT NOT NULL
-		)
-	`)
-	if err != nil {
-		log.Fatalf("Failed to create order_items table: %v", err)
+	if err := DB.Close(); err != nil {
+		logging.For(logging.ComponentDB).Error("Error closing database", "error", err)
 	}
-
-	log.Println("Successfully created tables")
 }
 
-// SeedData adds some initial data to the database
+// SeedData adds a second restaurant, demo food items for both restaurants, a
+// test user and a kitchen user to an empty database. It is only meant for
+// development and must run after the migrations.
 func SeedData() {
 	// Check if food items already exist
 	var count int
 	err := DB.QueryRow("SELECT COUNT(*) FROM food_items").Scan(&count)
 	if err != nil {
-		log.Fatalf("Failed to check food items count: %v", err)
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to check food items count", "error", err)
 	}
 
 	// Only seed if no food items exist
 	if count == 0 {
 		// Seed some food items (all with price 10 INR and quantity 1000)
 		foodItems := []struct {
-			name string
+			name    string
+			station string
 		}{
-			{"Butter Chicken"},
-			{"Paneer Tikka"},
-			{"Biryani"},
-			{"Masala Dosa"},
-			{"Chole Bhature"},
-			{"Pav Bhaji"},
-			{"Gulab Jamun"},
-			{"Samosa"},
-			{"Naan"},
-			{"Tandoori Roti"},
+			{"Butter Chicken", "curry"},
+			{"Paneer Tikka", "tandoor"},
+			{"Biryani", "curry"},
+			{"Masala Dosa", "griddle"},
+			{"Chole Bhature", "fryer"},
+			{"Pav Bhaji", "griddle"},
+			{"Gulab Jamun", "dessert"},
+			{"Samosa", "fryer"},
+			{"Naan", "tandoor"},
+			{"Tandoori Roti", "tandoor"},
 		}
 
 		for _, item := range foodItems {
 			_, err := DB.Exec(
-				"INSERT INTO food_items (name, price, quantity) VALUES ($1, $2, $3)",
-				item.name, 10.0, 1000,
+				"INSERT INTO food_items (restaurant_id, name, price, quantity, station) VALUES ($1, $2, $3, $4, $5)",
+				1, item.name, 10.0, 1000, item.station,
 			)
 			if err != nil {
-				log.Fatalf("Failed to insert food item: %v", err)
+				logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert food item", "error", err)
 			}
 		}
 
-		log.Println("Successfully seeded food items")
+		// Seed a second restaurant with tax and a last-order cutoff, next to
+		// the default one created by the migrations, which is always open
+		var restaurantID int
+		err := DB.QueryRow(
+			`INSERT INTO restaurants (slug, name, timezone, currency, tax_rate, last_order_minutes)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			"coastal-kitchen", "Coastal Kitchen", "Asia/Kolkata", "INR", 0.05, 30,
+		).Scan(&restaurantID)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert restaurant", "error", err)
+		}
+
+		// It opens for lunch and dinner, and is closed on Mondays
+		for weekday := 0; weekday < 7; weekday++ {
+			if weekday == int(time.Monday) {
+				continue
+			}
+			for _, hours := range [][2]string{{"11:00", "15:00"}, {"18:00", "23:00"}} {
+				_, err := DB.Exec(
+					"INSERT INTO restaurant_hours (restaurant_id, weekday, opens_at, closes_at) VALUES ($1, $2, $3, $4)",
+					restaurantID, weekday, hours[0], hours[1],
+				)
+				if err != nil {
+					logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert opening hours", "error", err)
+				}
+			}
+		}
+
+		coastalItems := make(map[string]int) // Food item ID by name
+		for _, item := range foodItems[:5] {
+			var foodItemID int
+			err := DB.QueryRow(
+				"INSERT INTO food_items (restaurant_id, name, price, quantity, station) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+				restaurantID, item.name, 12.0, 500, item.station,
+			).Scan(&foodItemID)
+			if err != nil {
+				logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert food item", "error", err)
+			}
+			coastalItems[item.name] = foodItemID
+		}
+		seedItemOptions(coastalItems)
+		seedBundles(restaurantID, coastalItems)
+		seedInventory(restaurantID, coastalItems)
+
+		logging.For(logging.ComponentDB).Info("Successfully seeded food items")
 	}
 
 	// Check if users already exist
 	err = DB.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
 	if err != nil {
-		log.Fatalf("Failed to check users count: %v", err)
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to check users count", "error", err)
 	}
 
 	// Only seed if no users exist
@@ -161,9 +170,208 @@ func SeedData() {
 			"testuser", "password123", "test@example.com", "123 Test Street, Test City",
 		)
 		if err != nil {
-			log.Fatalf("Failed to insert default user: %v", err)
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert default user", "error", err)
+		}
+
+		// Seed a user for the kitchen display of the default restaurant
+		_, err = DB.Exec(
+			"INSERT INTO users (username, password, email, address, role, restaurant_id) VALUES ($1, $2, $3, $4, $5, $6)",
+			"kitchen", "password123", "kitchen@example.com", "", "kitchen", 1,
+		)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert kitchen user", "error", err)
+		}
+
+		logging.For(logging.ComponentDB).Info("Successfully seeded default users")
+	}
+}
+
+// seedItemOptions gives some of the food items of the second restaurant
+// variants and modifiers
+func seedItemOptions(foodItemIDs map[string]int) {
+	// Biryani comes in two sizes, each with its own stock
+	for _, variant := range []struct {
+		name  string
+		price float64
+	}{{"Half", 8.0}, {"Full", 14.0}} {
+		_, err := DB.Exec(
+			"INSERT INTO food_item_variants (food_item_id, name, price, quantity) VALUES ($1, $2, $3, $4)",
+			foodItemIDs["Biryani"], variant.name, variant.price, 200,
+		)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert variant", "error", err)
+		}
+	}
+
+	// Butter Chicken needs a spice level, and Masala Dosa has add-ons
+	groups := []struct {
+		foodItem  string
+		name      string
+		min, max  int
+		modifiers []string
+		prices    []float64
+	}{
+		{"Butter Chicken", "Spice level", 1, 1, []string{"Mild", "Medium", "Hot"}, []float64{0, 0, 0}},
+		{"Masala Dosa", "Add-ons", 0, 3, []string{"Extra cheese", "Extra chutney", "Ghee roast"}, []float64{2.0, 0.5, 1.5}},
+	}
+	for _, group := range groups {
+		var groupID int
+		err := DB.QueryRow(
+			"INSERT INTO modifier_groups (food_item_id, name, min_selections, max_selections) VALUES ($1, $2, $3, $4) RETURNING id",
+			foodItemIDs[group.foodItem], group.name, group.min, group.max,
+		).Scan(&groupID)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert modifier group", "error", err)
 		}
 
-		log.Println("Successfully seeded default user")
+		for i, name := range group.modifiers {
+			_, err := DB.Exec(
+				"INSERT INTO modifiers (group_id, name, price_delta) VALUES ($1, $2, $3)",
+				groupID, name, group.prices[i],
+			)
+			if err != nil {
+				logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert modifier", "error", err)
+			}
+		}
+	}
+}
+
+// seedBundles gives the second restaurant a thali, in which the main is
+// chosen and the side is fixed
+func seedBundles(restaurantID int, foodItemIDs map[string]int) {
+	var bundleID int
+	err := DB.QueryRow(
+		"INSERT INTO bundles (restaurant_id, name, price) VALUES ($1, $2, $3) RETURNING id",
+		restaurantID, "Thali", 20.0,
+	).Scan(&bundleID)
+	if err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert bundle", "error", err)
+	}
+
+	// The biryani comes as a half, or as a full one for a little more
+	variantIDs := make(map[string]int) // Biryani variant ID by name
+	rows, err := DB.Query("SELECT id, name FROM food_item_variants WHERE food_item_id = $1", foodItemIDs["Biryani"])
+	if err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to load variants", "error", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to load variants", "error", err)
+		}
+		variantIDs[name] = id
+	}
+	if err := rows.Err(); err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to load variants", "error", err)
+	}
+
+	type option struct {
+		foodItem   string
+		variant    string
+		priceDelta float64
+	}
+	slots := []struct {
+		name     string
+		quantity int
+		options  []option
+	}{
+		{"Main", 1, []option{{"Paneer Tikka", "", 0}, {"Biryani", "Half", 0}, {"Biryani", "Full", 4.0}}},
+		{"Side", 1, []option{{"Chole Bhature", "", 0}}},
+	}
+	for _, slot := range slots {
+		var slotID int
+		err := DB.QueryRow(
+			"INSERT INTO bundle_slots (bundle_id, name, quantity) VALUES ($1, $2, $3) RETURNING id",
+			bundleID, slot.name, slot.quantity,
+		).Scan(&slotID)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert bundle slot", "error", err)
+		}
+
+		for _, option := range slot.options {
+			var variantID *int
+			if id, ok := variantIDs[option.variant]; ok {
+				variantID = &id
+			}
+			_, err := DB.Exec(
+				"INSERT INTO bundle_slot_options (slot_id, food_item_id, variant_id, price_delta) VALUES ($1, $2, $3, $4)",
+				slotID, foodItemIDs[option.foodItem], variantID, option.priceDelta,
+			)
+			if err != nil {
+				logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert bundle option", "error", err)
+			}
+		}
+	}
+}
+
+// seedInventory gives the second restaurant ingredients, restocked once, and
+// recipes for some of its food items and for the extra cheese on dosas
+func seedInventory(restaurantID int, foodItemIDs map[string]int) {
+	ingredients := []struct {
+		name      string
+		unit      string
+		quantity  float64
+		threshold float64
+	}{
+		{"Chicken", "kg", 20, 4},
+		{"Paneer", "kg", 8, 2},
+		{"Rice", "kg", 40, 8},
+		{"Dosa batter", "l", 15, 3},
+		{"Cheese", "kg", 3, 0.5},
+	}
+	ingredientIDs := make(map[string]int) // Ingredient ID by name
+	for _, ingredient := range ingredients {
+		var ingredientID int
+		err := DB.QueryRow(
+			"INSERT INTO ingredients (restaurant_id, name, unit, quantity, low_stock_threshold) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			restaurantID, ingredient.name, ingredient.unit, ingredient.quantity, ingredient.threshold,
+		).Scan(&ingredientID)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert ingredient", "error", err)
+		}
+		ingredientIDs[ingredient.name] = ingredientID
+
+		_, err = DB.Exec(
+			"INSERT INTO stock_movements (ingredient_id, change, quantity_after, reason, note) VALUES ($1, $2, $3, $4, $5)",
+			ingredientID, ingredient.quantity, ingredient.quantity, "restock", "Initial stock",
+		)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert stock movement", "error", err)
+		}
+	}
+
+	// A portion of each food item, in the unit of the ingredient
+	recipes := []struct {
+		foodItem   string
+		ingredient string
+		quantity   float64
+	}{
+		{"Butter Chicken", "Chicken", 0.25},
+		{"Paneer Tikka", "Paneer", 0.2},
+		{"Biryani", "Rice", 0.3},
+		{"Biryani", "Chicken", 0.15},
+		{"Masala Dosa", "Dosa batter", 0.25},
+	}
+	for _, recipe := range recipes {
+		_, err := DB.Exec(
+			"INSERT INTO food_item_ingredients (food_item_id, ingredient_id, quantity) VALUES ($1, $2, $3)",
+			foodItemIDs[recipe.foodItem], ingredientIDs[recipe.ingredient], recipe.quantity,
+		)
+		if err != nil {
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert recipe", "error", err)
+		}
+	}
+
+	_, err := DB.Exec(
+		`INSERT INTO modifier_ingredients (modifier_id, ingredient_id, quantity)
+		SELECT m.id, $1, $2 FROM modifiers m
+		JOIN modifier_groups g ON g.id = m.group_id
+		WHERE g.food_item_id = $3 AND m.name = $4`,
+		ingredientIDs["Cheese"], 0.05, foodItemIDs["Masala Dosa"], "Extra cheese",
+	)
+	if err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert recipe", "error", err)
 	}
 }
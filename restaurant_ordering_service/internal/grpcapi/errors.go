@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"github.com/shared/apperrors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain names the service in the ErrorInfo of error statuses
+const errorDomain = "restaurant-service"
+
+// grpcCodes maps each kind of error to the gRPC status code it is sent as
+var grpcCodes = map[apperrors.Kind]codes.Code{
+	apperrors.KindInternal:     codes.Internal,
+	apperrors.KindInvalid:      codes.InvalidArgument,
+	apperrors.KindUnauthorized: codes.Unauthenticated,
+	apperrors.KindForbidden:    codes.PermissionDenied,
+	apperrors.KindNotFound:     codes.NotFound,
+	apperrors.KindConflict:     codes.AlreadyExists,
+	apperrors.KindUnavailable:  codes.Unavailable,
+	apperrors.KindCanceled:     codes.Canceled,
+
+	apperrors.KindTooManyRequests: codes.ResourceExhausted,
+}
+
+// toStatus converts an error returned by a method into a gRPC status. The
+// error code travels as the reason of an ErrorInfo detail and invalid fields
+// as a BadRequest detail, mirroring the error body of the REST API. Errors
+// that already are statuses are returned unchanged.
+func toStatus(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	appErr := apperrors.From(err)
+	st := status.New(grpcCodes[appErr.Kind], appErr.Message)
+
+	info := &errdetails.ErrorInfo{Reason: string(appErr.Code), Domain: errorDomain}
+	if len(appErr.Details) > 0 {
+		info.Metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			info.Metadata[key] = fmt.Sprint(value)
+		}
+	}
+	details := []protoadapt.MessageV1{info}
+
+	if len(appErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range appErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
@@ -0,0 +1,177 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/pkg/servicetoken"
+	"github.com/shared/apperrors"
+	"github.com/shared/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// Audience is the audience service tokens for this service must be minted for
+const Audience = "restaurant-service"
+
+// requestIDKey is the metadata key carrying the request ID between services
+const requestIDKey = "x-request-id"
+
+// maxRequestIDLength bounds caller supplied request IDs
+const maxRequestIDLength = 128
+
+// unauthenticatedCaller labels calls whose caller could not be identified
+const unauthenticatedCaller = "unauthenticated"
+
+// interceptor authenticates, logs and measures every call, and converts the
+// errors of the methods into gRPC statuses
+type interceptor struct {
+	secret          []byte
+	allowedServices []string
+}
+
+// unary intercepts calls of unary methods
+func (i *interceptor) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = withRequestID(ctx)
+	start := time.Now()
+
+	caller, err := i.authenticate(ctx, info.FullMethod)
+	var resp any
+	if err == nil {
+		err = protect(func() error {
+			var handlerErr error
+			resp, handlerErr = handler(ctx, req)
+			return handlerErr
+		})
+	}
+	return resp, finish(ctx, info.FullMethod, caller, start, err)
+}
+
+// stream intercepts calls of streaming methods
+func (i *interceptor) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := withRequestID(ss.Context())
+	start := time.Now()
+
+	caller, err := i.authenticate(ctx, info.FullMethod)
+	if err == nil {
+		err = protect(func() error {
+			return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+	return finish(ctx, info.FullMethod, caller, start, err)
+}
+
+// authenticate verifies the service token in the authorization metadata and
+// returns the name of the calling service. Health checks are answered
+// without a token so that probes can reach them.
+func (i *interceptor) authenticate(ctx context.Context, method string) (string, error) {
+	if strings.HasPrefix(method, "/"+grpc_health_v1.Health_ServiceDesc.ServiceName+"/") {
+		return unauthenticatedCaller, nil
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return unauthenticatedCaller, apperrors.Unauthorized(apperrors.CodeUnauthorized, "A service token is required")
+	}
+	token, found := strings.CutPrefix(values[0], "Bearer ")
+	if !found {
+		return unauthenticatedCaller, apperrors.Unauthorized(apperrors.CodeUnauthorized, "Authorization metadata must be in the format 'Bearer {token}'")
+	}
+
+	caller, err := servicetoken.Verify(i.secret, token, Audience)
+	if err != nil {
+		return unauthenticatedCaller, &apperrors.Error{
+			Kind:    apperrors.KindUnauthorized,
+			Code:    apperrors.CodeUnauthorized,
+			Message: "Invalid or expired service token",
+			Err:     err,
+		}
+	}
+
+	if len(i.allowedServices) > 0 && !slices.Contains(i.allowedServices, caller) {
+		return caller, apperrors.Forbidden(apperrors.CodeServiceNotAllowed, "Service "+caller+" may not call this API")
+	}
+	return caller, nil
+}
+
+// protect turns a panic in fn into an internal error so that one bad call
+// does not bring the server down
+func protect(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = apperrors.Internal(fmt.Errorf("panic: %v", r))
+		}
+	}()
+	return fn()
+}
+
+// finish logs and measures a call and returns its error as a gRPC status.
+// The log line keeps the underlying cause, which callers never see.
+func finish(ctx context.Context, method, caller string, start time.Time, err error) error {
+	code := codes.OK
+	var result error
+	if err != nil {
+		st := toStatus(err)
+		code = st.Code()
+		result = st.Err()
+	}
+	metrics.ObserveGRPC(method, code.String(), caller, time.Since(start))
+
+	logger := logging.For(logging.ComponentGRPC)
+	args := []any{
+		"method", method,
+		"code", code.String(),
+		"caller", caller,
+		"latency_ms", time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		args = append(args, "error", err.Error())
+	}
+
+	switch code {
+	case codes.OK, codes.Canceled:
+		logger.InfoContext(ctx, "Call completed", args...)
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss:
+		logger.ErrorContext(ctx, "Call completed", args...)
+	default:
+		logger.WarnContext(ctx, "Call completed", args...)
+	}
+	return result
+}
+
+// withRequestID stores the request ID sent by the caller, or a new one, in
+// the context so that every log line for the call includes it
+func withRequestID(ctx context.Context) context.Context {
+	requestID := ""
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDKey); len(values) > 0 {
+		requestID = values[0]
+	}
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			requestID = "unknown"
+		} else {
+			requestID = hex.EncodeToString(buf)
+		}
+	}
+	return logging.WithRequestID(ctx, requestID)
+}
+
+// serverStream replaces the context of a stream with one carrying the
+// request ID
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
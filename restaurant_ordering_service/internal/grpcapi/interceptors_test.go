@@ -0,0 +1,104 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/restaurant_ordering_service/pkg/servicetoken"
+	"github.com/shared/apperrors"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthenticate(t *testing.T) {
+	secret := []byte("test-secret")
+	i := &interceptor{secret: secret, allowedServices: []string{"feedback-service"}}
+	token := func(service, audience string) string {
+		token, err := servicetoken.Sign(secret, service, audience, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	const method = "/restaurant.v1.RestaurantService/GetOrder"
+	tests := []struct {
+		name          string
+		method        string
+		authorization string // Not sent if empty
+		caller        string
+		code          apperrors.Code // Of the error, if the call is refused
+	}{
+		{
+			name:          "allowed service",
+			method:        method,
+			authorization: "Bearer " + token("feedback-service", Audience),
+			caller:        "feedback-service",
+		},
+		{
+			name:   "health check without a token",
+			method: "/grpc.health.v1.Health/Check",
+			caller: unauthenticatedCaller,
+		},
+		{
+			name:   "health watch without a token",
+			method: "/grpc.health.v1.Health/Watch",
+			caller: unauthenticatedCaller,
+		},
+		{
+			name:   "method of a service named like the health service",
+			method: "/grpc.health.v1.HealthCheck/Check",
+			caller: unauthenticatedCaller,
+			code:   apperrors.CodeUnauthorized,
+		},
+		{
+			name:   "no token",
+			method: method,
+			caller: unauthenticatedCaller,
+			code:   apperrors.CodeUnauthorized,
+		},
+		{
+			name:          "not a bearer token",
+			method:        method,
+			authorization: token("feedback-service", Audience),
+			caller:        unauthenticatedCaller,
+			code:          apperrors.CodeUnauthorized,
+		},
+		{
+			name:          "token for another service",
+			method:        method,
+			authorization: "Bearer " + token("feedback-service", "kitchen-service"),
+			caller:        unauthenticatedCaller,
+			code:          apperrors.CodeUnauthorized,
+		},
+		{
+			name:          "service not allowed",
+			method:        method,
+			authorization: "Bearer " + token("billing-service", Audience),
+			caller:        "billing-service",
+			code:          apperrors.CodeServiceNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			caller, err := i.authenticate(ctx, tt.method)
+			if caller != tt.caller {
+				t.Errorf("authenticate() caller = %q, want %q", caller, tt.caller)
+			}
+			if tt.code == "" {
+				if err != nil {
+					t.Errorf("authenticate() error = %v", err)
+				}
+				return
+			}
+			if !apperrors.HasCode(err, tt.code) {
+				t.Errorf("authenticate() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
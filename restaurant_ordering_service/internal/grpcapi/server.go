@@ -0,0 +1,251 @@
+// Package grpcapi serves the internal gRPC API of the restaurant service to
+// other services. It calls the same service layer as the REST handlers and
+// is also reachable over HTTP through the gateway.
+package grpcapi
+
+import (
+	"context"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/orderstream"
+	"github.com/restaurant_ordering_service/internal/service"
+	restaurantv1 "github.com/restaurant_ordering_service/pkg/pb/restaurant/v1"
+	"github.com/shared/apperrors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Server implements the RestaurantService
+type Server struct {
+	restaurantv1.UnimplementedRestaurantServiceServer
+
+	restaurants       *service.Restaurants
+	menu              *service.Menu
+	orders            *service.Orders
+	hub               *orderstream.Hub
+	defaultRestaurant string
+}
+
+// NewServer creates a Server. Requests without a restaurant are for the one
+// with the ID or slug defaultRestaurant. Order status streams are fed from
+// hub, as those served over SSE and WebSocket are.
+func NewServer(restaurants *service.Restaurants, menu *service.Menu, orders *service.Orders, hub *orderstream.Hub, defaultRestaurant string) *Server {
+	return &Server{
+		restaurants:       restaurants,
+		menu:              menu,
+		orders:            orders,
+		hub:               hub,
+		defaultRestaurant: defaultRestaurant,
+	}
+}
+
+// ListFoodItems returns the menu of a restaurant
+func (s *Server) ListFoodItems(ctx context.Context, req *restaurantv1.ListFoodItemsRequest) (*restaurantv1.ListFoodItemsResponse, error) {
+	restaurant, err := s.restaurant(ctx, req.GetRestaurantId())
+	if err != nil {
+		return nil, err
+	}
+
+	foodItems, err := s.menu.List(ctx, restaurant)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &restaurantv1.ListFoodItemsResponse{FoodItems: make([]*restaurantv1.FoodItem, 0, len(foodItems))}
+	for _, foodItem := range foodItems {
+		response.FoodItems = append(response.FoodItems, toFoodItem(foodItem))
+	}
+	return response, nil
+}
+
+// GetFoodItem returns a single food item
+func (s *Server) GetFoodItem(ctx context.Context, req *restaurantv1.GetFoodItemRequest) (*restaurantv1.FoodItem, error) {
+	if req.GetId() < 1 {
+		return nil, errInvalidID("id")
+	}
+
+	restaurant, err := s.restaurant(ctx, req.GetRestaurantId())
+	if err != nil {
+		return nil, err
+	}
+
+	foodItem, err := s.menu.Get(ctx, restaurant.ID, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return toFoodItem(foodItem), nil
+}
+
+// GetOrder returns an order with its items
+func (s *Server) GetOrder(ctx context.Context, req *restaurantv1.GetOrderRequest) (*restaurantv1.Order, error) {
+	if req.GetId() < 1 {
+		return nil, errInvalidID("id")
+	}
+
+	order, err := s.orders.Get(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return toOrder(order), nil
+}
+
+// WatchOrderStatus streams the status of an order until it is final. The
+// current status is sent first, then each change as the order events of
+// every replica reach the hub. The stream ends with codes.Unavailable when
+// the hub ends the subscription, because the client fell behind or the
+// server is shutting down; the client then reconnects and is sent the
+// current status again.
+func (s *Server) WatchOrderStatus(req *restaurantv1.WatchOrderStatusRequest, stream grpc.ServerStreamingServer[restaurantv1.OrderStatusEvent]) error {
+	if req.GetOrderId() < 1 {
+		return errInvalidID("order_id")
+	}
+	ctx := stream.Context()
+
+	// Subscribe before reading the order so that a change in between is
+	// not missed
+	sub, _, _, err := s.hub.Subscribe(int(req.GetOrderId()), 0, "")
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+	order, err := s.orders.Get(ctx, int(req.GetOrderId()))
+	if err != nil {
+		return err
+	}
+
+	send := func(status string) error {
+		return stream.Send(&restaurantv1.OrderStatusEvent{
+			OrderId:    int32(order.ID),
+			Status:     status,
+			ObservedAt: timestamppb.Now(),
+		})
+	}
+	current := order.Status
+	if err := send(current); err != nil {
+		return err
+	}
+	for !service.IsFinal(current) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.Unavailable, "The stream was ended by the server, reconnect to keep watching")
+			}
+			if event.Status == current {
+				continue
+			}
+			current = event.Status
+			if err := send(current); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// restaurant returns the restaurant a request is for. Zero stands for the
+// default restaurant.
+func (s *Server) restaurant(ctx context.Context, id int32) (models.Restaurant, error) {
+	if id < 0 {
+		return models.Restaurant{}, errInvalidID("restaurant_id")
+	}
+	if id == 0 {
+		return s.restaurants.Resolve(ctx, s.defaultRestaurant)
+	}
+	return s.restaurants.Get(ctx, int(id))
+}
+
+// errInvalidID reports an ID field that is not a positive number
+func errInvalidID(field string) error {
+	return apperrors.Validation(models.FieldError{Field: field, Message: "must be at least 1"})
+}
+
+// toFoodItem converts a food item with its variants and modifier groups to
+// its protobuf message
+func toFoodItem(foodItem models.FoodItem) *restaurantv1.FoodItem {
+	message := &restaurantv1.FoodItem{
+		Id:           int32(foodItem.ID),
+		Name:         foodItem.Name,
+		Price:        foodItem.Price,
+		Quantity:     int32(foodItem.Quantity),
+		RestaurantId: int32(foodItem.RestaurantID),
+	}
+	for _, variant := range foodItem.Variants {
+		message.Variants = append(message.Variants, &restaurantv1.Variant{
+			Id:       int32(variant.ID),
+			Name:     variant.Name,
+			Price:    variant.Price,
+			Quantity: int32(variant.Quantity),
+		})
+	}
+	for _, group := range foodItem.ModifierGroups {
+		groupMessage := &restaurantv1.ModifierGroup{
+			Id:            int32(group.ID),
+			Name:          group.Name,
+			MinSelections: int32(group.MinSelections),
+			MaxSelections: int32(group.MaxSelections),
+		}
+		for _, modifier := range group.Modifiers {
+			groupMessage.Modifiers = append(groupMessage.Modifiers, &restaurantv1.Modifier{
+				Id:         int32(modifier.ID),
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+			})
+		}
+		message.ModifierGroups = append(message.ModifierGroups, groupMessage)
+	}
+	return message
+}
+
+// toOrder converts an order with its items and bundles to their protobuf
+// message
+func toOrder(order models.Order) *restaurantv1.Order {
+	message := &restaurantv1.Order{
+		Id:           int32(order.ID),
+		UserId:       int32(order.UserID),
+		TotalPrice:   order.TotalPrice,
+		Status:       order.Status,
+		RestaurantId: int32(order.RestaurantID),
+		Tax:          order.Tax,
+		Currency:     order.Currency,
+		OrderItems:   toOrderItems(order.OrderItems),
+	}
+	for _, bundle := range order.Bundles {
+		message.Bundles = append(message.Bundles, &restaurantv1.OrderBundle{
+			Id:        int32(bundle.ID),
+			BundleId:  int32(bundle.BundleID),
+			Name:      bundle.Name,
+			Quantity:  int32(bundle.Quantity),
+			UnitPrice: bundle.UnitPrice,
+			Items:     toOrderItems(bundle.Items),
+		})
+	}
+	return message
+}
+
+// toOrderItems converts order items to their protobuf messages
+func toOrderItems(items []models.OrderItem) []*restaurantv1.OrderItem {
+	messages := make([]*restaurantv1.OrderItem, 0, len(items))
+	for _, item := range items {
+		itemMessage := &restaurantv1.OrderItem{
+			Id:         int32(item.ID),
+			FoodItemId: int32(item.FoodItemID),
+			Quantity:   int32(item.Quantity),
+			VariantId:  int32(item.VariantID),
+			Variant:    item.Variant,
+			UnitPrice:  item.UnitPrice,
+		}
+		for _, modifier := range item.Modifiers {
+			itemMessage.Modifiers = append(itemMessage.Modifiers, &restaurantv1.OrderItemModifier{
+				ModifierId: int32(modifier.ModifierID),
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+			})
+		}
+		messages = append(messages, itemMessage)
+	}
+	return messages
+}
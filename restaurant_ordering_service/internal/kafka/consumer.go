@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/segmentio/kafka-go"
+	"github.com/shared/logging"
+)
+
+// streamGroupPrefix starts the consumer group ID of each replica's event
+// stream reader. Every replica uses its own group so that it receives the
+// events of all partitions.
+const streamGroupPrefix = "restaurant-order-stream-"
+
+// StreamReader reads the order and inventory events that feed the live
+// streams
+var StreamReader *kafka.Reader
+
+// streamStopped is closed once the stream consumer loop has exited
+var streamStopped chan struct{}
+
+// StreamHandlers receive the events of the live streams, by topic
+type StreamHandlers struct {
+	Order     func(models.OrderEvent)
+	Inventory func(models.InventoryEvent)
+}
+
+// InitEventStream starts consuming the order and inventory events published
+// by every replica and passes each one to its handler. Only events
+// published after the replica started are read, and offsets are never
+// committed, since the streams only need live events. Consumption stops
+// when ctx is cancelled.
+func InitEventStream(ctx context.Context, handlers StreamHandlers) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	StreamReader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     brokers,
+		GroupTopics: []string{OrderTopic, InventoryTopic},
+		GroupID:     streamGroupPrefix + hostname,
+		StartOffset: kafka.LastOffset,
+		MaxWait:     500 * time.Millisecond,
+	})
+
+	logging.For(logging.ComponentKafka).Info("Kafka event stream consumer initialized successfully", "group_id", streamGroupPrefix+hostname)
+
+	streamStopped = make(chan struct{})
+	go func() {
+		defer close(streamStopped)
+		consumeEventStream(ctx, handlers)
+	}()
+}
+
+// CloseEventStream waits for the stream consumer loop to exit and closes the
+// reader. The context passed to InitEventStream must be cancelled first;
+// CloseEventStream gives up waiting once ctx is done.
+func CloseEventStream(ctx context.Context) {
+	if streamStopped != nil {
+		select {
+		case <-streamStopped:
+		case <-ctx.Done():
+			logging.For(logging.ComponentKafka).Error("Timed out waiting for Kafka event stream consumer to stop", "error", ctx.Err())
+		}
+	}
+
+	if StreamReader != nil {
+		if err := StreamReader.Close(); err != nil {
+			logging.For(logging.ComponentKafka).Error("Error closing Kafka event stream reader", "error", err)
+		}
+	}
+}
+
+// consumeEventStream reads order and inventory events until ctx is
+// cancelled
+func consumeEventStream(ctx context.Context, handlers StreamHandlers) {
+	for {
+		message, err := StreamReader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				logging.For(logging.ComponentKafka).Info("Kafka event stream consumer stopped")
+				return
+			}
+			logging.For(logging.ComponentKafka).Error("Error reading event stream message", "error", err)
+			continue
+		}
+
+		switch message.Topic {
+		case OrderTopic:
+			var event models.OrderEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				logging.For(logging.ComponentKafka).Error("Skipping malformed order event", "offset", message.Offset, "partition", message.Partition, "error", err)
+				continue
+			}
+			handlers.Order(event)
+		case InventoryTopic:
+			var event models.InventoryEvent
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				logging.For(logging.ComponentKafka).Error("Skipping malformed inventory event", "offset", message.Offset, "partition", message.Partition, "error", err)
+				continue
+			}
+			handlers.Inventory(event)
+		}
+	}
+}
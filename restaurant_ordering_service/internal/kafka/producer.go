@@ -3,84 +3,242 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
-	"os"
+	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/restaurant_ordering_service/internal/config"
 	"github.com/restaurant_ordering_service/internal/models"
 	"github.com/segmentio/kafka-go"
+	"github.com/shared/logging"
+	"github.com/shared/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
-	OrderTopic = "orders"
+	OrderTopic     = "orders"
+	InventoryTopic = "inventory"
+
+	// RequestIDHeader carries the ID of the request that caused an event
+	RequestIDHeader = "X-Request-ID"
+
+	tracerName = "github.com/restaurant_ordering_service/internal/kafka"
 )
 
 var Writer *kafka.Writer
 
-// InitKafka initializes the Kafka producer
-func InitKafka() {
-	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
-	if kafkaBrokers == "" {
-		kafkaBrokers = "localhost:9092"
-	}
+// pending tracks asynchronous publishes that have not finished yet
+var pending sync.WaitGroup
+var pendingCount atomic.Int64
 
+// brokers holds the configured broker addresses for health checks
+var brokers []string
+
+// InitKafka initializes the Kafka producer. Each message names its topic.
+func InitKafka(cfg config.KafkaConfig) {
+	brokers = cfg.Brokers
+	// Messages are partitioned by key, so that the events of an order, or
+	// of an ingredient or food item, are consumed in the order published
 	Writer = &kafka.Writer{
-		Addr:     kafka.TCP(kafkaBrokers),
-		Topic:    OrderTopic,
-		Balancer: &kafka.LeastBytes{},
+		Addr:     kafka.TCP(brokers...),
+		Balancer: &kafka.Hash{},
 	}
 
-	log.Println("Kafka producer initialized successfully")
+	logging.For(logging.ComponentKafka).Info("Kafka producer initialized successfully")
 }
 
-// CloseKafka closes the Kafka producer connection
-func CloseKafka() {
+// CloseKafka waits for in-flight publishes to finish and then closes the
+// Kafka producer, flushing any buffered messages. It gives up waiting once
+// ctx is done.
+func CloseKafka(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logging.For(logging.ComponentKafka).Error("Timed out waiting for pending Kafka publishes", "error", ctx.Err())
+	}
+
 	if err := Writer.Close(); err != nil {
-		log.Printf("Error closing Kafka writer: %v", err)
+		logging.For(logging.ComponentKafka).Error("Error closing Kafka writer", "error", err)
 	}
 }
 
-// PublishOrderEvent publishes an order event to the Kafka topic
-func PublishOrderEvent(order models.Order, foodItems map[int]string) error {
-	// Prepare order items for the event
+// PublishOrderEventAsync publishes an order event in the background. The
+// publish is tracked so that CloseKafka can wait for it during shutdown. It
+// keeps the trace of ctx but is not cancelled when ctx is.
+func PublishOrderEventAsync(ctx context.Context, order models.Order, foodItems map[int]string) {
+	ctx = context.WithoutCancel(ctx)
+
+	pending.Add(1)
+	pendingCount.Add(1)
+	go func() {
+		defer pending.Done()
+		defer pendingCount.Add(-1)
+		if err := PublishOrderEvent(ctx, order, foodItems); err != nil {
+			logging.For(logging.ComponentKafka).ErrorContext(ctx, "Failed to publish order event", "order_id", order.ID, "status", order.Status, "error", err)
+		}
+	}()
+}
+
+// PublishInventoryEventsAsync publishes inventory events in the background,
+// tracked like PublishOrderEventAsync. The events are written one after the
+// other, so that those of a single stock change reach the topic in order.
+func PublishInventoryEventsAsync(ctx context.Context, events []models.InventoryEvent) {
+	if len(events) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	pending.Add(1)
+	pendingCount.Add(1)
+	go func() {
+		defer pending.Done()
+		defer pendingCount.Add(-1)
+		for _, event := range events {
+			if err := PublishInventoryEvent(ctx, event); err != nil {
+				logging.For(logging.ComponentKafka).ErrorContext(ctx, "Failed to publish inventory event", "alert_id", event.AlertID, "type", event.Type, "error", err)
+			}
+		}
+	}()
+}
+
+// PendingPublishes returns the number of asynchronous publishes in flight
+func PendingPublishes() int64 {
+	return pendingCount.Load()
+}
+
+// Ping checks that at least one of the configured brokers is reachable
+func Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return fmt.Errorf("no reachable Kafka broker: %w", err)
+}
+
+// PublishOrderEvent publishes an order event to the orders topic. The trace
+// context of ctx is injected into the message headers so that consumers can
+// continue the trace.
+func PublishOrderEvent(ctx context.Context, order models.Order, foodItems map[int]string) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, OrderTopic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(OrderTopic),
+		),
+	)
+	defer span.End()
+
+	// Prepare order items for the event, with those of the bundles
 	var items []models.Item
-	for _, orderItem := range order.OrderItems {
+	for _, orderItem := range order.AllItems() {
 		items = append(items, models.Item{
-			FoodItemID: orderItem.FoodItemID,
-			Name:       foodItems[orderItem.FoodItemID],
-			Quantity:   orderItem.Quantity,
+			FoodItemID:    orderItem.FoodItemID,
+			Name:          foodItems[orderItem.FoodItemID],
+			VariantID:     orderItem.VariantID,
+			Variant:       orderItem.Variant,
+			Quantity:      orderItem.Quantity,
+			UnitPrice:     orderItem.UnitPrice,
+			Modifiers:     orderItem.Modifiers,
+			OrderBundleID: orderItem.OrderBundleID,
 		})
 	}
 
+	// The bundles refer to their items by ID, so they are sent without them
+	var bundles []models.OrderBundle
+	for _, bundle := range order.Bundles {
+		bundle.Items = nil
+		bundles = append(bundles, bundle)
+	}
+
 	// Create event
 	event := models.OrderEvent{
-		OrderID:    order.ID,
-		UserID:     order.UserID,
-		TotalPrice: order.TotalPrice,
-		Status:     order.Status,
-		Items:      items,
-		Timestamp:  time.Now().Unix(),
+		EventID:      order.StatusEventID(),
+		OrderID:      order.ID,
+		RestaurantID: order.RestaurantID,
+		UserID:       order.UserID,
+		TotalPrice:   order.TotalPrice,
+		Status:       order.Status,
+		Items:        items,
+		Bundles:      bundles,
+		Timestamp:    time.Now().Unix(),
 	}
 
-	// Serialize to JSON
-	value, err := json.Marshal(event)
-	if err != nil {
+	// Serialize and publish message
+	if err := write(ctx, OrderTopic, strconv.Itoa(event.OrderID), event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	// Publish message
-	err = Writer.WriteMessages(context.Background(),
-		kafka.Message{
-			Key:   []byte(strconv.Itoa(event.OrderID)),
-			Value: value,
-		},
+	logging.For(logging.ComponentKafka).InfoContext(ctx, "Order event published to Kafka", "order_id", event.OrderID, "status", event.Status)
+	return nil
+}
+
+// PublishInventoryEvent publishes an inventory event to the inventory topic,
+// keyed by ingredient or food item so that the events of each stay in order
+func PublishInventoryEvent(ctx context.Context, event models.InventoryEvent) error {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, InventoryTopic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(InventoryTopic),
+		),
 	)
+	defer span.End()
 
-	if err != nil {
+	if err := write(ctx, InventoryTopic, inventoryKey(event), event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return err
 	}
 
-	log.Printf("Order event published to Kafka: OrderID=%d, Status=%s", event.OrderID, event.Status)
+	logging.For(logging.ComponentKafka).InfoContext(ctx, "Inventory event published to Kafka", "alert_id", event.AlertID, "type", event.Type)
 	return nil
 }
+
+// inventoryKey returns the message key of an inventory event, naming the
+// ingredient or food item it is about
+func inventoryKey(event models.InventoryEvent) string {
+	if event.IngredientID != 0 {
+		return "ingredient-" + strconv.Itoa(event.IngredientID)
+	}
+	return "food-item-" + strconv.Itoa(event.FoodItemID)
+}
+
+// write serializes an event to JSON and writes it to a topic, with the
+// trace context and request ID of ctx in the message headers
+func write(ctx context.Context, topic, key string, event any) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	message := kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+	}
+	otel.GetTextMapPropagator().Inject(ctx, tracing.MessageCarrier{Message: &message})
+	if requestID := logging.RequestID(ctx); requestID != "" {
+		tracing.MessageCarrier{Message: &message}.Set(RequestIDHeader, requestID)
+	}
+
+	return Writer.WriteMessages(ctx, message)
+}
@@ -0,0 +1,36 @@
+package kafka
+
+import "sync"
+
+// WriterSnapshot holds cumulative producer counters since startup
+type WriterSnapshot struct {
+	Writes   int64
+	Messages int64
+	Bytes    int64
+	Errors   int64
+	Retries  int64
+}
+
+var (
+	statsMu     sync.Mutex
+	writerTotal WriterSnapshot
+)
+
+// Snapshot returns the cumulative producer statistics. kafka-go resets its
+// counters on every Stats call, so all callers must go through Snapshot.
+func Snapshot() WriterSnapshot {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if Writer == nil {
+		return writerTotal
+	}
+
+	stats := Writer.Stats()
+	writerTotal.Writes += stats.Writes
+	writerTotal.Messages += stats.Messages
+	writerTotal.Bytes += stats.Bytes
+	writerTotal.Errors += stats.Errors
+	writerTotal.Retries += stats.Retries
+	return writerTotal
+}
@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/restaurant_ordering_service/internal/kafka"
+)
+
+const namespace = "restaurant"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	apiVersionRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_version_requests_total",
+		Help:      "API requests by API version and route, to track clients of old versions.",
+	}, []string{"version", "route"})
+
+	grpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "gRPC calls by method, status code and calling service.",
+	}, []string{"method", "code", "caller"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "gRPC call latency by method and status code. Streams are timed until they end.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	// OrderStreams counts the open order status streams by transport, sse or
+	// websocket, the open kitchen ticket streams as transport kitchen and the
+	// open stock alert streams as transport alerts
+	OrderStreams = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "order_streams_open",
+		Help:      "Open order status, kitchen ticket and stock alert streams, by transport.",
+	}, []string{"transport"})
+
+	// OrderStreamsRejected counts streams refused by a connection limit
+	OrderStreamsRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_streams_rejected_total",
+		Help:      "Order status and kitchen ticket streams refused, by the limit that was reached.",
+	}, []string{"limit"})
+
+	// OrderStreamsDropped counts streams ended because the client could not
+	// keep up with its events
+	OrderStreamsDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "order_streams_dropped_total",
+		Help:      "Order status and kitchen ticket streams ended because the client fell behind.",
+	})
+
+	// PrepTime observes how long the kitchen took to prepare an order item,
+	// by station
+	PrepTime = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kitchen_prep_seconds",
+		Help:      "Time from starting to finishing an order item in the kitchen, by station.",
+		Buckets:   []float64{30, 60, 120, 300, 600, 900, 1200, 1800, 2700, 3600},
+	}, []string{"station"})
+
+	// OrdersPlaced counts orders created through the API
+	OrdersPlaced = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_placed_total",
+		Help:      "Orders placed.",
+	})
+
+	// OrdersCompleted counts orders that were paid for successfully
+	OrdersCompleted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_completed_total",
+		Help:      "Orders completed by a successful transaction.",
+	})
+
+	// Revenue sums the total price of completed orders
+	Revenue = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "revenue_total",
+		Help:      "Total price of completed orders, in INR.",
+	})
+
+	// StockOuts counts orders and transactions rejected because a food item
+	// ran out
+	StockOuts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stock_outs_total",
+		Help:      "Orders and transactions rejected for insufficient stock, by food item.",
+	}, []string{"food_item"})
+
+	// StockAlerts counts the stock alerts raised for ingredients and food
+	// items
+	StockAlerts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stock_alerts_total",
+		Help:      "Stock alerts raised, by type.",
+	}, []string{"type"})
+)
+
+// Middleware records the count and latency of every request. Requests that
+// do not match a route are grouped under "unmatched" to bound cardinality.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// APIVersion counts the requests served by an API version
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		apiVersionRequests.WithLabelValues(version, c.FullPath()).Inc()
+	}
+}
+
+// ObserveGRPC records a finished gRPC call. caller is the calling service, or
+// "unauthenticated" if it could not be identified.
+func ObserveGRPC(method, code, caller string, elapsed time.Duration) {
+	grpcRequests.WithLabelValues(method, code, caller).Inc()
+	grpcDuration.WithLabelValues(method, code).Observe(elapsed.Seconds())
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// RegisterDB exposes the connection pool statistics of db
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "restaurant_db"))
+}
+
+// RegisterKafka exposes the Kafka producer statistics
+func RegisterKafka() {
+	prometheus.MustRegister(&kafkaCollector{})
+}
+
+var (
+	kafkaWritesDesc = prometheus.NewDesc(namespace+"_kafka_writer_writes_total",
+		"Write calls made by the Kafka producer.", nil, nil)
+	kafkaMessagesDesc = prometheus.NewDesc(namespace+"_kafka_writer_messages_total",
+		"Messages written by the Kafka producer.", nil, nil)
+	kafkaBytesDesc = prometheus.NewDesc(namespace+"_kafka_writer_bytes_total",
+		"Bytes written by the Kafka producer.", nil, nil)
+	kafkaErrorsDesc = prometheus.NewDesc(namespace+"_kafka_writer_errors_total",
+		"Errors returned by the Kafka producer.", nil, nil)
+	kafkaRetriesDesc = prometheus.NewDesc(namespace+"_kafka_writer_retries_total",
+		"Write retries made by the Kafka producer.", nil, nil)
+	kafkaPendingDesc = prometheus.NewDesc(namespace+"_kafka_pending_publishes",
+		"Order events waiting to be published.", nil, nil)
+)
+
+// kafkaCollector reads the producer statistics on every scrape
+type kafkaCollector struct{}
+
+func (kafkaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kafkaWritesDesc
+	ch <- kafkaMessagesDesc
+	ch <- kafkaBytesDesc
+	ch <- kafkaErrorsDesc
+	ch <- kafkaRetriesDesc
+	ch <- kafkaPendingDesc
+}
+
+func (kafkaCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := kafka.Snapshot()
+	ch <- prometheus.MustNewConstMetric(kafkaWritesDesc, prometheus.CounterValue, float64(stats.Writes))
+	ch <- prometheus.MustNewConstMetric(kafkaMessagesDesc, prometheus.CounterValue, float64(stats.Messages))
+	ch <- prometheus.MustNewConstMetric(kafkaBytesDesc, prometheus.CounterValue, float64(stats.Bytes))
+	ch <- prometheus.MustNewConstMetric(kafkaErrorsDesc, prometheus.CounterValue, float64(stats.Errors))
+	ch <- prometheus.MustNewConstMetric(kafkaRetriesDesc, prometheus.CounterValue, float64(stats.Retries))
+	ch <- prometheus.MustNewConstMetric(kafkaPendingDesc, prometheus.GaugeValue, float64(kafka.PendingPublishes()))
+}
@@ -2,25 +2,27 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
 )
 
-// AuthMiddleware verifies the JWT token in the request header
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware verifies the JWT token in the request header against the
+// given signing secret
+func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the JWT token from the Authorization header
+		// Get the JWT token from the Authorization header. Browsers cannot set
+		// headers on EventSource and WebSocket connections, so streams may
+		// pass the token in the access_token query parameter instead.
 		authHeader := c.GetHeader("Authorization")
+		if token := c.Query("access_token"); authHeader == "" && token != "" && isStream(c) {
+			authHeader = "Bearer " + token
+		}
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Authorization header is required",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Authorization header is required"))
 			c.Abort()
 			return
 		}
@@ -28,10 +30,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Check if the header has the correct format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Authorization header must be in the format 'Bearer {token}'",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Authorization header must be in the format 'Bearer {token}'"))
 			c.Abort()
 			return
 		}
@@ -45,14 +44,11 @@ func AuthMiddleware() gin.HandlerFunc {
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
 			// Return the secret key
-			return []byte(os.Getenv("JWT_SECRET")), nil
+			return []byte(jwtSecret), nil
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Invalid or expired token",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Invalid or expired token"))
 			c.Abort()
 			return
 		}
@@ -62,15 +58,41 @@ func AuthMiddleware() gin.HandlerFunc {
 			// Store the user ID in the context
 			c.Set("user_id", int(claims["user_id"].(float64)))
 			c.Set("username", claims["username"].(string))
-			c.Set("jwt_secret", os.Getenv("JWT_SECRET"))
+			// Tokens issued before roles existed belong to customers
+			role, _ := claims["role"].(string)
+			if role == "" {
+				role = models.RoleCustomer
+			}
+			c.Set("role", role)
+			// Staff tokens name the restaurant they work at
+			if restaurantID, ok := claims["restaurant_id"].(float64); ok {
+				c.Set("restaurant_id", int(restaurantID))
+			}
 			c.Next()
 		} else {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Invalid token claims",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Invalid token claims"))
+			c.Abort()
+			return
+		}
+	}
+}
+
+// RequireRole lets only users with the given role through. It must run after
+// AuthMiddleware.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			c.Error(apperrors.Forbidden(apperrors.CodeRoleRequired, "This endpoint requires the "+role+" role").
+				WithDetail("role", role))
 			c.Abort()
 			return
 		}
+		c.Next()
 	}
 }
+
+// isStream reports whether the request opens an event stream or a WebSocket
+func isStream(c *gin.Context) bool {
+	return strings.EqualFold(c.GetHeader("Upgrade"), "websocket") ||
+		strings.Contains(c.GetHeader("Accept"), "text/event-stream")
+}
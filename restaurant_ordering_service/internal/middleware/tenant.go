@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+// ResolveFunc looks up a restaurant by its ID or slug
+type ResolveFunc func(ctx context.Context, ref string) (models.Restaurant, error)
+
+// RestaurantFromPath makes the restaurant named by the :restaurant path
+// parameter the one the request is for
+func RestaurantFromPath(resolve ResolveFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setRestaurant(c, resolve, c.Param("restaurant"))
+	}
+}
+
+// RestaurantFromToken makes the restaurant the authenticated staff member
+// works at the one the request is for. It must run after AuthMiddleware.
+func RestaurantFromToken(resolve ResolveFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		restaurantID := c.GetInt("restaurant_id")
+		if restaurantID == 0 {
+			c.Error(apperrors.Forbidden(apperrors.CodeRestaurantRequired, "This endpoint is for the staff of a restaurant; log in again after being assigned to one"))
+			c.Abort()
+			return
+		}
+		setRestaurant(c, resolve, strconv.Itoa(restaurantID))
+	}
+}
+
+// DefaultRestaurant makes the restaurant named by ref the one requests are
+// for, on routes that predate restaurants
+func DefaultRestaurant(resolve ResolveFunc, ref string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setRestaurant(c, resolve, ref)
+	}
+}
+
+// setRestaurant looks up the restaurant named by ref and stores it in the
+// context for the handler
+func setRestaurant(c *gin.Context, resolve ResolveFunc, ref string) {
+	restaurant, err := resolve(c.Request.Context(), ref)
+	if err != nil {
+		c.Error(err)
+		c.Abort()
+		return
+	}
+	c.Set("restaurant", restaurant)
+	c.Next()
+}
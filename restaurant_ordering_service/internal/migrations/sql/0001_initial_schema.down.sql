@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TABLE IF EXISTS food_items;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,31 @@
+-- Baseline schema. IF NOT EXISTS lets databases created before versioned
+-- migrations adopt this version without changes.
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY,
+	username VARCHAR(50) UNIQUE NOT NULL,
+	password VARCHAR(100) NOT NULL,
+	email VARCHAR(100) UNIQUE NOT NULL,
+	address TEXT
+);
+
+CREATE TABLE IF NOT EXISTS food_items (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(100) NOT NULL,
+	price NUMERIC(10,2) NOT NULL,
+	quantity INT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS orders (
+	id SERIAL PRIMARY KEY,
+	user_id INT REFERENCES users(id),
+	total_price NUMERIC(10,2) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS order_items (
+	id SERIAL PRIMARY KEY,
+	order_id INT REFERENCES orders(id),
+	food_item_id INT REFERENCES food_items(id),
+	quantity INT NOT NULL
+);
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,5 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS done_at;
+ALTER TABLE order_items DROP COLUMN IF EXISTS started_at;
+DROP TABLE IF EXISTS kitchen_tickets;
+ALTER TABLE food_items DROP COLUMN IF EXISTS station;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
@@ -0,0 +1,20 @@
+-- Users are customers unless they staff the kitchen
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'customer';
+
+-- The kitchen station that prepares each food item
+ALTER TABLE food_items ADD COLUMN station VARCHAR(50) NOT NULL DEFAULT 'main';
+
+-- A ticket is opened on the kitchen display when an order is paid, and
+-- leaves the queue when it is bumped
+CREATE TABLE kitchen_tickets (
+	order_id INT PRIMARY KEY REFERENCES orders(id),
+	priority INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	bumped_at TIMESTAMP
+);
+
+CREATE INDEX idx_kitchen_tickets_active ON kitchen_tickets (priority DESC, created_at) WHERE bumped_at IS NULL;
+
+-- When the kitchen started and finished preparing each item
+ALTER TABLE order_items ADD COLUMN started_at TIMESTAMP;
+ALTER TABLE order_items ADD COLUMN done_at TIMESTAMP;
@@ -0,0 +1,8 @@
+ALTER TABLE users DROP COLUMN IF EXISTS restaurant_id;
+DROP INDEX IF EXISTS idx_orders_restaurant_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders DROP COLUMN IF EXISTS tax;
+ALTER TABLE orders DROP COLUMN IF EXISTS restaurant_id;
+DROP INDEX IF EXISTS idx_food_items_restaurant_id;
+ALTER TABLE food_items DROP COLUMN IF EXISTS restaurant_id;
+DROP TABLE IF EXISTS restaurants;
@@ -0,0 +1,30 @@
+-- Each restaurant has its own menu, orders and staff. Opening hours are in
+-- the restaurant's timezone; equal times mean open all day.
+CREATE TABLE restaurants (
+	id SERIAL PRIMARY KEY,
+	slug VARCHAR(50) UNIQUE NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+	opens_at TIME NOT NULL DEFAULT '00:00',
+	closes_at TIME NOT NULL DEFAULT '00:00',
+	currency CHAR(3) NOT NULL DEFAULT 'INR',
+	tax_rate NUMERIC(5,4) NOT NULL DEFAULT 0
+);
+
+-- Everything that existed before belongs to the default restaurant
+INSERT INTO restaurants (id, slug, name, timezone) VALUES (1, 'default', 'Default Restaurant', 'Asia/Kolkata');
+SELECT setval(pg_get_serial_sequence('restaurants', 'id'), 1);
+
+ALTER TABLE food_items ADD COLUMN restaurant_id INT NOT NULL DEFAULT 1 REFERENCES restaurants(id);
+ALTER TABLE food_items ALTER COLUMN restaurant_id DROP DEFAULT;
+CREATE INDEX idx_food_items_restaurant_id ON food_items (restaurant_id);
+
+ALTER TABLE orders ADD COLUMN restaurant_id INT NOT NULL DEFAULT 1 REFERENCES restaurants(id);
+ALTER TABLE orders ALTER COLUMN restaurant_id DROP DEFAULT;
+ALTER TABLE orders ADD COLUMN tax NUMERIC(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'INR';
+CREATE INDEX idx_orders_restaurant_id ON orders (restaurant_id);
+
+-- Staff work at a single restaurant; customers order from any
+ALTER TABLE users ADD COLUMN restaurant_id INT REFERENCES restaurants(id);
+UPDATE users SET restaurant_id = 1 WHERE role <> 'customer';
@@ -0,0 +1,19 @@
+ALTER TABLE restaurants DROP COLUMN pause_reason;
+ALTER TABLE restaurants DROP COLUMN paused_until;
+ALTER TABLE restaurants DROP COLUMN paused_at;
+ALTER TABLE restaurants DROP COLUMN last_order_minutes;
+
+DROP TABLE restaurant_hours_exceptions;
+
+-- Keep the earliest window of each restaurant as its daily hours
+ALTER TABLE restaurants ADD COLUMN opens_at TIME NOT NULL DEFAULT '00:00';
+ALTER TABLE restaurants ADD COLUMN closes_at TIME NOT NULL DEFAULT '00:00';
+UPDATE restaurants r SET opens_at = h.opens_at, closes_at = h.closes_at
+FROM (
+	SELECT DISTINCT ON (restaurant_id) restaurant_id, opens_at, closes_at
+	FROM restaurant_hours
+	ORDER BY restaurant_id, weekday, opens_at
+) h
+WHERE h.restaurant_id = r.id;
+
+DROP TABLE restaurant_hours;
@@ -0,0 +1,38 @@
+-- Weekly opening hours replace the single daily window, in the restaurant's
+-- timezone. A window that closes at or before it opens runs past midnight.
+-- A restaurant without any is open all week.
+CREATE TABLE restaurant_hours (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+	opens_at TIME NOT NULL,
+	closes_at TIME NOT NULL
+);
+CREATE INDEX idx_restaurant_hours_restaurant_id ON restaurant_hours (restaurant_id);
+
+INSERT INTO restaurant_hours (restaurant_id, weekday, opens_at, closes_at)
+SELECT r.id, d.weekday, r.opens_at, r.closes_at
+FROM restaurants r CROSS JOIN generate_series(0, 6) AS d(weekday)
+WHERE r.opens_at <> r.closes_at;
+
+ALTER TABLE restaurants DROP COLUMN opens_at;
+ALTER TABLE restaurants DROP COLUMN closes_at;
+
+-- Dates on which the weekly hours do not apply, such as holidays
+CREATE TABLE restaurant_hours_exceptions (
+	restaurant_id INT NOT NULL REFERENCES restaurants(id) ON DELETE CASCADE,
+	date DATE NOT NULL,
+	closed BOOLEAN NOT NULL,
+	opens_at TIME,
+	closes_at TIME,
+	note VARCHAR(200) NOT NULL DEFAULT '',
+	PRIMARY KEY (restaurant_id, date),
+	CHECK (closed OR (opens_at IS NOT NULL AND closes_at IS NOT NULL))
+);
+
+-- Orders stop last_order_minutes before closing, and staff can pause
+-- ordering until a given time or until they resume it
+ALTER TABLE restaurants ADD COLUMN last_order_minutes INT NOT NULL DEFAULT 0;
+ALTER TABLE restaurants ADD COLUMN paused_at TIMESTAMPTZ;
+ALTER TABLE restaurants ADD COLUMN paused_until TIMESTAMPTZ;
+ALTER TABLE restaurants ADD COLUMN pause_reason VARCHAR(200) NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS order_item_modifiers;
+ALTER TABLE order_items DROP COLUMN IF EXISTS unit_price;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_name;
+ALTER TABLE order_items DROP COLUMN IF EXISTS variant_id;
+DROP TABLE IF EXISTS modifiers;
+DROP TABLE IF EXISTS modifier_groups;
+DROP TABLE IF EXISTS food_item_variants;
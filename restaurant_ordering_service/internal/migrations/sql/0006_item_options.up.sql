@@ -0,0 +1,47 @@
+-- Variants are versions of a food item, such as sizes, with their own price
+-- and stock. An item with variants is always ordered as one of them.
+CREATE TABLE food_item_variants (
+	id SERIAL PRIMARY KEY,
+	food_item_id INT NOT NULL REFERENCES food_items(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	price NUMERIC(10,2) NOT NULL,
+	quantity INT NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_food_item_variants_food_item_id ON food_item_variants (food_item_id);
+
+-- Modifier groups offer options on a food item, such as toppings or the
+-- spice level. Between min_selections and max_selections of their modifiers
+-- are picked, so a group with a minimum is required.
+CREATE TABLE modifier_groups (
+	id SERIAL PRIMARY KEY,
+	food_item_id INT NOT NULL REFERENCES food_items(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	min_selections INT NOT NULL DEFAULT 0,
+	max_selections INT NOT NULL DEFAULT 1,
+	CHECK (min_selections >= 0 AND max_selections >= 1 AND max_selections >= min_selections)
+);
+CREATE INDEX idx_modifier_groups_food_item_id ON modifier_groups (food_item_id);
+
+CREATE TABLE modifiers (
+	id SERIAL PRIMARY KEY,
+	group_id INT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	price_delta NUMERIC(10,2) NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_modifiers_group_id ON modifiers (group_id);
+
+-- Order items record the variant and price they were ordered at. Items
+-- ordered before variants existed were at the price of the food item.
+ALTER TABLE order_items ADD COLUMN variant_id INT REFERENCES food_item_variants(id);
+ALTER TABLE order_items ADD COLUMN variant_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE order_items ADD COLUMN unit_price NUMERIC(10,2) NOT NULL DEFAULT 0;
+UPDATE order_items i SET unit_price = f.price FROM food_items f WHERE f.id = i.food_item_id;
+
+-- The modifiers picked for an order item, as named and priced when ordered
+CREATE TABLE order_item_modifiers (
+	order_item_id INT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+	modifier_id INT NOT NULL REFERENCES modifiers(id),
+	name VARCHAR(100) NOT NULL,
+	price_delta NUMERIC(10,2) NOT NULL,
+	PRIMARY KEY (order_item_id, modifier_id)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS order_bundle_id;
+DROP TABLE IF EXISTS order_bundles;
+DROP TABLE IF EXISTS bundle_slot_options;
+DROP TABLE IF EXISTS bundle_slots;
+DROP TABLE IF EXISTS bundles;
@@ -0,0 +1,41 @@
+-- Bundles sell several food items at one price. Each slot is filled with
+-- one of its options, a food item or a variant of one, so a slot with a
+-- single option is a fixed component.
+CREATE TABLE bundles (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id),
+	name VARCHAR(100) NOT NULL,
+	price NUMERIC(10,2) NOT NULL
+);
+CREATE INDEX idx_bundles_restaurant_id ON bundles (restaurant_id);
+
+CREATE TABLE bundle_slots (
+	id SERIAL PRIMARY KEY,
+	bundle_id INT NOT NULL REFERENCES bundles(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	quantity INT NOT NULL DEFAULT 1 CHECK (quantity >= 1)
+);
+CREATE INDEX idx_bundle_slots_bundle_id ON bundle_slots (bundle_id);
+
+CREATE TABLE bundle_slot_options (
+	id SERIAL PRIMARY KEY,
+	slot_id INT NOT NULL REFERENCES bundle_slots(id) ON DELETE CASCADE,
+	food_item_id INT NOT NULL REFERENCES food_items(id) ON DELETE CASCADE,
+	variant_id INT REFERENCES food_item_variants(id) ON DELETE CASCADE,
+	price_delta NUMERIC(10,2) NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_bundle_slot_options_slot_id ON bundle_slot_options (slot_id);
+
+-- The bundles of an order, as named and priced when ordered. Their items
+-- are order items that point back at them.
+CREATE TABLE order_bundles (
+	id SERIAL PRIMARY KEY,
+	order_id INT NOT NULL REFERENCES orders(id),
+	bundle_id INT NOT NULL REFERENCES bundles(id),
+	name VARCHAR(100) NOT NULL,
+	quantity INT NOT NULL,
+	unit_price NUMERIC(10,2) NOT NULL
+);
+CREATE INDEX idx_order_bundles_order_id ON order_bundles (order_id);
+
+ALTER TABLE order_items ADD COLUMN order_bundle_id INT REFERENCES order_bundles(id);
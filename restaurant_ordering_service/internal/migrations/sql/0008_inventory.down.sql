@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS stock_movements;
+DROP TABLE IF EXISTS modifier_ingredients;
+DROP TABLE IF EXISTS food_item_ingredients;
+DROP TABLE IF EXISTS ingredients;
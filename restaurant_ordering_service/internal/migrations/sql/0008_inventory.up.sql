@@ -0,0 +1,41 @@
+-- Ingredients are the stock a restaurant cooks with, counted in their unit.
+-- An ingredient at or below its threshold is low on stock.
+CREATE TABLE ingredients (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id),
+	name VARCHAR(100) NOT NULL,
+	unit VARCHAR(20) NOT NULL,
+	quantity NUMERIC(12,3) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+	low_stock_threshold NUMERIC(12,3) NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+	UNIQUE (restaurant_id, name)
+);
+
+-- Recipes give the ingredients used by one portion of a food item, or by a
+-- modifier picked for one
+CREATE TABLE food_item_ingredients (
+	food_item_id INT NOT NULL REFERENCES food_items(id) ON DELETE CASCADE,
+	ingredient_id INT NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+	quantity NUMERIC(12,3) NOT NULL CHECK (quantity > 0),
+	PRIMARY KEY (food_item_id, ingredient_id)
+);
+
+CREATE TABLE modifier_ingredients (
+	modifier_id INT NOT NULL REFERENCES modifiers(id) ON DELETE CASCADE,
+	ingredient_id INT NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+	quantity NUMERIC(12,3) NOT NULL CHECK (quantity > 0),
+	PRIMARY KEY (modifier_id, ingredient_id)
+);
+
+-- Every change to the stock of an ingredient, as an audit trail
+CREATE TABLE stock_movements (
+	id SERIAL PRIMARY KEY,
+	ingredient_id INT NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+	change NUMERIC(12,3) NOT NULL,
+	quantity_after NUMERIC(12,3) NOT NULL,
+	reason VARCHAR(20) NOT NULL,
+	order_id INT REFERENCES orders(id),
+	user_id INT REFERENCES users(id),
+	note VARCHAR(200) NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_stock_movements_ingredient_id ON stock_movements (ingredient_id, id);
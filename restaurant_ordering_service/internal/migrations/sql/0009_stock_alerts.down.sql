@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_alerts;
+ALTER TABLE food_items DROP COLUMN IF EXISTS hide_when_sold_out;
+ALTER TABLE food_items DROP COLUMN IF EXISTS low_stock_threshold;
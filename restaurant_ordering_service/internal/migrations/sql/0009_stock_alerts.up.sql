@@ -0,0 +1,26 @@
+-- Food items warn staff once their stock falls to the threshold, and leave
+-- the menu while sold out unless staff keep showing them
+ALTER TABLE food_items
+	ADD COLUMN low_stock_threshold INT NOT NULL DEFAULT 0 CHECK (low_stock_threshold >= 0),
+	ADD COLUMN hide_when_sold_out BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Stock alerts tell the staff of a restaurant about ingredients and food
+-- items running low or selling out, and food items back in stock. An alert
+-- stays open until acknowledged, or until the stock recovers.
+CREATE TABLE stock_alerts (
+	id SERIAL PRIMARY KEY,
+	restaurant_id INT NOT NULL REFERENCES restaurants(id),
+	type VARCHAR(40) NOT NULL,
+	ingredient_id INT REFERENCES ingredients(id) ON DELETE CASCADE,
+	food_item_id INT REFERENCES food_items(id) ON DELETE CASCADE,
+	variant_id INT REFERENCES food_item_variants(id) ON DELETE CASCADE,
+	name VARCHAR(100) NOT NULL,
+	variant VARCHAR(100) NOT NULL DEFAULT '',
+	unit VARCHAR(20) NOT NULL DEFAULT '',
+	quantity NUMERIC(12,3) NOT NULL,
+	low_stock_threshold NUMERIC(12,3) NOT NULL,
+	created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	acknowledged_at TIMESTAMP,
+	acknowledged_by INT REFERENCES users(id)
+);
+CREATE INDEX idx_stock_alerts_open ON stock_alerts (restaurant_id, id) WHERE acknowledged_at IS NULL;
@@ -1,5 +1,12 @@
 package models
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/shared/response"
+)
+
 // User represents a user in the system
 type User struct {
 	ID       int    `json:"id"`
@@ -7,37 +14,250 @@ type User struct {
 	Password string `json:"password,omitempty"`
 	Email    string `json:"email"`
 	Address  string `json:"address"`
+	Role     string `json:"role"` // customer or kitchen
+	// RestaurantID is the restaurant a staff member works at, zero for
+	// customers
+	RestaurantID int `json:"restaurant_id,omitempty"`
+}
+
+// Roles a user can have
+const (
+	RoleCustomer = "customer"
+	RoleKitchen  = "kitchen"
+)
+
+// Restaurant is a restaurant with its own menu, orders and staff
+type Restaurant struct {
+	ID               int        `json:"id"`
+	Slug             string     `json:"slug"` // Identifies the restaurant in URLs
+	Name             string     `json:"name"`
+	Timezone         string     `json:"timezone"`               // IANA name, such as Asia/Kolkata
+	Currency         string     `json:"currency"`               // ISO 4217 code of its prices
+	TaxRate          float64    `json:"tax_rate"`               // Fraction of the subtotal added as tax, such as 0.05
+	LastOrderMinutes int        `json:"last_order_minutes"`     // Orders stop this long before closing
+	PausedAt         *time.Time `json:"paused_at,omitempty"`    // Set while staff have paused ordering
+	PausedUntil      *time.Time `json:"paused_until,omitempty"` // End of the pause, unset until resumed
+	PauseReason      string     `json:"pause_reason,omitempty"`
+}
+
+// OpeningHours is a window in which a restaurant is open on a day of the
+// week
+type OpeningHours struct {
+	Weekday  int    `json:"weekday" binding:"min=0,max=6"` // 0 is Sunday
+	OpensAt  string `json:"opens_at" binding:"required"`   // HH:MM in the restaurant's timezone
+	ClosesAt string `json:"closes_at" binding:"required"`  // HH:MM, at or before OpensAt when open past midnight
+}
+
+// HoursException replaces the weekly hours of a restaurant on a date, such
+// as a holiday
+type HoursException struct {
+	Date     string `json:"date"` // YYYY-MM-DD in the restaurant's timezone
+	Closed   bool   `json:"closed"`
+	OpensAt  string `json:"opens_at,omitempty"` // Hours on the date when it is not closed
+	ClosesAt string `json:"closes_at,omitempty"`
+	Note     string `json:"note,omitempty"` // Such as the name of the holiday
+}
+
+// RestaurantHours is the schedule of a restaurant
+type RestaurantHours struct {
+	Timezone         string           `json:"timezone"`
+	LastOrderMinutes int              `json:"last_order_minutes"`
+	Weekly           []OpeningHours   `json:"weekly"`     // Open all week when empty
+	Exceptions       []HoursException `json:"exceptions"` // From today on
+}
+
+// HoursRequest replaces the weekly hours and last-order cutoff of a
+// restaurant
+type HoursRequest struct {
+	LastOrderMinutes int            `json:"last_order_minutes" binding:"min=0,max=240"`
+	Weekly           []OpeningHours `json:"weekly" binding:"dive"`
 }
 
+// HoursExceptionRequest sets the hours of a restaurant on a date
+type HoursExceptionRequest struct {
+	Closed   bool   `json:"closed"`
+	OpensAt  string `json:"opens_at"` // Required unless closed
+	ClosesAt string `json:"closes_at"`
+	Note     string `json:"note" binding:"max=200"`
+}
+
+// PauseRequest pauses ordering at a restaurant
+type PauseRequest struct {
+	Minutes int    `json:"minutes" binding:"min=0,max=1440"` // Zero pauses until ordering is resumed
+	Reason  string `json:"reason" binding:"max=200"`
+}
+
+// Availability tells whether a restaurant takes orders
+type Availability struct {
+	Open            bool       `json:"open"` // Within its opening hours
+	AcceptingOrders bool       `json:"accepting_orders"`
+	Reason          string     `json:"reason,omitempty"` // Why orders are not accepted
+	Note            string     `json:"note,omitempty"`   // Note of today's exception, such as the holiday
+	ClosesAt        *time.Time `json:"closes_at,omitempty"`
+	LastOrderAt     *time.Time `json:"last_order_at,omitempty"`
+	NextOpensAt     *time.Time `json:"next_opens_at,omitempty"`
+	PausedUntil     *time.Time `json:"paused_until,omitempty"`
+}
+
+// Reasons why a restaurant or a food item cannot be ordered
+const (
+	UnavailableClosed     = "closed"
+	UnavailableHoliday    = "holiday"
+	UnavailableLastOrders = "last_orders_passed"
+	UnavailablePaused     = "paused"
+	UnavailableSoldOut    = "sold_out"
+)
+
 // FoodItem represents a food item in the menu
 type FoodItem struct {
-	ID       int     `json:"id"`
-	Name     string  `json:"name"`
-	Price    float64 `json:"price"`    // Always 10 INR as per requirements
-	Quantity int     `json:"quantity"` // Starting with 1000 as per requirements
+	ID           int     `json:"id"`
+	RestaurantID int     `json:"restaurant_id"`
+	Name         string  `json:"name"`
+	Price        float64 `json:"price"`    // Always 10 INR as per requirements
+	Quantity     int     `json:"quantity"` // Starting with 1000 as per requirements
+	Station      string  `json:"station"`  // Kitchen station that prepares it
+
+	// Staff are alerted once the stock left falls to the threshold, and the
+	// item leaves the menu while sold out if HideWhenSoldOut is set
+	LowStockThreshold int  `json:"low_stock_threshold"`
+	HideWhenSoldOut   bool `json:"hide_when_sold_out"`
+
+	// Variants replace the price and stock of the item; one must be picked
+	Variants       []Variant       `json:"variants,omitempty"`
+	ModifierGroups []ModifierGroup `json:"modifier_groups,omitempty"`
+
+	// Set on menu listings: whether the item can be ordered now, and if
+	// not why
+	Available         bool   `json:"available"`
+	UnavailableReason string `json:"unavailable_reason,omitempty"`
+}
+
+// Variant is a version of a food item, such as a size, with its own price
+// and stock
+type Variant struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Quantity  int     `json:"quantity"`
+	Available bool    `json:"available"` // Set on menu listings
+}
+
+// ModifierGroup is a set of options on a food item, such as toppings or the
+// spice level. A group with a minimum of one or more is required.
+type ModifierGroup struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	MinSelections int        `json:"min_selections"`
+	MaxSelections int        `json:"max_selections"`
+	Modifiers     []Modifier `json:"modifiers"`
+}
+
+// Modifier is an option of a modifier group, such as extra cheese
+type Modifier struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"` // Added to the price of the item, negative for a discount
+}
+
+// Bundle is a combo of food items sold at one price, such as a thali. Each
+// slot is filled with one of its options, so a slot with a single option is
+// a fixed component.
+type Bundle struct {
+	ID           int          `json:"id"`
+	RestaurantID int          `json:"restaurant_id"`
+	Name         string       `json:"name"`
+	Price        float64      `json:"price"`
+	Slots        []BundleSlot `json:"slots"`
+
+	// Set on bundle listings, as for food items
+	Available         bool   `json:"available"`
+	UnavailableReason string `json:"unavailable_reason,omitempty"`
+}
+
+// BundleSlot is a component of a bundle
+type BundleSlot struct {
+	ID       int            `json:"id"`
+	Name     string         `json:"name"`     // Such as Main or Dessert
+	Quantity int            `json:"quantity"` // Of the option picked, in each bundle
+	Options  []BundleOption `json:"options"`
+}
+
+// BundleOption is a food item, or a variant of one, that can fill a slot
+type BundleOption struct {
+	ID         int     `json:"id"`
+	FoodItemID int     `json:"food_item_id"`
+	VariantID  int     `json:"variant_id,omitempty"`
+	Name       string  `json:"name"`        // Of the food item, with the variant in parentheses
+	PriceDelta float64 `json:"price_delta"` // Added to the price of the bundle when picked
+	Available  bool    `json:"available"`   // Set on bundle listings
 }
 
 // Order represents a user's order
 type Order struct {
-	ID         int         `json:"id"`
-	UserID     int         `json:"user_id"`
-	OrderItems []OrderItem `json:"order_items"`
-	TotalPrice float64     `json:"total_price"`
-	Status     string      `json:"status"` // pending, completed, cancelled
+	ID           int           `json:"id"`
+	RestaurantID int           `json:"restaurant_id"`
+	UserID       int           `json:"user_id"`
+	OrderItems   []OrderItem   `json:"order_items"`
+	Bundles      []OrderBundle `json:"bundles,omitempty"`
+	Tax          float64       `json:"tax"`
+	TotalPrice   float64       `json:"total_price"` // Including tax
+	Currency     string        `json:"currency"`
+	Status       string        `json:"status"` // pending, completed, cancelled
+}
+
+// AllItems returns the items of the order followed by the items of its
+// bundles
+func (o Order) AllItems() []OrderItem {
+	items := append([]OrderItem(nil), o.OrderItems...)
+	for _, bundle := range o.Bundles {
+		items = append(items, bundle.Items...)
+	}
+	return items
+}
+
+// OrderBundle is a bundle in an order with the items it is made of
+type OrderBundle struct {
+	ID        int         `json:"id"`
+	OrderID   int         `json:"order_id"`
+	BundleID  int         `json:"bundle_id"`
+	Name      string      `json:"name"` // As when ordered
+	Quantity  int         `json:"quantity"`
+	UnitPrice float64     `json:"unit_price"`      // Including the price deltas of the options picked
+	Items     []OrderItem `json:"items,omitempty"` // Priced at zero, as the bundle is
+}
+
+// StatusEventID returns the ID of the event published when the order moved
+// to its current status
+func (o Order) StatusEventID() string {
+	return fmt.Sprintf("order-%d-%s", o.ID, o.Status)
 }
 
 // OrderItem represents an item in an order
 type OrderItem struct {
-	ID         int `json:"id"`
-	OrderID    int `json:"order_id"`
-	FoodItemID int `json:"food_item_id"`
-	Quantity   int `json:"quantity"`
+	ID         int                 `json:"id"`
+	OrderID    int                 `json:"order_id"`
+	FoodItemID int                 `json:"food_item_id"`
+	VariantID  int                 `json:"variant_id,omitempty"`
+	Variant    string              `json:"variant,omitempty"` // Name of the variant when ordered
+	Quantity   int                 `json:"quantity"`
+	UnitPrice  float64             `json:"unit_price"` // Including the variant and modifiers
+	Modifiers  []OrderItemModifier `json:"modifiers,omitempty"`
+	// OrderBundleID is the bundle of the order the item is part of
+	OrderBundleID int `json:"order_bundle_id,omitempty"`
+}
+
+// OrderItemModifier is a modifier picked for an order item, as named and
+// priced when ordered
+type OrderItemModifier struct {
+	ModifierID int     `json:"modifier_id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
 }
 
 // LoginRequest represents login credentials
 type LoginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
 // LoginResponse represents login response with token
@@ -45,42 +265,290 @@ type LoginResponse struct {
 	Token string `json:"token"`
 }
 
-// OrderRequest represents a request to place an order
+// OrderRequest represents a request to place an order of at least one item
+// or bundle
 type OrderRequest struct {
-	Items []OrderItemRequest `json:"items"`
+	Items   []OrderItemRequest   `json:"items" binding:"dive"`
+	Bundles []OrderBundleRequest `json:"bundles" binding:"dive"`
 }
 
 // OrderItemRequest represents an item in an order request
 type OrderItemRequest struct {
-	FoodItemID int `json:"food_item_id"`
-	Quantity   int `json:"quantity"`
+	FoodItemID  int   `json:"food_item_id" binding:"required,min=1"`
+	Quantity    int   `json:"quantity" binding:"required,min=1"`
+	VariantID   int   `json:"variant_id" binding:"omitempty,min=1"` // Required when the food item has variants
+	ModifierIDs []int `json:"modifier_ids" binding:"max=20,dive,min=1"`
+}
+
+// OrderBundleRequest represents a bundle in an order request
+type OrderBundleRequest struct {
+	BundleID int            `json:"bundle_id" binding:"required,min=1"`
+	Quantity int            `json:"quantity" binding:"required,min=1"`
+	Choices  []BundleChoice `json:"choices" binding:"dive"` // For each slot with several options
+}
+
+// BundleChoice picks the option that fills a slot of a bundle
+type BundleChoice struct {
+	SlotID   int `json:"slot_id" binding:"required,min=1"`
+	OptionID int `json:"option_id" binding:"required,min=1"`
+}
+
+// OrderPlacedResponse represents the response to a placed order
+type OrderPlacedResponse struct {
+	OrderID    int     `json:"order_id"`
+	TotalPrice float64 `json:"total_price"`
 }
 
 // TransactionRequest represents a request to process a transaction
 type TransactionRequest struct {
-	OrderID int `json:"order_id"`
+	OrderID int `json:"order_id" binding:"required,min=1"`
 }
 
-// APIResponse represents a generic API response
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-}
+// The response envelope is shared by the services
+type (
+	APIResponse = response.APIResponse
+	ErrorDetail = response.ErrorDetail
+	FieldError  = response.FieldError
+)
 
 // OrderEvent represents an order event that will be sent to Kafka
 type OrderEvent struct {
-	OrderID    int     `json:"order_id"`
-	UserID     int     `json:"user_id"`
-	TotalPrice float64 `json:"total_price"`
-	Status     string  `json:"status"`
-	Items      []Item  `json:"items"`
-	Timestamp  int64   `json:"timestamp"`
+	EventID      string        `json:"event_id"` // Stable per order status change, used by consumers for deduplication
+	OrderID      int           `json:"order_id"`
+	RestaurantID int           `json:"restaurant_id"`
+	UserID       int           `json:"user_id"`
+	TotalPrice   float64       `json:"total_price"`
+	Status       string        `json:"status"`
+	Items        []Item        `json:"items"`             // Including the items of the bundles
+	Bundles      []OrderBundle `json:"bundles,omitempty"` // Without their items, which are in Items
+	Timestamp    int64         `json:"timestamp"`
+}
+
+// OrderStatusEvent is a change of an order's status, pushed to the owner of
+// the order over SSE or WebSocket
+type OrderStatusEvent struct {
+	EventID   string `json:"event_id"` // Same as the ID of the order event, used to resume streams
+	OrderID   int    `json:"order_id"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// StreamMessage is a message sent over the order status WebSocket
+type StreamMessage struct {
+	Type  string            `json:"type"` // status or heartbeat
+	Event *OrderStatusEvent `json:"event,omitempty"`
 }
 
 // Item represents an item in an order event
 type Item struct {
-	FoodItemID int    `json:"food_item_id"`
-	Name       string `json:"name"`
-	Quantity   int    `json:"quantity"`
+	FoodItemID int                 `json:"food_item_id"`
+	Name       string              `json:"name"`
+	VariantID  int                 `json:"variant_id,omitempty"`
+	Variant    string              `json:"variant,omitempty"`
+	Quantity   int                 `json:"quantity"`
+	UnitPrice  float64             `json:"unit_price"`
+	Modifiers  []OrderItemModifier `json:"modifiers,omitempty"`
+	// OrderBundleID is the ID of the bundle in Bundles the item is part of
+	OrderBundleID int `json:"order_bundle_id,omitempty"`
+}
+
+// KitchenTicket is a paid order as shown on the kitchen display
+type KitchenTicket struct {
+	OrderID      int           `json:"order_id"`
+	RestaurantID int           `json:"restaurant_id"`
+	Priority     int           `json:"priority"`   // Higher priorities are prepared first
+	CreatedAt    time.Time     `json:"created_at"` // When the order was paid
+	BumpedAt     *time.Time    `json:"bumped_at,omitempty"`
+	Items        []KitchenItem `json:"items"`
+}
+
+// KitchenItem is an order item as prepared by the kitchen
+type KitchenItem struct {
+	ID         int        `json:"id"` // ID of the order item
+	FoodItemID int        `json:"food_item_id"`
+	Name       string     `json:"name"`
+	Variant    string     `json:"variant,omitempty"`
+	Modifiers  []string   `json:"modifiers,omitempty"` // Names of the modifiers picked
+	Station    string     `json:"station"`
+	Quantity   int        `json:"quantity"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	DoneAt     *time.Time `json:"done_at,omitempty"`
+}
+
+// KitchenStation lists the active tickets of a station, with only the items
+// the station prepares
+type KitchenStation struct {
+	Station string          `json:"station"`
+	Tickets []KitchenTicket `json:"tickets"`
+}
+
+// PrepTime is the average time the kitchen takes to prepare a food item
+type PrepTime struct {
+	FoodItemID     int     `json:"food_item_id"`
+	Name           string  `json:"name"`
+	Station        string  `json:"station"`
+	Prepared       int     `json:"prepared"` // Number of order items the average is over
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+// TicketPriorityRequest represents a request to change a ticket's priority
+type TicketPriorityRequest struct {
+	Priority int `json:"priority" binding:"min=0,max=9"`
+}
+
+// Ingredient is stock a restaurant cooks with, counted in its unit
+type Ingredient struct {
+	ID                int     `json:"id"`
+	RestaurantID      int     `json:"restaurant_id"`
+	Name              string  `json:"name"`
+	Unit              string  `json:"unit"` // Such as g, ml or piece
+	Quantity          float64 `json:"quantity"`
+	LowStockThreshold float64 `json:"low_stock_threshold"`
+	LowStock          bool    `json:"low_stock"` // Quantity is at or below the threshold
+}
+
+// Reasons for a change to the stock of an ingredient
+const (
+	StockReasonOrder      = "order" // Used by a paid order
+	StockReasonRestock    = "restock"
+	StockReasonWaste      = "waste"
+	StockReasonCorrection = "correction" // After a stock count
+)
+
+// StockMovement is a change to the stock of an ingredient
+type StockMovement struct {
+	ID            int       `json:"id"`
+	IngredientID  int       `json:"ingredient_id"`
+	Change        float64   `json:"change"` // Negative when stock was taken out
+	QuantityAfter float64   `json:"quantity_after"`
+	Reason        string    `json:"reason"`
+	OrderID       int       `json:"order_id,omitempty"` // For reason order
+	UserID        int       `json:"user_id,omitempty"`  // Staff member or customer who caused it
+	Note          string    `json:"note,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Recipe gives the ingredients used by one portion of a food item, or by a
+// modifier picked for one
+type Recipe struct {
+	FoodItemID  int                `json:"food_item_id,omitempty"`
+	ModifierID  int                `json:"modifier_id,omitempty"`
+	Ingredients []RecipeIngredient `json:"ingredients"`
+}
+
+// RecipeIngredient is the quantity of an ingredient a recipe uses, in the
+// unit of the ingredient
+type RecipeIngredient struct {
+	IngredientID int     `json:"ingredient_id" binding:"required,min=1"`
+	Name         string  `json:"name"` // Set in responses
+	Unit         string  `json:"unit"` // Set in responses
+	Quantity     float64 `json:"quantity" binding:"gt=0"`
+}
+
+// IngredientRequest represents a request to add an ingredient
+type IngredientRequest struct {
+	Name              string  `json:"name" binding:"required,max=100"`
+	Unit              string  `json:"unit" binding:"required,max=20"`
+	Quantity          float64 `json:"quantity" binding:"min=0"`
+	LowStockThreshold float64 `json:"low_stock_threshold" binding:"min=0"`
+}
+
+// ThresholdRequest represents a request to change the low-stock threshold
+// of an ingredient
+type ThresholdRequest struct {
+	LowStockThreshold *float64 `json:"low_stock_threshold" binding:"required,min=0"`
+}
+
+// StockAdjustmentRequest represents a change to the stock of an ingredient
+// made by staff
+type StockAdjustmentRequest struct {
+	Change float64 `json:"change" binding:"required"` // Negative to take stock out
+	Reason string  `json:"reason" binding:"required,oneof=restock waste correction"`
+	Note   string  `json:"note" binding:"max=200"`
+}
+
+// RecipeRequest represents a request to replace a recipe. An empty list of
+// ingredients removes the recipe.
+type RecipeRequest struct {
+	Ingredients []RecipeIngredient `json:"ingredients" binding:"max=50,dive"`
+}
+
+// Types of inventory events and stock alerts
+const (
+	InventoryLowStock = "inventory.low_stock" // An ingredient fell to its threshold
+	MenuItemLowStock  = "menu.item_low_stock" // A food item or variant fell to its threshold
+	MenuItemSoldOut   = "menu.item_sold_out"  // A food item or variant can no longer be ordered
+	MenuItemRestored  = "menu.item_restored"  // A sold out food item or variant is back in stock
+)
+
+// InventoryEvent is published to Kafka when the stock of an ingredient or a
+// food item changes in a way other systems care about. It is the stock alert
+// raised for the change.
+type InventoryEvent struct {
+	EventID           string  `json:"event_id"` // Unique per event, used by consumers for deduplication
+	Type              string  `json:"type"`
+	AlertID           int     `json:"alert_id"`
+	RestaurantID      int     `json:"restaurant_id"`
+	IngredientID      int     `json:"ingredient_id,omitempty"` // For inventory events
+	FoodItemID        int     `json:"food_item_id,omitempty"`  // For menu events
+	VariantID         int     `json:"variant_id,omitempty"`    // For menu events about a variant
+	Name              string  `json:"name"`
+	Variant           string  `json:"variant,omitempty"`
+	Unit              string  `json:"unit,omitempty"`
+	Quantity          float64 `json:"quantity"` // Of the ingredient, or portions of the food item left
+	LowStockThreshold float64 `json:"low_stock_threshold"`
+	Timestamp         int64   `json:"timestamp"`
+}
+
+// StockAlert tells the staff of a restaurant that an ingredient or a food
+// item is running low or sold out, or that a food item is back in stock. It
+// is open until acknowledged by staff or resolved by a restock.
+type StockAlert struct {
+	ID                int        `json:"id"`
+	RestaurantID      int        `json:"restaurant_id"`
+	Type              string     `json:"type"`
+	IngredientID      int        `json:"ingredient_id,omitempty"`
+	FoodItemID        int        `json:"food_item_id,omitempty"`
+	VariantID         int        `json:"variant_id,omitempty"`
+	Name              string     `json:"name"`
+	Variant           string     `json:"variant,omitempty"`
+	Unit              string     `json:"unit,omitempty"`
+	Quantity          float64    `json:"quantity"`
+	LowStockThreshold float64    `json:"low_stock_threshold"`
+	CreatedAt         time.Time  `json:"created_at"`
+	AcknowledgedAt    *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy    int        `json:"acknowledged_by,omitempty"` // Unset when resolved by a restock
+}
+
+// Event returns the inventory event announcing the alert
+func (a StockAlert) Event() InventoryEvent {
+	return InventoryEvent{
+		EventID:           fmt.Sprintf("stock-alert-%d", a.ID),
+		Type:              a.Type,
+		AlertID:           a.ID,
+		RestaurantID:      a.RestaurantID,
+		IngredientID:      a.IngredientID,
+		FoodItemID:        a.FoodItemID,
+		VariantID:         a.VariantID,
+		Name:              a.Name,
+		Variant:           a.Variant,
+		Unit:              a.Unit,
+		Quantity:          a.Quantity,
+		LowStockThreshold: a.LowStockThreshold,
+		Timestamp:         a.CreatedAt.Unix(),
+	}
+}
+
+// StockSettingsRequest represents a request to change when a food item
+// raises a low-stock alert and whether it leaves the menu while sold out
+type StockSettingsRequest struct {
+	LowStockThreshold *int  `json:"low_stock_threshold" binding:"required,min=0"`
+	HideWhenSoldOut   *bool `json:"hide_when_sold_out" binding:"required"`
+}
+
+// RestockRequest represents stock added to a food item, or to one of its
+// variants
+type RestockRequest struct {
+	Quantity  int `json:"quantity" binding:"required,min=1,max=100000"`
+	VariantID int `json:"variant_id" binding:"omitempty,min=1"` // Required when the food item has variants
 }
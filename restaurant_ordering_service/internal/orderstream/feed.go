@@ -0,0 +1,142 @@
+package orderstream
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+// maxAnnounced bounds the keys remembered to skip repeated items
+const maxAnnounced = 256
+
+// Feed delivers new items, such as kitchen tickets or stock alerts, to every
+// display connected to this replica
+type Feed[T any] struct {
+	mu             sync.Mutex
+	maxConnections int
+	key            func(T) string // Identifies repeated items
+	subscriptions  map[*FeedSubscription[T]]struct{}
+	announced      []string // Keys of the latest items, oldest first
+	closed         bool
+}
+
+// TicketFeed delivers new kitchen tickets to the kitchen displays
+type TicketFeed = Feed[models.KitchenTicket]
+
+// AlertFeed delivers the events of new stock alerts to the staff displays
+type AlertFeed = Feed[models.InventoryEvent]
+
+// NewTicketFeed creates a TicketFeed serving at most maxConnections
+// displays. Tickets are told apart by their order.
+func NewTicketFeed(maxConnections int) *TicketFeed {
+	return newFeed(maxConnections, func(ticket models.KitchenTicket) string {
+		return strconv.Itoa(ticket.OrderID)
+	})
+}
+
+// NewAlertFeed creates an AlertFeed serving at most maxConnections displays.
+// Events are told apart by their ID.
+func NewAlertFeed(maxConnections int) *AlertFeed {
+	return newFeed(maxConnections, func(event models.InventoryEvent) string {
+		return event.EventID
+	})
+}
+
+// newFeed creates a Feed serving at most maxConnections displays and
+// telling items apart by key
+func newFeed[T any](maxConnections int, key func(T) string) *Feed[T] {
+	return &Feed[T]{
+		maxConnections: maxConnections,
+		key:            key,
+		subscriptions:  make(map[*FeedSubscription[T]]struct{}),
+	}
+}
+
+// FeedSubscription receives new items
+type FeedSubscription[T any] struct {
+	feed  *Feed[T]
+	items chan T
+}
+
+// Items returns the new items. The channel is closed when the feed ends the
+// subscription because the display fell behind or the feed was closed; the
+// display should then reload and reconnect.
+func (s *FeedSubscription[T]) Items() <-chan T {
+	return s.items
+}
+
+// Close ends the subscription and frees its connection slot
+func (s *FeedSubscription[T]) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.remove(s)
+}
+
+// Subscribe starts receiving new items. It fails if the connection limit is
+// reached.
+func (f *Feed[T]) Subscribe() (*FeedSubscription[T], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, apperrors.New(apperrors.KindUnavailable, apperrors.CodeShuttingDown, "The server is shutting down, please reconnect")
+	}
+	if len(f.subscriptions) >= f.maxConnections {
+		metrics.OrderStreamsRejected.WithLabelValues("server").Inc()
+		return nil, apperrors.TooManyRequests(apperrors.CodeTooManyStreams, "Too many open streams, please try again later")
+	}
+
+	sub := &FeedSubscription[T]{feed: f, items: make(chan T, bufferSize)}
+	f.subscriptions[sub] = struct{}{}
+	return sub, nil
+}
+
+// Publish delivers a new item to every subscription. Items already
+// delivered, such as those of redelivered Kafka events, are ignored.
+func (f *Feed[T]) Publish(item T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := f.key(item)
+	if f.closed || slices.Contains(f.announced, key) {
+		return
+	}
+	f.announced = append(f.announced, key)
+	if len(f.announced) > maxAnnounced {
+		f.announced = f.announced[len(f.announced)-maxAnnounced:]
+	}
+
+	for sub := range f.subscriptions {
+		select {
+		case sub.items <- item:
+		default:
+			metrics.OrderStreamsDropped.Inc()
+			f.remove(sub)
+		}
+	}
+}
+
+// Close ends every subscription. Later subscriptions are refused.
+func (f *Feed[T]) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for sub := range f.subscriptions {
+		f.remove(sub)
+	}
+}
+
+// remove unregisters a subscription and closes its channel, once. The lock
+// must be held.
+func (f *Feed[T]) remove(sub *FeedSubscription[T]) {
+	if _, ok := f.subscriptions[sub]; !ok {
+		return
+	}
+	delete(f.subscriptions, sub)
+	close(sub.items)
+}
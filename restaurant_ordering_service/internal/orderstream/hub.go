@@ -0,0 +1,218 @@
+// Package orderstream fans order status events out to the clients watching
+// an order, new kitchen tickets out to the kitchen displays and stock alerts
+// out to the staff. Every replica consumes all order and inventory events
+// from Kafka into its own hub and feeds, so a client sees each change
+// whichever replica it is connected to.
+package orderstream
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+// bufferSize is how many events a subscription holds before its client is
+// considered too slow and disconnected
+const bufferSize = 16
+
+// maxHistoryPerOrder bounds the events kept for resuming a single order
+const maxHistoryPerOrder = 32
+
+// Hub delivers order status events to subscriptions and remembers recent
+// events so that clients can resume where they left off
+type Hub struct {
+	mu            sync.Mutex
+	limits        config.StreamConfig
+	subscriptions map[int]map[*Subscription]struct{} // By order ID
+	open          int
+	openByUser    map[int]int
+	history       map[int][]historyEntry // Recent events by order ID, oldest first
+	expiries      []expiry               // When each history entry expires, oldest first
+	closed        bool
+}
+
+type historyEntry struct {
+	event    models.OrderStatusEvent
+	received time.Time
+}
+
+type expiry struct {
+	orderID int
+	at      time.Time
+}
+
+// NewHub creates a Hub enforcing the connection limits of cfg
+func NewHub(cfg config.StreamConfig) *Hub {
+	return &Hub{
+		limits:        cfg,
+		subscriptions: make(map[int]map[*Subscription]struct{}),
+		openByUser:    make(map[int]int),
+		history:       make(map[int][]historyEntry),
+	}
+}
+
+// Subscription receives the events of a single order
+type Subscription struct {
+	hub     *Hub
+	orderID int
+	userID  int
+	events  chan models.OrderStatusEvent
+}
+
+// Events returns the events of the order. The channel is closed when the
+// hub ends the subscription because the client fell behind or the hub was
+// closed; the client should then reconnect and resume.
+func (s *Subscription) Events() <-chan models.OrderStatusEvent {
+	return s.events
+}
+
+// Close ends the subscription and frees its connection slot
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Subscribe starts receiving the events of an order on behalf of a user.
+// If lastEventID names an event the hub still remembers, the events that
+// followed it are returned for replay and resumed is true; otherwise the
+// caller must send the current status itself. It fails if a connection
+// limit is reached. Internal callers, such as the gRPC API, subscribe with
+// userID 0 and are only bound by the limit of the server.
+func (h *Hub) Subscribe(orderID, userID int, lastEventID string) (sub *Subscription, replay []models.OrderStatusEvent, resumed bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, nil, false, apperrors.New(apperrors.KindUnavailable, apperrors.CodeShuttingDown, "The server is shutting down, please reconnect")
+	}
+	if h.open >= h.limits.MaxConnections {
+		metrics.OrderStreamsRejected.WithLabelValues("server").Inc()
+		return nil, nil, false, apperrors.TooManyRequests(apperrors.CodeTooManyStreams, "Too many open streams, please try again later")
+	}
+	if userID != 0 && h.openByUser[userID] >= h.limits.MaxConnectionsPerUser {
+		metrics.OrderStreamsRejected.WithLabelValues("user").Inc()
+		return nil, nil, false, apperrors.TooManyRequests(apperrors.CodeTooManyStreams, "Too many open streams for this user, close one first").
+			WithDetail("limit", h.limits.MaxConnectionsPerUser)
+	}
+
+	sub = &Subscription{hub: h, orderID: orderID, userID: userID, events: make(chan models.OrderStatusEvent, bufferSize)}
+	if h.subscriptions[orderID] == nil {
+		h.subscriptions[orderID] = make(map[*Subscription]struct{})
+	}
+	h.subscriptions[orderID][sub] = struct{}{}
+	h.open++
+	h.openByUser[userID]++
+
+	// Replay what the client missed since the last event it received
+	if lastEventID != "" {
+		history := h.history[orderID]
+		i := slices.IndexFunc(history, func(entry historyEntry) bool { return entry.event.EventID == lastEventID })
+		if i >= 0 {
+			resumed = true
+			for _, entry := range history[i+1:] {
+				replay = append(replay, entry.event)
+			}
+		}
+	}
+	return sub, replay, resumed, nil
+}
+
+// Publish delivers an order event to the subscriptions of its order and
+// remembers it for resuming. Events already seen, such as Kafka
+// redeliveries, are ignored.
+func (h *Hub) Publish(orderEvent models.OrderEvent) {
+	event := models.OrderStatusEvent{
+		EventID:   orderEvent.EventID,
+		OrderID:   orderEvent.OrderID,
+		Status:    orderEvent.Status,
+		Timestamp: orderEvent.Timestamp,
+	}
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.expire(now)
+
+	history := h.history[event.OrderID]
+	if slices.ContainsFunc(history, func(entry historyEntry) bool { return entry.event.EventID == event.EventID }) {
+		return
+	}
+	history = append(history, historyEntry{event: event, received: now})
+	if len(history) > maxHistoryPerOrder {
+		history = history[len(history)-maxHistoryPerOrder:]
+	}
+	h.history[event.OrderID] = history
+	h.expiries = append(h.expiries, expiry{orderID: event.OrderID, at: now.Add(h.limits.HistoryRetention)})
+
+	for sub := range h.subscriptions[event.OrderID] {
+		select {
+		case sub.events <- event:
+		default:
+			// The client is not keeping up. Disconnecting it lets it resume
+			// from the history rather than silently miss events.
+			metrics.OrderStreamsDropped.Inc()
+			h.remove(sub)
+		}
+	}
+}
+
+// Close ends every subscription. Later subscriptions are refused.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for _, subs := range h.subscriptions {
+		for sub := range subs {
+			h.remove(sub)
+		}
+	}
+}
+
+// remove unregisters a subscription and closes its channel, once. The lock
+// must be held.
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subscriptions[sub.orderID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscriptions, sub.orderID)
+	}
+	h.open--
+	if h.openByUser[sub.userID]--; h.openByUser[sub.userID] == 0 {
+		delete(h.openByUser, sub.userID)
+	}
+	close(sub.events)
+}
+
+// expire forgets the history entries older than the retention. The lock
+// must be held.
+func (h *Hub) expire(now time.Time) {
+	for len(h.expiries) > 0 && !h.expiries[0].at.After(now) {
+		orderID := h.expiries[0].orderID
+		h.expiries = h.expiries[1:]
+
+		cutoff := now.Add(-h.limits.HistoryRetention)
+		history := slices.DeleteFunc(h.history[orderID], func(entry historyEntry) bool {
+			return !entry.received.After(cutoff)
+		})
+		if len(history) == 0 {
+			delete(h.history, orderID)
+		} else {
+			h.history[orderID] = history
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package orderstream
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/config"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+var testLimits = config.StreamConfig{
+	MaxConnections:        4,
+	MaxConnectionsPerUser: 2,
+	HistoryRetention:      time.Minute,
+}
+
+// orderEvent returns the event of an order changing to status
+func orderEvent(eventID string, orderID int, status string) models.OrderEvent {
+	return models.OrderEvent{EventID: eventID, OrderID: orderID, Status: status, Timestamp: time.Now().Unix()}
+}
+
+// subscribe subscribes to an order, failing the test if it is refused
+func subscribe(t *testing.T, hub *Hub, orderID, userID int, lastEventID string) (*Subscription, []models.OrderStatusEvent, bool) {
+	t.Helper()
+
+	sub, replay, resumed, err := hub.Subscribe(orderID, userID, lastEventID)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	t.Cleanup(sub.Close)
+	return sub, replay, resumed
+}
+
+// receive returns the events waiting on a subscription, and whether its
+// channel was closed
+func receive(sub *Subscription) (events []string, closed bool) {
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return events, true
+			}
+			events = append(events, event.EventID)
+		default:
+			return events, false
+		}
+	}
+}
+
+func equal(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHubDeliversToTheOrder(t *testing.T) {
+	hub := NewHub(testLimits)
+	first, _, _ := subscribe(t, hub, 1, 10, "")
+	second, _, _ := subscribe(t, hub, 1, 11, "")
+	other, _, _ := subscribe(t, hub, 2, 10, "")
+
+	hub.Publish(orderEvent("e1", 1, "pending"))
+
+	for name, sub := range map[string]*Subscription{"first": first, "second": second} {
+		if events, closed := receive(sub); !equal(events, []string{"e1"}) || closed {
+			t.Errorf("%s subscription received %v, closed %v, want [e1]", name, events, closed)
+		}
+	}
+	if events, _ := receive(other); len(events) != 0 {
+		t.Errorf("subscription to another order received %v", events)
+	}
+}
+
+func TestHubResume(t *testing.T) {
+	hub := NewHub(testLimits)
+	hub.Publish(orderEvent("e1", 1, "pending"))
+	hub.Publish(orderEvent("e2", 1, "completed"))
+	hub.Publish(orderEvent("other", 2, "completed"))
+	hub.Publish(orderEvent("e3", 1, "completed"))
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		replay      []string
+		resumed     bool
+	}{
+		{"after a known event", "e1", []string{"e2", "e3"}, true},
+		{"after the latest event", "e3", nil, true},
+		{"after an unknown event", "gone", nil, false},
+		{"after an event of another order", "other", nil, false},
+		{"without Last-Event-ID", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, replay, resumed := subscribe(t, hub, 1, 10, tt.lastEventID)
+
+			var got []string
+			for _, event := range replay {
+				got = append(got, event.EventID)
+			}
+			if !equal(got, tt.replay) || resumed != tt.resumed {
+				t.Errorf("Subscribe() replay, resumed = %v, %v, want %v, %v", got, resumed, tt.replay, tt.resumed)
+			}
+		})
+	}
+}
+
+func TestHubIgnoresRedeliveredEvents(t *testing.T) {
+	hub := NewHub(testLimits)
+	sub, _, _ := subscribe(t, hub, 1, 10, "")
+
+	hub.Publish(orderEvent("e1", 1, "pending"))
+	hub.Publish(orderEvent("e1", 1, "pending"))
+	hub.Publish(orderEvent("e2", 1, "completed"))
+
+	if events, _ := receive(sub); !equal(events, []string{"e1", "e2"}) {
+		t.Errorf("received %v, want [e1 e2]", events)
+	}
+	_, replay, _ := subscribe(t, hub, 1, 10, "e1")
+	if len(replay) != 1 || replay[0].EventID != "e2" {
+		t.Errorf("replay after e1 = %+v, want only e2", replay)
+	}
+}
+
+func TestHubLimits(t *testing.T) {
+	t.Run("per user", func(t *testing.T) {
+		hub := NewHub(testLimits)
+		subscribe(t, hub, 1, 10, "")
+		last, _, _ := subscribe(t, hub, 2, 10, "")
+
+		if _, _, _, err := hub.Subscribe(3, 10, ""); !apperrors.HasCode(err, apperrors.CodeTooManyStreams) {
+			t.Fatalf("Subscribe() over the user limit error = %v, want %s", err, apperrors.CodeTooManyStreams)
+		}
+		subscribe(t, hub, 3, 11, "") // Other users are not affected
+
+		// Closing a stream frees its slot
+		last.Close()
+		subscribe(t, hub, 3, 10, "")
+	})
+
+	t.Run("internal callers are only bound by the server", func(t *testing.T) {
+		hub := NewHub(testLimits)
+		for i := range testLimits.MaxConnections {
+			subscribe(t, hub, i+1, 0, "")
+		}
+		if _, _, _, err := hub.Subscribe(9, 0, ""); !apperrors.HasCode(err, apperrors.CodeTooManyStreams) {
+			t.Fatalf("Subscribe() over the server limit error = %v, want %s", err, apperrors.CodeTooManyStreams)
+		}
+	})
+
+	t.Run("per server", func(t *testing.T) {
+		hub := NewHub(testLimits)
+		for i := range testLimits.MaxConnections {
+			subscribe(t, hub, 1, 10+i, "")
+		}
+		if _, _, _, err := hub.Subscribe(1, 99, ""); !apperrors.HasCode(err, apperrors.CodeTooManyStreams) {
+			t.Fatalf("Subscribe() over the server limit error = %v, want %s", err, apperrors.CodeTooManyStreams)
+		}
+	})
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(testLimits)
+	slow, _, _ := subscribe(t, hub, 1, 10, "")
+
+	var published []string
+	for i := range bufferSize + 1 {
+		eventID := "e" + strconv.Itoa(i)
+		hub.Publish(orderEvent(eventID, 1, "pending"))
+		published = append(published, eventID)
+	}
+
+	// The buffered events are still delivered, then the channel is closed
+	// so that the client reconnects and resumes from the last one
+	events, closed := receive(slow)
+	if !equal(events, published[:bufferSize]) || !closed {
+		t.Fatalf("slow subscription received %v, closed %v, want the first %d events and closed", events, closed, bufferSize)
+	}
+	_, replay, resumed := subscribe(t, hub, 1, 10, events[len(events)-1])
+	if !resumed || len(replay) != 1 || replay[0].EventID != published[bufferSize] {
+		t.Errorf("resuming replay, resumed = %+v, %v, want the missed event", replay, resumed)
+	}
+
+	// The slot of the dropped subscription is free again
+	if hub.open != 1 || hub.openByUser[10] != 1 {
+		t.Errorf("open, open by the user = %d, %d, want 1, 1", hub.open, hub.openByUser[10])
+	}
+}
+
+func TestHubForgetsExpiredHistory(t *testing.T) {
+	limits := testLimits
+	limits.HistoryRetention = 50 * time.Millisecond
+	hub := NewHub(limits)
+
+	hub.Publish(orderEvent("e1", 1, "pending"))
+	hub.Publish(orderEvent("other", 2, "pending"))
+	if _, _, resumed := subscribe(t, hub, 1, 10, "e1"); !resumed {
+		t.Fatal("Subscribe() did not resume after a recent event")
+	}
+
+	time.Sleep(2 * limits.HistoryRetention)
+	hub.Publish(orderEvent("e2", 1, "completed"))
+
+	if _, _, resumed := subscribe(t, hub, 1, 10, "e1"); resumed {
+		t.Error("Subscribe() resumed after an expired event")
+	}
+	if _, ok := hub.history[2]; ok {
+		t.Error("the expired history of an order without new events is kept")
+	}
+	if _, replay, resumed := subscribe(t, hub, 1, 11, "e2"); !resumed || len(replay) != 0 {
+		t.Errorf("Subscribe() after the latest event replay, resumed = %+v, %v, want none, true", replay, resumed)
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(testLimits)
+	sub, _, _ := subscribe(t, hub, 1, 10, "")
+
+	hub.Close()
+
+	if _, closed := receive(sub); !closed {
+		t.Error("Close() left a subscription open")
+	}
+	if _, _, _, err := hub.Subscribe(1, 10, ""); !apperrors.HasCode(err, apperrors.CodeShuttingDown) {
+		t.Errorf("Subscribe() after Close() error = %v, want %s", err, apperrors.CodeShuttingDown)
+	}
+	hub.Publish(orderEvent("e1", 1, "pending")) // Must not panic on the closed channel
+}
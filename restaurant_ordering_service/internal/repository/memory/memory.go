@@ -0,0 +1,1169 @@
+// Package memory implements the repositories in memory, so that handlers can
+// be exercised without a database
+package memory
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// Store holds the data shared by the in-memory repositories
+type Store struct {
+	mu          sync.Mutex
+	txMu        sync.Mutex
+	restaurants map[int]models.Restaurant
+	hours       map[int][]models.OpeningHours            // By restaurant ID
+	exceptions  map[int]map[string]models.HoursException // By restaurant ID and date
+	users       map[int]models.User
+	foodItems   map[int]models.FoodItem
+	bundles     map[int]models.Bundle
+	orders      map[int]models.Order
+	tickets     map[int]ticket       // By order ID
+	progress    map[int]itemProgress // By order item ID
+	ingredients map[int]models.Ingredient
+	recipes     map[recipeKey][]models.RecipeIngredient
+	movements   []models.StockMovement
+	alerts      []models.StockAlert
+	nextID      int
+}
+
+// recipeKey identifies the recipe of a food item or of a modifier
+type recipeKey struct {
+	foodItemID int
+	modifierID int
+}
+
+// ticket is the kitchen state of a paid order
+type ticket struct {
+	priority  int
+	createdAt time.Time
+	bumpedAt  *time.Time
+}
+
+// itemProgress is the preparation state of an order item
+type itemProgress struct {
+	startedAt *time.Time
+	doneAt    *time.Time
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		restaurants: make(map[int]models.Restaurant),
+		hours:       make(map[int][]models.OpeningHours),
+		exceptions:  make(map[int]map[string]models.HoursException),
+		users:       make(map[int]models.User),
+		foodItems:   make(map[int]models.FoodItem),
+		bundles:     make(map[int]models.Bundle),
+		orders:      make(map[int]models.Order),
+		tickets:     make(map[int]ticket),
+		progress:    make(map[int]itemProgress),
+		ingredients: make(map[int]models.Ingredient),
+		recipes:     make(map[recipeKey][]models.RecipeIngredient),
+	}
+}
+
+// AddRestaurant inserts a restaurant, assigning an ID if it has none
+func (s *Store) AddRestaurant(restaurant models.Restaurant) models.Restaurant {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if restaurant.ID == 0 {
+		restaurant.ID = s.newID()
+	}
+	if restaurant.Timezone == "" {
+		restaurant.Timezone = "UTC"
+	}
+	if restaurant.Currency == "" {
+		restaurant.Currency = "INR"
+	}
+	s.restaurants[restaurant.ID] = restaurant
+	return restaurant
+}
+
+// AddUser inserts a user, assigning an ID if it has none
+func (s *Store) AddUser(user models.User) models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = s.newID()
+	}
+	if user.Role == "" {
+		user.Role = models.RoleCustomer
+	}
+	s.users[user.ID] = user
+	return user
+}
+
+// AddFoodItem inserts a food item with its variants and modifier groups,
+// assigning IDs to those that have none. Its restaurant must have been
+// added.
+func (s *Store) AddFoodItem(item models.FoodItem) models.FoodItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item = copyFoodItem(item)
+	if item.ID == 0 {
+		item.ID = s.newID()
+	}
+	if item.Station == "" {
+		item.Station = "main"
+	}
+	for i := range item.Variants {
+		if item.Variants[i].ID == 0 {
+			item.Variants[i].ID = s.newID()
+		}
+	}
+	for i := range item.ModifierGroups {
+		group := &item.ModifierGroups[i]
+		if group.ID == 0 {
+			group.ID = s.newID()
+		}
+		for j := range group.Modifiers {
+			if group.Modifiers[j].ID == 0 {
+				group.Modifiers[j].ID = s.newID()
+			}
+		}
+	}
+	s.foodItems[item.ID] = item
+	return copyFoodItem(item)
+}
+
+// AddBundle inserts a bundle with its slots and options, assigning IDs to
+// those that have none and naming options after their food items. The food
+// items must have been added.
+func (s *Store) AddBundle(bundle models.Bundle) models.Bundle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bundle = copyBundle(bundle)
+	if bundle.ID == 0 {
+		bundle.ID = s.newID()
+	}
+	for i := range bundle.Slots {
+		slot := &bundle.Slots[i]
+		if slot.ID == 0 {
+			slot.ID = s.newID()
+		}
+		if slot.Quantity == 0 {
+			slot.Quantity = 1
+		}
+		for j := range slot.Options {
+			option := &slot.Options[j]
+			if option.ID == 0 {
+				option.ID = s.newID()
+			}
+			if option.Name == "" {
+				foodItem := s.foodItems[option.FoodItemID]
+				option.Name = foodItem.Name
+				for _, variant := range foodItem.Variants {
+					if variant.ID == option.VariantID {
+						option.Name += " (" + variant.Name + ")"
+					}
+				}
+			}
+		}
+	}
+	s.bundles[bundle.ID] = bundle
+	return copyBundle(bundle)
+}
+
+// AddIngredient inserts an ingredient, assigning an ID if it has none
+func (s *Store) AddIngredient(ingredient models.Ingredient) models.Ingredient {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ingredient.ID == 0 {
+		ingredient.ID = s.newID()
+	}
+	s.ingredients[ingredient.ID] = ingredient
+	return ingredient
+}
+
+// AddRecipe sets the recipe of a food item or modifier. Its ingredients must
+// have been added.
+func (s *Store) AddRecipe(recipe models.Recipe) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recipes[recipeKey{recipe.FoodItemID, recipe.ModifierID}] = append([]models.RecipeIngredient(nil), recipe.Ingredients...)
+}
+
+// Repos returns repositories backed by the store
+func (s *Store) Repos() repository.Repos {
+	return repository.Repos{
+		Restaurants: &RestaurantRepo{s: s},
+		Users:       &UserRepo{s: s},
+		Menu:        &MenuRepo{s: s},
+		Orders:      &OrderRepo{s: s},
+		Kitchen:     &KitchenRepo{s: s},
+		Inventory:   &InventoryRepo{s: s},
+		Alerts:      &AlertRepo{s: s},
+	}
+}
+
+// Do runs fn against the store, restoring the previous contents if it
+// returns an error. Units of work are serialized with each other, but
+// statements outside a unit of work may observe uncommitted changes.
+func (s *Store) Do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.clone()
+	s.mu.Unlock()
+
+	if err := fn(s.Repos()); err != nil {
+		s.mu.Lock()
+		s.restaurants, s.hours, s.exceptions = snapshot.restaurants, snapshot.hours, snapshot.exceptions
+		s.users, s.foodItems, s.bundles = snapshot.users, snapshot.foodItems, snapshot.bundles
+		s.orders, s.nextID = snapshot.orders, snapshot.nextID
+		s.tickets, s.progress = snapshot.tickets, snapshot.progress
+		s.ingredients, s.recipes, s.movements = snapshot.ingredients, snapshot.recipes, snapshot.movements
+		s.alerts = snapshot.alerts
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// newID returns the next ID. The caller must hold mu.
+func (s *Store) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// clone copies the store contents. The caller must hold mu.
+func (s *Store) clone() *Store {
+	c := NewStore()
+	for id, restaurant := range s.restaurants {
+		c.restaurants[id] = restaurant
+	}
+	for id, hours := range s.hours {
+		c.hours[id] = append([]models.OpeningHours(nil), hours...)
+	}
+	for id, exceptions := range s.exceptions {
+		c.exceptions[id] = make(map[string]models.HoursException, len(exceptions))
+		for date, exception := range exceptions {
+			c.exceptions[id][date] = exception
+		}
+	}
+	for id, user := range s.users {
+		c.users[id] = user
+	}
+	for id, item := range s.foodItems {
+		c.foodItems[id] = copyFoodItem(item)
+	}
+	for id, bundle := range s.bundles {
+		c.bundles[id] = copyBundle(bundle)
+	}
+	for id, order := range s.orders {
+		c.orders[id] = copyOrder(order)
+	}
+	for id, t := range s.tickets {
+		c.tickets[id] = t
+	}
+	for id, p := range s.progress {
+		c.progress[id] = p
+	}
+	for id, ingredient := range s.ingredients {
+		c.ingredients[id] = ingredient
+	}
+	for key, ingredients := range s.recipes {
+		c.recipes[key] = append([]models.RecipeIngredient(nil), ingredients...)
+	}
+	c.movements = append([]models.StockMovement(nil), s.movements...)
+	c.alerts = append([]models.StockAlert(nil), s.alerts...)
+	c.nextID = s.nextID
+	return c
+}
+
+// RestaurantRepo is an in-memory repository.RestaurantRepo
+type RestaurantRepo struct {
+	s *Store
+}
+
+// List returns every restaurant ordered by ID
+func (r *RestaurantRepo) List(ctx context.Context) ([]models.Restaurant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	restaurants := make([]models.Restaurant, 0, len(r.s.restaurants))
+	for _, restaurant := range r.s.restaurants {
+		restaurants = append(restaurants, restaurant)
+	}
+	sort.Slice(restaurants, func(i, j int) bool {
+		return restaurants[i].ID < restaurants[j].ID
+	})
+	return restaurants, nil
+}
+
+// Get returns a restaurant by ID
+func (r *RestaurantRepo) Get(ctx context.Context, id int) (models.Restaurant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	restaurant, ok := r.s.restaurants[id]
+	if !ok {
+		return models.Restaurant{}, repository.ErrNotFound
+	}
+	return restaurant, nil
+}
+
+// GetBySlug returns a restaurant by slug
+func (r *RestaurantRepo) GetBySlug(ctx context.Context, slug string) (models.Restaurant, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, restaurant := range r.s.restaurants {
+		if restaurant.Slug == slug {
+			return restaurant, nil
+		}
+	}
+	return models.Restaurant{}, repository.ErrNotFound
+}
+
+// WeeklyHours returns the opening hours ordered by weekday and time
+func (r *RestaurantRepo) WeeklyHours(ctx context.Context, restaurantID int) ([]models.OpeningHours, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	hours := append([]models.OpeningHours(nil), r.s.hours[restaurantID]...)
+	sort.Slice(hours, func(i, j int) bool {
+		if hours[i].Weekday != hours[j].Weekday {
+			return hours[i].Weekday < hours[j].Weekday
+		}
+		return hours[i].OpensAt < hours[j].OpensAt
+	})
+	return hours, nil
+}
+
+// SetWeeklyHours replaces the opening hours and the last-order cutoff
+func (r *RestaurantRepo) SetWeeklyHours(ctx context.Context, restaurantID, lastOrderMinutes int, hours []models.OpeningHours) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	restaurant, ok := r.s.restaurants[restaurantID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	restaurant.LastOrderMinutes = lastOrderMinutes
+	r.s.restaurants[restaurantID] = restaurant
+	r.s.hours[restaurantID] = append([]models.OpeningHours(nil), hours...)
+	return nil
+}
+
+// Exceptions returns the exceptions dated from from to to inclusive
+func (r *RestaurantRepo) Exceptions(ctx context.Context, restaurantID int, from, to string) ([]models.HoursException, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var exceptions []models.HoursException
+	for date, exception := range r.s.exceptions[restaurantID] {
+		if date >= from && date <= to {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	sort.Slice(exceptions, func(i, j int) bool {
+		return exceptions[i].Date < exceptions[j].Date
+	})
+	return exceptions, nil
+}
+
+// SetException creates or replaces the exception on its date
+func (r *RestaurantRepo) SetException(ctx context.Context, restaurantID int, exception models.HoursException) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if r.s.exceptions[restaurantID] == nil {
+		r.s.exceptions[restaurantID] = make(map[string]models.HoursException)
+	}
+	r.s.exceptions[restaurantID][exception.Date] = exception
+	return nil
+}
+
+// DeleteException removes the exception on date
+func (r *RestaurantRepo) DeleteException(ctx context.Context, restaurantID int, date string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.exceptions[restaurantID][date]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.exceptions[restaurantID], date)
+	return nil
+}
+
+// SetPause pauses ordering from pausedAt until until, or resumes it when
+// pausedAt is nil
+func (r *RestaurantRepo) SetPause(ctx context.Context, restaurantID int, pausedAt, until *time.Time, reason string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	restaurant, ok := r.s.restaurants[restaurantID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	restaurant.PausedAt, restaurant.PausedUntil, restaurant.PauseReason = pausedAt, until, reason
+	r.s.restaurants[restaurantID] = restaurant
+	return nil
+}
+
+// UserRepo is an in-memory repository.UserRepo
+type UserRepo struct {
+	s *Store
+}
+
+// GetByUsername returns the user including their password
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+// GetByID returns the user without their password
+func (r *UserRepo) GetByID(ctx context.Context, id int) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	user, ok := r.s.users[id]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// MenuRepo is an in-memory repository.MenuRepo
+type MenuRepo struct {
+	s *Store
+}
+
+// List returns every food item of a restaurant ordered by ID
+func (r *MenuRepo) List(ctx context.Context, restaurantID int) ([]models.FoodItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	foodItems := make([]models.FoodItem, 0)
+	for _, item := range r.s.foodItems {
+		if item.RestaurantID == restaurantID {
+			foodItems = append(foodItems, copyFoodItem(item))
+		}
+	}
+	sort.Slice(foodItems, func(i, j int) bool {
+		return foodItems[i].ID < foodItems[j].ID
+	})
+	return foodItems, nil
+}
+
+// ListForUpdate returns the food items of a restaurant with the given IDs
+// ordered by ID. Units of work on the store are serialized, so nothing needs
+// locking.
+func (r *MenuRepo) ListForUpdate(ctx context.Context, restaurantID int, ids []int) ([]models.FoodItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var foodItems []models.FoodItem
+	for _, id := range ids {
+		if item, ok := r.s.foodItems[id]; ok && item.RestaurantID == restaurantID {
+			foodItems = append(foodItems, copyFoodItem(item))
+		}
+	}
+	sort.Slice(foodItems, func(i, j int) bool {
+		return foodItems[i].ID < foodItems[j].ID
+	})
+	return foodItems, nil
+}
+
+// Get returns a single food item of a restaurant
+func (r *MenuRepo) Get(ctx context.Context, restaurantID, id int) (models.FoodItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item, ok := r.s.foodItems[id]
+	if !ok || item.RestaurantID != restaurantID {
+		return models.FoodItem{}, repository.ErrNotFound
+	}
+	return copyFoodItem(item), nil
+}
+
+// ListBundles returns every bundle of a restaurant ordered by ID
+func (r *MenuRepo) ListBundles(ctx context.Context, restaurantID int) ([]models.Bundle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	bundles := make([]models.Bundle, 0)
+	for _, bundle := range r.s.bundles {
+		if bundle.RestaurantID == restaurantID {
+			bundles = append(bundles, copyBundle(bundle))
+		}
+	}
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].ID < bundles[j].ID
+	})
+	return bundles, nil
+}
+
+// GetBundle returns a single bundle of a restaurant
+func (r *MenuRepo) GetBundle(ctx context.Context, restaurantID, id int) (models.Bundle, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	bundle, ok := r.s.bundles[id]
+	if !ok || bundle.RestaurantID != restaurantID {
+		return models.Bundle{}, repository.ErrNotFound
+	}
+	return copyBundle(bundle), nil
+}
+
+// DecrementStock removes quantity from the food item's stock
+func (r *MenuRepo) DecrementStock(ctx context.Context, restaurantID, id, quantity int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item, ok := r.s.foodItems[id]
+	if !ok || item.RestaurantID != restaurantID || item.Quantity < quantity {
+		return repository.ErrInsufficientStock
+	}
+	item.Quantity -= quantity
+	r.s.foodItems[id] = item
+	return nil
+}
+
+// DecrementVariantStock removes quantity from the variant's stock
+func (r *MenuRepo) DecrementVariantStock(ctx context.Context, restaurantID, variantID, quantity int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for id, item := range r.s.foodItems {
+		if item.RestaurantID != restaurantID {
+			continue
+		}
+		for i, variant := range item.Variants {
+			if variant.ID != variantID {
+				continue
+			}
+			if variant.Quantity < quantity {
+				return repository.ErrInsufficientStock
+			}
+			item = copyFoodItem(item)
+			item.Variants[i].Quantity -= quantity
+			r.s.foodItems[id] = item
+			return nil
+		}
+	}
+	return repository.ErrInsufficientStock
+}
+
+// SetStockSettings changes the low-stock threshold of a food item and
+// whether it is hidden while sold out
+func (r *MenuRepo) SetStockSettings(ctx context.Context, restaurantID, id, threshold int, hideWhenSoldOut bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item, ok := r.s.foodItems[id]
+	if !ok || item.RestaurantID != restaurantID {
+		return repository.ErrNotFound
+	}
+	item.LowStockThreshold, item.HideWhenSoldOut = threshold, hideWhenSoldOut
+	r.s.foodItems[id] = item
+	return nil
+}
+
+// Restock adds quantity to the stock of a food item or of its variant
+func (r *MenuRepo) Restock(ctx context.Context, restaurantID, id, variantID, quantity int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item, ok := r.s.foodItems[id]
+	if !ok || item.RestaurantID != restaurantID {
+		return repository.ErrNotFound
+	}
+	if variantID == 0 {
+		item.Quantity += quantity
+		r.s.foodItems[id] = item
+		return nil
+	}
+	for i, variant := range item.Variants {
+		if variant.ID == variantID {
+			item = copyFoodItem(item)
+			item.Variants[i].Quantity += quantity
+			r.s.foodItems[id] = item
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+// OrderRepo is an in-memory repository.OrderRepo
+type OrderRepo struct {
+	s *Store
+}
+
+// Create inserts the order with its items and bundles, setting their IDs
+func (r *OrderRepo) Create(ctx context.Context, order *models.Order) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	order.ID = r.s.newID()
+	for i := range order.OrderItems {
+		order.OrderItems[i].ID = r.s.newID()
+		order.OrderItems[i].OrderID = order.ID
+	}
+	for i := range order.Bundles {
+		bundle := &order.Bundles[i]
+		bundle.ID = r.s.newID()
+		bundle.OrderID = order.ID
+		for j := range bundle.Items {
+			bundle.Items[j].ID = r.s.newID()
+			bundle.Items[j].OrderID, bundle.Items[j].OrderBundleID = order.ID, bundle.ID
+		}
+	}
+
+	r.s.orders[order.ID] = copyOrder(*order)
+	return nil
+}
+
+// Get returns the order with its items and bundles
+func (r *OrderRepo) Get(ctx context.Context, id int) (models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	order, ok := r.s.orders[id]
+	if !ok {
+		return models.Order{}, repository.ErrNotFound
+	}
+	return copyOrder(order), nil
+}
+
+// GetForUpdate returns the order with its items. Units of work are already
+// serialized, so no further locking is needed.
+func (r *OrderRepo) GetForUpdate(ctx context.Context, id int) (models.Order, error) {
+	return r.Get(ctx, id)
+}
+
+// UpdateStatus changes the status of an order if it is still in status from
+func (r *OrderRepo) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	order, ok := r.s.orders[id]
+	if !ok || order.Status != from {
+		return repository.ErrStatusChanged
+	}
+	order.Status = to
+	r.s.orders[id] = order
+	return nil
+}
+
+// KitchenRepo is an in-memory repository.KitchenRepo
+type KitchenRepo struct {
+	s *Store
+}
+
+// CreateTicket opens the ticket of a paid order
+func (r *KitchenRepo) CreateTicket(ctx context.Context, orderID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.orders[orderID]; !ok {
+		return repository.ErrNotFound
+	}
+	r.s.tickets[orderID] = ticket{createdAt: time.Now()}
+	return nil
+}
+
+// Queue returns the tickets that have not been bumped, highest priority and
+// then oldest first, with their items
+func (r *KitchenRepo) Queue(ctx context.Context, restaurantID int) ([]models.KitchenTicket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var tickets []models.KitchenTicket
+	for orderID, t := range r.s.tickets {
+		if t.bumpedAt == nil && r.s.orders[orderID].RestaurantID == restaurantID {
+			tickets = append(tickets, r.s.kitchenTicket(orderID, t))
+		}
+	}
+	sort.Slice(tickets, func(i, j int) bool {
+		a, b := tickets[i], tickets[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.OrderID < b.OrderID
+	})
+	return tickets, nil
+}
+
+// GetTicket returns a ticket with its items, bumped or not
+func (r *KitchenRepo) GetTicket(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.ticket(restaurantID, orderID)
+	if !ok {
+		return models.KitchenTicket{}, repository.ErrNotFound
+	}
+	return r.s.kitchenTicket(orderID, t), nil
+}
+
+// GetItem returns an order item that has a ticket
+func (r *KitchenRepo) GetItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	item, ok := r.s.ticketItem(restaurantID, itemID)
+	if !ok {
+		return models.KitchenItem{}, repository.ErrNotFound
+	}
+	return r.s.kitchenItem(item), nil
+}
+
+// StartItem marks an item as started, only if it was not already
+func (r *KitchenRepo) StartItem(ctx context.Context, restaurantID, itemID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.progress[itemID]
+	if _, ok := r.s.ticketItem(restaurantID, itemID); !ok || p.startedAt != nil {
+		return repository.ErrKitchenStateChanged
+	}
+	now := time.Now()
+	p.startedAt = &now
+	r.s.progress[itemID] = p
+	return nil
+}
+
+// FinishItem marks an item as done, only if it was started and not already
+// done
+func (r *KitchenRepo) FinishItem(ctx context.Context, restaurantID, itemID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	p := r.s.progress[itemID]
+	if _, ok := r.s.ticketItem(restaurantID, itemID); !ok || p.startedAt == nil || p.doneAt != nil {
+		return repository.ErrKitchenStateChanged
+	}
+	now := time.Now()
+	p.doneAt = &now
+	r.s.progress[itemID] = p
+	return nil
+}
+
+// SetPriority changes the priority of a ticket
+func (r *KitchenRepo) SetPriority(ctx context.Context, restaurantID, orderID, priority int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.ticket(restaurantID, orderID)
+	if !ok {
+		return repository.ErrNotFound
+	}
+	t.priority = priority
+	r.s.tickets[orderID] = t
+	return nil
+}
+
+// Bump takes a ticket off the queue, only if it is on it
+func (r *KitchenRepo) Bump(ctx context.Context, restaurantID, orderID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.ticket(restaurantID, orderID)
+	if !ok || t.bumpedAt != nil {
+		return repository.ErrKitchenStateChanged
+	}
+	now := time.Now()
+	t.bumpedAt = &now
+	r.s.tickets[orderID] = t
+	return nil
+}
+
+// Recall puts a bumped ticket back on the queue
+func (r *KitchenRepo) Recall(ctx context.Context, restaurantID, orderID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	t, ok := r.s.ticket(restaurantID, orderID)
+	if !ok || t.bumpedAt == nil {
+		return repository.ErrKitchenStateChanged
+	}
+	t.bumpedAt = nil
+	r.s.tickets[orderID] = t
+	return nil
+}
+
+// PrepTimes returns the average preparation time of each food item over the
+// items that were started and finished, ordered by food item ID
+func (r *KitchenRepo) PrepTimes(ctx context.Context, restaurantID int) ([]models.PrepTime, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	byFoodItem := make(map[int]*models.PrepTime)
+	for _, order := range r.s.orders {
+		if order.RestaurantID != restaurantID {
+			continue
+		}
+		for _, item := range order.AllItems() {
+			p := r.s.progress[item.ID]
+			if p.doneAt == nil {
+				continue
+			}
+			prepTime, ok := byFoodItem[item.FoodItemID]
+			if !ok {
+				foodItem := r.s.foodItems[item.FoodItemID]
+				prepTime = &models.PrepTime{FoodItemID: foodItem.ID, Name: foodItem.Name, Station: foodItem.Station}
+				byFoodItem[item.FoodItemID] = prepTime
+			}
+			// Keep the running total in AverageSeconds until all items are seen
+			prepTime.Prepared++
+			prepTime.AverageSeconds += p.doneAt.Sub(*p.startedAt).Seconds()
+		}
+	}
+
+	prepTimes := make([]models.PrepTime, 0, len(byFoodItem))
+	for _, prepTime := range byFoodItem {
+		prepTime.AverageSeconds /= float64(prepTime.Prepared)
+		prepTimes = append(prepTimes, *prepTime)
+	}
+	sort.Slice(prepTimes, func(i, j int) bool {
+		return prepTimes[i].FoodItemID < prepTimes[j].FoodItemID
+	})
+	return prepTimes, nil
+}
+
+// InventoryRepo is an in-memory repository.InventoryRepo
+type InventoryRepo struct {
+	s *Store
+}
+
+// ListIngredients returns the ingredients of a restaurant ordered by name
+func (r *InventoryRepo) ListIngredients(ctx context.Context, restaurantID int) ([]models.Ingredient, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var ingredients []models.Ingredient
+	for _, ingredient := range r.s.ingredients {
+		if ingredient.RestaurantID == restaurantID {
+			ingredients = append(ingredients, ingredient)
+		}
+	}
+	sort.Slice(ingredients, func(i, j int) bool {
+		return ingredients[i].Name < ingredients[j].Name
+	})
+	return ingredients, nil
+}
+
+// GetIngredient returns a single ingredient of a restaurant
+func (r *InventoryRepo) GetIngredient(ctx context.Context, restaurantID, id int) (models.Ingredient, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ingredient, ok := r.s.ingredients[id]
+	if !ok || ingredient.RestaurantID != restaurantID {
+		return models.Ingredient{}, repository.ErrNotFound
+	}
+	return ingredient, nil
+}
+
+// CreateIngredient inserts an ingredient, setting its ID
+func (r *InventoryRepo) CreateIngredient(ctx context.Context, ingredient *models.Ingredient) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, existing := range r.s.ingredients {
+		if existing.RestaurantID == ingredient.RestaurantID && existing.Name == ingredient.Name {
+			return repository.ErrDuplicate
+		}
+	}
+	ingredient.ID = r.s.newID()
+	r.s.ingredients[ingredient.ID] = *ingredient
+	return nil
+}
+
+// SetThreshold changes the low-stock threshold of an ingredient
+func (r *InventoryRepo) SetThreshold(ctx context.Context, restaurantID, id int, threshold float64) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	ingredient, ok := r.s.ingredients[id]
+	if !ok || ingredient.RestaurantID != restaurantID {
+		return repository.ErrNotFound
+	}
+	ingredient.LowStockThreshold = threshold
+	r.s.ingredients[id] = ingredient
+	return nil
+}
+
+// AdjustStock changes the stock of an ingredient and records the movement
+func (r *InventoryRepo) AdjustStock(ctx context.Context, restaurantID int, movement *models.StockMovement) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// Quantities are kept to three decimals, like the NUMERIC columns
+	ingredient, ok := r.s.ingredients[movement.IngredientID]
+	quantity := math.Round((ingredient.Quantity+movement.Change)*1000) / 1000
+	if !ok || ingredient.RestaurantID != restaurantID || quantity < 0 {
+		return repository.ErrInsufficientStock
+	}
+	ingredient.Quantity = quantity
+	r.s.ingredients[ingredient.ID] = ingredient
+
+	movement.ID = r.s.newID()
+	movement.QuantityAfter = ingredient.Quantity
+	movement.CreatedAt = time.Now().UTC()
+	r.s.movements = append(r.s.movements, *movement)
+	return nil
+}
+
+// Movements returns the latest stock movements of a restaurant, newest first
+func (r *InventoryRepo) Movements(ctx context.Context, restaurantID, ingredientID, limit int) ([]models.StockMovement, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var movements []models.StockMovement
+	for i := len(r.s.movements) - 1; i >= 0 && len(movements) < limit; i-- {
+		movement := r.s.movements[i]
+		if r.s.ingredients[movement.IngredientID].RestaurantID != restaurantID {
+			continue
+		}
+		if ingredientID == 0 || movement.IngredientID == ingredientID {
+			movements = append(movements, movement)
+		}
+	}
+	return movements, nil
+}
+
+// Recipes returns the recipes of the food items and modifiers of a
+// restaurant
+func (r *InventoryRepo) Recipes(ctx context.Context, restaurantID int) ([]models.Recipe, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var recipes []models.Recipe
+	for key, ingredients := range r.s.recipes {
+		recipe := models.Recipe{FoodItemID: key.foodItemID, ModifierID: key.modifierID}
+		for _, ingredient := range ingredients {
+			stock := r.s.ingredients[ingredient.IngredientID]
+			if stock.RestaurantID != restaurantID {
+				continue
+			}
+			ingredient.Name, ingredient.Unit = stock.Name, stock.Unit
+			recipe.Ingredients = append(recipe.Ingredients, ingredient)
+		}
+		if len(recipe.Ingredients) > 0 {
+			sort.Slice(recipe.Ingredients, func(i, j int) bool {
+				return recipe.Ingredients[i].IngredientID < recipe.Ingredients[j].IngredientID
+			})
+			recipes = append(recipes, recipe)
+		}
+	}
+	sort.Slice(recipes, func(i, j int) bool {
+		if recipes[i].ModifierID != recipes[j].ModifierID {
+			return recipes[i].ModifierID < recipes[j].ModifierID
+		}
+		return recipes[i].FoodItemID < recipes[j].FoodItemID
+	})
+	return recipes, nil
+}
+
+// SetRecipe replaces the recipe of a food item or modifier of a restaurant
+func (r *InventoryRepo) SetRecipe(ctx context.Context, restaurantID int, recipe models.Recipe) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	key := recipeKey{recipe.FoodItemID, recipe.ModifierID}
+	if len(recipe.Ingredients) == 0 {
+		delete(r.s.recipes, key)
+		return nil
+	}
+	ingredients := make([]models.RecipeIngredient, 0, len(recipe.Ingredients))
+	for _, ingredient := range recipe.Ingredients {
+		ingredients = append(ingredients, models.RecipeIngredient{IngredientID: ingredient.IngredientID, Quantity: ingredient.Quantity})
+	}
+	r.s.recipes[key] = ingredients
+	return nil
+}
+
+// AlertRepo is an in-memory repository.AlertRepo
+type AlertRepo struct {
+	s *Store
+}
+
+// Create inserts an alert, setting its ID and CreatedAt
+func (r *AlertRepo) Create(ctx context.Context, alert *models.StockAlert) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	alert.ID = r.s.newID()
+	alert.CreatedAt = time.Now().UTC()
+	r.s.alerts = append(r.s.alerts, *alert)
+	return nil
+}
+
+// List returns the latest alerts of a restaurant, newest first
+func (r *AlertRepo) List(ctx context.Context, restaurantID int, openOnly bool, limit int) ([]models.StockAlert, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var alerts []models.StockAlert
+	for i := len(r.s.alerts) - 1; i >= 0 && len(alerts) < limit; i-- {
+		alert := r.s.alerts[i]
+		if alert.RestaurantID == restaurantID && (!openOnly || alert.AcknowledgedAt == nil) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// Acknowledge closes an open alert of a restaurant and returns it
+func (r *AlertRepo) Acknowledge(ctx context.Context, restaurantID, id, userID int) (models.StockAlert, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for i, alert := range r.s.alerts {
+		if alert.ID != id || alert.RestaurantID != restaurantID {
+			continue
+		}
+		if alert.AcknowledgedAt == nil {
+			now := time.Now().UTC()
+			alert.AcknowledgedAt, alert.AcknowledgedBy = &now, userID
+			r.s.alerts[i] = alert
+		}
+		return alert, nil
+	}
+	return models.StockAlert{}, repository.ErrNotFound
+}
+
+// Resolve closes the open alerts of an ingredient, food item or variant
+func (r *AlertRepo) Resolve(ctx context.Context, restaurantID, ingredientID, foodItemID, variantID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for i, alert := range r.s.alerts {
+		if alert.RestaurantID == restaurantID && alert.AcknowledgedAt == nil &&
+			alert.IngredientID == ingredientID && alert.FoodItemID == foodItemID && alert.VariantID == variantID {
+			r.s.alerts[i].AcknowledgedAt = &now
+		}
+	}
+	return nil
+}
+
+// ticket returns the kitchen state of an order of the restaurant. The
+// caller must hold mu.
+func (s *Store) ticket(restaurantID, orderID int) (ticket, bool) {
+	t, ok := s.tickets[orderID]
+	if !ok || s.orders[orderID].RestaurantID != restaurantID {
+		return ticket{}, false
+	}
+	return t, true
+}
+
+// ticketItem returns an order item that has a ticket at the restaurant. The
+// caller must hold mu.
+func (s *Store) ticketItem(restaurantID, itemID int) (models.OrderItem, bool) {
+	for orderID := range s.tickets {
+		order := s.orders[orderID]
+		if order.RestaurantID != restaurantID {
+			continue
+		}
+		for _, item := range order.AllItems() {
+			if item.ID == itemID {
+				return item, true
+			}
+		}
+	}
+	return models.OrderItem{}, false
+}
+
+// kitchenTicket builds the ticket of an order. The caller must hold mu.
+func (s *Store) kitchenTicket(orderID int, t ticket) models.KitchenTicket {
+	kitchenTicket := models.KitchenTicket{
+		OrderID:      orderID,
+		RestaurantID: s.orders[orderID].RestaurantID,
+		Priority:     t.priority,
+		CreatedAt:    t.createdAt,
+		BumpedAt:     t.bumpedAt,
+	}
+	for _, item := range s.orders[orderID].AllItems() {
+		kitchenTicket.Items = append(kitchenTicket.Items, s.kitchenItem(item))
+	}
+	return kitchenTicket
+}
+
+// kitchenItem builds the kitchen view of an order item. The caller must hold
+// mu.
+func (s *Store) kitchenItem(item models.OrderItem) models.KitchenItem {
+	foodItem := s.foodItems[item.FoodItemID]
+	p := s.progress[item.ID]
+	var modifiers []string
+	for _, modifier := range item.Modifiers {
+		modifiers = append(modifiers, modifier.Name)
+	}
+	return models.KitchenItem{
+		ID:         item.ID,
+		FoodItemID: item.FoodItemID,
+		Name:       foodItem.Name,
+		Variant:    item.Variant,
+		Modifiers:  modifiers,
+		Station:    foodItem.Station,
+		Quantity:   item.Quantity,
+		StartedAt:  p.startedAt,
+		DoneAt:     p.doneAt,
+	}
+}
+
+// copyFoodItem copies a food item so that its variants and modifier groups
+// are not shared with the store
+func copyFoodItem(item models.FoodItem) models.FoodItem {
+	item.Variants = append([]models.Variant(nil), item.Variants...)
+	groups := item.ModifierGroups
+	item.ModifierGroups = nil
+	for _, group := range groups {
+		group.Modifiers = append([]models.Modifier(nil), group.Modifiers...)
+		item.ModifierGroups = append(item.ModifierGroups, group)
+	}
+	return item
+}
+
+// copyBundle copies a bundle so that its slots and options are not shared
+// with the store
+func copyBundle(bundle models.Bundle) models.Bundle {
+	slots := bundle.Slots
+	bundle.Slots = nil
+	for _, slot := range slots {
+		slot.Options = append([]models.BundleOption(nil), slot.Options...)
+		bundle.Slots = append(bundle.Slots, slot)
+	}
+	return bundle
+}
+
+// copyOrder copies an order so that its items and bundles are not shared
+// with the store
+func copyOrder(order models.Order) models.Order {
+	order.OrderItems = append([]models.OrderItem{}, order.OrderItems...)
+	bundles := order.Bundles
+	order.Bundles = nil
+	for _, bundle := range bundles {
+		bundle.Items = append([]models.OrderItem(nil), bundle.Items...)
+		order.Bundles = append(order.Bundles, bundle)
+	}
+	return order
+}
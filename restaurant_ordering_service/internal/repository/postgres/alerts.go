@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/restaurant_ordering_service/internal/models"
+)
+
+// AlertRepo maintains the stock_alerts table
+type AlertRepo struct {
+	conn
+}
+
+// alertColumns are the columns scanned by scanAlert
+const alertColumns = `id, restaurant_id, type, COALESCE(ingredient_id, 0), COALESCE(food_item_id, 0), COALESCE(variant_id, 0),
+	name, variant, unit, quantity, low_stock_threshold, created_at, acknowledged_at, COALESCE(acknowledged_by, 0)`
+
+// Create inserts an alert, setting its ID and CreatedAt
+func (r *AlertRepo) Create(ctx context.Context, alert *models.StockAlert) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.q.QueryRowContext(ctx,
+		`INSERT INTO stock_alerts (restaurant_id, type, ingredient_id, food_item_id, variant_id, name, variant, unit,
+			quantity, low_stock_threshold, acknowledged_at, acknowledged_by)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, 0), NULLIF($5, 0), $6, $7, $8, $9, $10, $11, NULLIF($12, 0))
+		RETURNING id, created_at`,
+		alert.RestaurantID, alert.Type, alert.IngredientID, alert.FoodItemID, alert.VariantID, alert.Name, alert.Variant, alert.Unit,
+		alert.Quantity, alert.LowStockThreshold, alert.AcknowledgedAt, alert.AcknowledgedBy,
+	).Scan(&alert.ID, &alert.CreatedAt)
+}
+
+// List returns the latest alerts of a restaurant, newest first
+func (r *AlertRepo) List(ctx context.Context, restaurantID int, openOnly bool, limit int) ([]models.StockAlert, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT "+alertColumns+` FROM stock_alerts
+		WHERE restaurant_id = $1 AND (NOT $2 OR acknowledged_at IS NULL)
+		ORDER BY id DESC
+		LIMIT $3`,
+		restaurantID, openOnly, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []models.StockAlert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// Acknowledge closes an open alert on behalf of a staff member and returns
+// it. An alert that is already closed keeps who closed it and when.
+func (r *AlertRepo) Acknowledge(ctx context.Context, restaurantID, id, userID int) (models.StockAlert, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	row := r.q.QueryRowContext(ctx,
+		`UPDATE stock_alerts SET
+			acknowledged_by = CASE WHEN acknowledged_at IS NULL THEN $1 ELSE acknowledged_by END,
+			acknowledged_at = COALESCE(acknowledged_at, CURRENT_TIMESTAMP)
+		WHERE id = $2 AND restaurant_id = $3
+		RETURNING `+alertColumns,
+		userID, id, restaurantID,
+	)
+	alert, err := scanAlert(row)
+	return alert, notFound(err)
+}
+
+// Resolve closes the open alerts of an ingredient, or of a food item or its
+// variant
+func (r *AlertRepo) Resolve(ctx context.Context, restaurantID, ingredientID, foodItemID, variantID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.q.ExecContext(ctx,
+		`UPDATE stock_alerts SET acknowledged_at = CURRENT_TIMESTAMP
+		WHERE restaurant_id = $1 AND acknowledged_at IS NULL
+		AND COALESCE(ingredient_id, 0) = $2 AND COALESCE(food_item_id, 0) = $3 AND COALESCE(variant_id, 0) = $4`,
+		restaurantID, ingredientID, foodItemID, variantID,
+	)
+	return err
+}
+
+// scanAlert scans the alertColumns of a row
+func scanAlert(row interface{ Scan(dest ...any) error }) (models.StockAlert, error) {
+	var alert models.StockAlert
+	var acknowledgedAt sql.NullTime
+	err := row.Scan(&alert.ID, &alert.RestaurantID, &alert.Type, &alert.IngredientID, &alert.FoodItemID, &alert.VariantID,
+		&alert.Name, &alert.Variant, &alert.Unit, &alert.Quantity, &alert.LowStockThreshold, &alert.CreatedAt,
+		&acknowledgedAt, &alert.AcknowledgedBy)
+	alert.AcknowledgedAt = timePtr(acknowledgedAt)
+	return alert, err
+}
@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// InventoryRepo maintains the ingredients table, the recipes in
+// food_item_ingredients and modifier_ingredients, and stock_movements
+type InventoryRepo struct {
+	conn
+}
+
+// ListIngredients returns the ingredients of a restaurant ordered by name
+func (r *InventoryRepo) ListIngredients(ctx context.Context, restaurantID int) ([]models.Ingredient, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT id, restaurant_id, name, unit, quantity, low_stock_threshold FROM ingredients WHERE restaurant_id = $1 ORDER BY name",
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ingredients []models.Ingredient
+	for rows.Next() {
+		var ingredient models.Ingredient
+		err := rows.Scan(&ingredient.ID, &ingredient.RestaurantID, &ingredient.Name, &ingredient.Unit, &ingredient.Quantity, &ingredient.LowStockThreshold)
+		if err != nil {
+			return nil, err
+		}
+		ingredients = append(ingredients, ingredient)
+	}
+	return ingredients, rows.Err()
+}
+
+// GetIngredient returns a single ingredient of a restaurant
+func (r *InventoryRepo) GetIngredient(ctx context.Context, restaurantID, id int) (models.Ingredient, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var ingredient models.Ingredient
+	err := r.q.QueryRowContext(ctx,
+		"SELECT id, restaurant_id, name, unit, quantity, low_stock_threshold FROM ingredients WHERE id = $1 AND restaurant_id = $2",
+		id, restaurantID,
+	).Scan(&ingredient.ID, &ingredient.RestaurantID, &ingredient.Name, &ingredient.Unit, &ingredient.Quantity, &ingredient.LowStockThreshold)
+	return ingredient, notFound(err)
+}
+
+// CreateIngredient inserts an ingredient, setting its ID
+func (r *InventoryRepo) CreateIngredient(ctx context.Context, ingredient *models.Ingredient) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err := r.q.QueryRowContext(ctx,
+		"INSERT INTO ingredients (restaurant_id, name, unit, quantity, low_stock_threshold) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+		ingredient.RestaurantID, ingredient.Name, ingredient.Unit, ingredient.Quantity, ingredient.LowStockThreshold,
+	).Scan(&ingredient.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return repository.ErrDuplicate
+	}
+	return err
+}
+
+// SetThreshold changes the low-stock threshold of an ingredient
+func (r *InventoryRepo) SetThreshold(ctx context.Context, restaurantID, id int, threshold float64) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE ingredients SET low_stock_threshold = $1 WHERE id = $2 AND restaurant_id = $3",
+		threshold, id, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// AdjustStock changes the stock of an ingredient and records the movement.
+// The update is conditional so that stock never goes negative.
+func (r *InventoryRepo) AdjustStock(ctx context.Context, restaurantID int, movement *models.StockMovement) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err := r.q.QueryRowContext(ctx,
+		`UPDATE ingredients SET quantity = quantity + $1
+		WHERE id = $2 AND restaurant_id = $3 AND quantity + $1 >= 0
+		RETURNING quantity`,
+		movement.Change, movement.IngredientID, restaurantID,
+	).Scan(&movement.QuantityAfter)
+	if err == sql.ErrNoRows {
+		return repository.ErrInsufficientStock
+	}
+	if err != nil {
+		return err
+	}
+
+	return r.q.QueryRowContext(ctx,
+		`INSERT INTO stock_movements (ingredient_id, change, quantity_after, reason, order_id, user_id, note)
+		VALUES ($1, $2, $3, $4, NULLIF($5, 0), NULLIF($6, 0), $7) RETURNING id, created_at`,
+		movement.IngredientID, movement.Change, movement.QuantityAfter, movement.Reason, movement.OrderID, movement.UserID, movement.Note,
+	).Scan(&movement.ID, &movement.CreatedAt)
+}
+
+// Movements returns the latest stock movements of a restaurant, newest first
+func (r *InventoryRepo) Movements(ctx context.Context, restaurantID, ingredientID, limit int) ([]models.StockMovement, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT m.id, m.ingredient_id, m.change, m.quantity_after, m.reason, COALESCE(m.order_id, 0), COALESCE(m.user_id, 0), m.note, m.created_at
+		FROM stock_movements m
+		JOIN ingredients i ON i.id = m.ingredient_id
+		WHERE i.restaurant_id = $1 AND ($2 = 0 OR m.ingredient_id = $2)
+		ORDER BY m.id DESC
+		LIMIT $3`,
+		restaurantID, ingredientID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []models.StockMovement
+	for rows.Next() {
+		var movement models.StockMovement
+		err := rows.Scan(&movement.ID, &movement.IngredientID, &movement.Change, &movement.QuantityAfter, &movement.Reason,
+			&movement.OrderID, &movement.UserID, &movement.Note, &movement.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		movements = append(movements, movement)
+	}
+	return movements, rows.Err()
+}
+
+// Recipes returns the recipes of the food items and modifiers of a
+// restaurant
+func (r *InventoryRepo) Recipes(ctx context.Context, restaurantID int) ([]models.Recipe, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT r.food_item_id, 0, i.id, i.name, i.unit, r.quantity
+		FROM food_item_ingredients r
+		JOIN ingredients i ON i.id = r.ingredient_id
+		WHERE i.restaurant_id = $1
+		UNION ALL
+		SELECT 0, r.modifier_id, i.id, i.name, i.unit, r.quantity
+		FROM modifier_ingredients r
+		JOIN ingredients i ON i.id = r.ingredient_id
+		WHERE i.restaurant_id = $1
+		ORDER BY 2, 1, 3`,
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipes []models.Recipe
+	for rows.Next() {
+		var recipe models.Recipe
+		var ingredient models.RecipeIngredient
+		err := rows.Scan(&recipe.FoodItemID, &recipe.ModifierID, &ingredient.IngredientID, &ingredient.Name, &ingredient.Unit, &ingredient.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows of the same recipe are adjacent
+		if n := len(recipes); n == 0 || recipes[n-1].FoodItemID != recipe.FoodItemID || recipes[n-1].ModifierID != recipe.ModifierID {
+			recipes = append(recipes, recipe)
+		}
+		current := &recipes[len(recipes)-1]
+		current.Ingredients = append(current.Ingredients, ingredient)
+	}
+	return recipes, rows.Err()
+}
+
+// SetRecipe replaces the recipe of a food item or modifier of a restaurant
+func (r *InventoryRepo) SetRecipe(ctx context.Context, restaurantID int, recipe models.Recipe) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	table, column, id := "food_item_ingredients", "food_item_id", recipe.FoodItemID
+	if recipe.ModifierID != 0 {
+		table, column, id = "modifier_ingredients", "modifier_id", recipe.ModifierID
+	}
+
+	_, err := r.q.ExecContext(ctx,
+		"DELETE FROM "+table+" WHERE "+column+" = $1 AND ingredient_id IN (SELECT id FROM ingredients WHERE restaurant_id = $2)",
+		id, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, ingredient := range recipe.Ingredients {
+		_, err := r.q.ExecContext(ctx,
+			"INSERT INTO "+table+" ("+column+", ingredient_id, quantity) VALUES ($1, $2, $3)",
+			id, ingredient.IngredientID, ingredient.Quantity,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
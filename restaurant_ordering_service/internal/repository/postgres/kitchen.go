@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// KitchenRepo stores tickets in the kitchen_tickets table and the
+// preparation of their items in order_items
+type KitchenRepo struct {
+	conn
+}
+
+// itemModifiers selects the names of the modifiers of order item i as an
+// array
+const itemModifiers = `ARRAY(SELECT m.name FROM order_item_modifiers m WHERE m.order_item_id = i.id ORDER BY m.modifier_id)`
+
+// ticketQuery selects tickets joined with their items, one row per item.
+// The restaurant of the order is the first parameter.
+const ticketQuery = `SELECT t.order_id, o.restaurant_id, t.priority, t.created_at, t.bumped_at,
+	i.id, i.food_item_id, f.name, i.variant_name, ` + itemModifiers + `, f.station, i.quantity, i.started_at, i.done_at
+FROM kitchen_tickets t
+JOIN orders o ON o.id = t.order_id
+JOIN order_items i ON i.order_id = t.order_id
+JOIN food_items f ON f.id = i.food_item_id
+WHERE o.restaurant_id = $1`
+
+// CreateTicket opens the ticket of a paid order
+func (r *KitchenRepo) CreateTicket(ctx context.Context, orderID int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.q.ExecContext(ctx, "INSERT INTO kitchen_tickets (order_id) VALUES ($1)", orderID)
+	return err
+}
+
+// Queue returns the tickets that have not been bumped, highest priority and
+// then oldest first, with their items
+func (r *KitchenRepo) Queue(ctx context.Context, restaurantID int) ([]models.KitchenTicket, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		ticketQuery+" AND t.bumped_at IS NULL ORDER BY t.priority DESC, t.created_at, t.order_id, i.id",
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTickets(rows)
+}
+
+// GetTicket returns a ticket with its items, bumped or not
+func (r *KitchenRepo) GetTicket(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx, ticketQuery+" AND t.order_id = $2 ORDER BY i.id", restaurantID, orderID)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	tickets, err := scanTickets(rows)
+	if err != nil {
+		return models.KitchenTicket{}, err
+	}
+	if len(tickets) == 0 {
+		return models.KitchenTicket{}, repository.ErrNotFound
+	}
+	return tickets[0], nil
+}
+
+// GetItem returns an order item that has a ticket
+func (r *KitchenRepo) GetItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var item models.KitchenItem
+	var startedAt, doneAt sql.NullTime
+	err := r.q.QueryRowContext(ctx,
+		`SELECT i.id, i.food_item_id, f.name, i.variant_name, `+itemModifiers+`, f.station, i.quantity, i.started_at, i.done_at
+		FROM order_items i
+		JOIN food_items f ON f.id = i.food_item_id
+		JOIN kitchen_tickets t ON t.order_id = i.order_id
+		JOIN orders o ON o.id = i.order_id
+		WHERE i.id = $1 AND o.restaurant_id = $2`,
+		itemID, restaurantID,
+	).Scan(&item.ID, &item.FoodItemID, &item.Name, &item.Variant, pq.Array(&item.Modifiers), &item.Station, &item.Quantity, &startedAt, &doneAt)
+	if err != nil {
+		return item, notFound(err)
+	}
+	item.StartedAt, item.DoneAt = timePtr(startedAt), timePtr(doneAt)
+	return item, nil
+}
+
+// StartItem marks an item as started, only if it was not already
+func (r *KitchenRepo) StartItem(ctx context.Context, restaurantID, itemID int) error {
+	return r.update(ctx, repository.ErrKitchenStateChanged,
+		`UPDATE order_items SET started_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND started_at IS NULL
+		AND order_id IN (SELECT id FROM orders WHERE restaurant_id = $2)`,
+		itemID, restaurantID,
+	)
+}
+
+// FinishItem marks an item as done, only if it was started and not already
+// done
+func (r *KitchenRepo) FinishItem(ctx context.Context, restaurantID, itemID int) error {
+	return r.update(ctx, repository.ErrKitchenStateChanged,
+		`UPDATE order_items SET done_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND started_at IS NOT NULL AND done_at IS NULL
+		AND order_id IN (SELECT id FROM orders WHERE restaurant_id = $2)`,
+		itemID, restaurantID,
+	)
+}
+
+// SetPriority changes the priority of a ticket
+func (r *KitchenRepo) SetPriority(ctx context.Context, restaurantID, orderID, priority int) error {
+	return r.update(ctx, repository.ErrNotFound,
+		`UPDATE kitchen_tickets SET priority = $1
+		WHERE order_id = $2 AND order_id IN (SELECT id FROM orders WHERE restaurant_id = $3)`,
+		priority, orderID, restaurantID,
+	)
+}
+
+// Bump takes a ticket off the queue, only if it is on it
+func (r *KitchenRepo) Bump(ctx context.Context, restaurantID, orderID int) error {
+	return r.update(ctx, repository.ErrKitchenStateChanged,
+		`UPDATE kitchen_tickets SET bumped_at = CURRENT_TIMESTAMP
+		WHERE order_id = $1 AND bumped_at IS NULL
+		AND order_id IN (SELECT id FROM orders WHERE restaurant_id = $2)`,
+		orderID, restaurantID,
+	)
+}
+
+// Recall puts a bumped ticket back on the queue
+func (r *KitchenRepo) Recall(ctx context.Context, restaurantID, orderID int) error {
+	return r.update(ctx, repository.ErrKitchenStateChanged,
+		`UPDATE kitchen_tickets SET bumped_at = NULL
+		WHERE order_id = $1 AND bumped_at IS NOT NULL
+		AND order_id IN (SELECT id FROM orders WHERE restaurant_id = $2)`,
+		orderID, restaurantID,
+	)
+}
+
+// PrepTimes returns the average preparation time of each food item over
+// the items that were started and finished
+func (r *KitchenRepo) PrepTimes(ctx context.Context, restaurantID int) ([]models.PrepTime, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT f.id, f.name, f.station, COUNT(*), AVG(EXTRACT(EPOCH FROM i.done_at - i.started_at))
+		FROM order_items i
+		JOIN food_items f ON f.id = i.food_item_id
+		WHERE i.done_at IS NOT NULL AND f.restaurant_id = $1
+		GROUP BY f.id, f.name, f.station
+		ORDER BY f.id`,
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prepTimes []models.PrepTime
+	for rows.Next() {
+		var prepTime models.PrepTime
+		if err := rows.Scan(&prepTime.FoodItemID, &prepTime.Name, &prepTime.Station, &prepTime.Prepared, &prepTime.AverageSeconds); err != nil {
+			return nil, err
+		}
+		prepTimes = append(prepTimes, prepTime)
+	}
+	return prepTimes, rows.Err()
+}
+
+// update runs a statement expected to change a single row, returning
+// errNone if it changed nothing
+func (r *KitchenRepo) update(ctx context.Context, errNone error, query string, args ...any) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errNone
+	}
+	return nil
+}
+
+// scanTickets reads the rows of ticketQuery, which must be ordered so that
+// the items of a ticket are adjacent
+func scanTickets(rows *sql.Rows) ([]models.KitchenTicket, error) {
+	defer rows.Close()
+
+	var tickets []models.KitchenTicket
+	for rows.Next() {
+		var ticket models.KitchenTicket
+		var item models.KitchenItem
+		var bumpedAt, startedAt, doneAt sql.NullTime
+		err := rows.Scan(&ticket.OrderID, &ticket.RestaurantID, &ticket.Priority, &ticket.CreatedAt, &bumpedAt,
+			&item.ID, &item.FoodItemID, &item.Name, &item.Variant, pq.Array(&item.Modifiers), &item.Station, &item.Quantity, &startedAt, &doneAt)
+		if err != nil {
+			return nil, err
+		}
+		item.StartedAt, item.DoneAt = timePtr(startedAt), timePtr(doneAt)
+
+		if n := len(tickets); n > 0 && tickets[n-1].OrderID == ticket.OrderID {
+			tickets[n-1].Items = append(tickets[n-1].Items, item)
+			continue
+		}
+		ticket.BumpedAt = timePtr(bumpedAt)
+		ticket.Items = []models.KitchenItem{item}
+		tickets = append(tickets, ticket)
+	}
+	return tickets, rows.Err()
+}
+
+// timePtr converts a nullable timestamp to a pointer, nil for NULL
+func timePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,378 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// MenuRepo reads and updates the food_items table, along with the variants
+// and modifiers of the food items, and reads the bundles
+type MenuRepo struct {
+	conn
+}
+
+// bundleQuery selects bundles joined with their slots and options, one row
+// per option, so bundles without options are left out. The restaurant is
+// the first parameter.
+const bundleQuery = `SELECT b.id, b.restaurant_id, b.name, b.price, s.id, s.name, s.quantity,
+	o.id, o.food_item_id, COALESCE(o.variant_id, 0),
+	CASE WHEN v.id IS NULL THEN f.name ELSE f.name || ' (' || v.name || ')' END,
+	o.price_delta
+FROM bundles b
+JOIN bundle_slots s ON s.bundle_id = b.id
+JOIN bundle_slot_options o ON o.slot_id = s.id
+JOIN food_items f ON f.id = o.food_item_id
+LEFT JOIN food_item_variants v ON v.id = o.variant_id
+WHERE b.restaurant_id = $1`
+
+// List returns every food item of a restaurant
+func (r *MenuRepo) List(ctx context.Context, restaurantID int) ([]models.FoodItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT id, restaurant_id, name, price, quantity, station, low_stock_threshold, hide_when_sold_out FROM food_items WHERE restaurant_id = $1 ORDER BY id",
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foodItems []models.FoodItem
+	for rows.Next() {
+		var item models.FoodItem
+		err := rows.Scan(&item.ID, &item.RestaurantID, &item.Name, &item.Price, &item.Quantity, &item.Station,
+			&item.LowStockThreshold, &item.HideWhenSoldOut)
+		if err != nil {
+			return nil, err
+		}
+		foodItems = append(foodItems, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadOptions(ctx, restaurantID, nil, foodItems); err != nil {
+		return nil, err
+	}
+	return foodItems, nil
+}
+
+// ListForUpdate returns the food items of a restaurant with the given IDs
+// and locks them, in ID order, until the surrounding transaction ends
+func (r *MenuRepo) ListForUpdate(ctx context.Context, restaurantID int, ids []int) ([]models.FoodItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		"SELECT id, restaurant_id, name, price, quantity, station, low_stock_threshold, hide_when_sold_out FROM food_items WHERE restaurant_id = $1 AND id = ANY($2) ORDER BY id FOR UPDATE",
+		restaurantID, pq.Array(int64s(ids)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var foodItems []models.FoodItem
+	for rows.Next() {
+		var item models.FoodItem
+		err := rows.Scan(&item.ID, &item.RestaurantID, &item.Name, &item.Price, &item.Quantity, &item.Station,
+			&item.LowStockThreshold, &item.HideWhenSoldOut)
+		if err != nil {
+			return nil, err
+		}
+		foodItems = append(foodItems, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(foodItems) == 0 {
+		return nil, nil
+	}
+
+	if err := r.loadOptions(ctx, restaurantID, ids, foodItems); err != nil {
+		return nil, err
+	}
+	return foodItems, nil
+}
+
+// Get returns a single food item of a restaurant
+func (r *MenuRepo) Get(ctx context.Context, restaurantID, id int) (models.FoodItem, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var item models.FoodItem
+	err := r.q.QueryRowContext(ctx,
+		"SELECT id, restaurant_id, name, price, quantity, station, low_stock_threshold, hide_when_sold_out FROM food_items WHERE id = $1 AND restaurant_id = $2",
+		id, restaurantID,
+	).Scan(&item.ID, &item.RestaurantID, &item.Name, &item.Price, &item.Quantity, &item.Station, &item.LowStockThreshold, &item.HideWhenSoldOut)
+	if err != nil {
+		return item, notFound(err)
+	}
+
+	foodItems := []models.FoodItem{item}
+	if err := r.loadOptions(ctx, restaurantID, []int{id}, foodItems); err != nil {
+		return item, err
+	}
+	return foodItems[0], nil
+}
+
+// ListBundles returns every bundle of a restaurant
+func (r *MenuRepo) ListBundles(ctx context.Context, restaurantID int) ([]models.Bundle, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx, bundleQuery+" ORDER BY b.id, s.id, o.id", restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	return scanBundles(rows)
+}
+
+// GetBundle returns a single bundle of a restaurant
+func (r *MenuRepo) GetBundle(ctx context.Context, restaurantID, id int) (models.Bundle, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx, bundleQuery+" AND b.id = $2 ORDER BY s.id, o.id", restaurantID, id)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	bundles, err := scanBundles(rows)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	if len(bundles) == 0 {
+		return models.Bundle{}, repository.ErrNotFound
+	}
+	return bundles[0], nil
+}
+
+// DecrementStock removes quantity from the food item's stock. The update is
+// conditional so that stock never goes negative.
+func (r *MenuRepo) DecrementStock(ctx context.Context, restaurantID, id, quantity int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE food_items SET quantity = quantity - $1 WHERE id = $2 AND restaurant_id = $3 AND quantity >= $1",
+		quantity, id, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrInsufficientStock
+	}
+	return nil
+}
+
+// DecrementVariantStock removes quantity from the variant's stock, under
+// the same condition as DecrementStock
+func (r *MenuRepo) DecrementVariantStock(ctx context.Context, restaurantID, variantID, quantity int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		`UPDATE food_item_variants SET quantity = quantity - $1
+		WHERE id = $2 AND quantity >= $1
+		AND food_item_id IN (SELECT id FROM food_items WHERE restaurant_id = $3)`,
+		quantity, variantID, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrInsufficientStock
+	}
+	return nil
+}
+
+// SetStockSettings changes the low-stock threshold of the food item and
+// whether it leaves the menu while sold out
+func (r *MenuRepo) SetStockSettings(ctx context.Context, restaurantID, id, threshold int, hideWhenSoldOut bool) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE food_items SET low_stock_threshold = $1, hide_when_sold_out = $2 WHERE id = $3 AND restaurant_id = $4",
+		threshold, hideWhenSoldOut, id, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Restock adds quantity to the stock of the food item, or of its variant
+// unless variantID is 0
+func (r *MenuRepo) Restock(ctx context.Context, restaurantID, id, variantID, quantity int) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var result sql.Result
+	var err error
+	if variantID == 0 {
+		result, err = r.q.ExecContext(ctx,
+			"UPDATE food_items SET quantity = quantity + $1 WHERE id = $2 AND restaurant_id = $3",
+			quantity, id, restaurantID,
+		)
+	} else {
+		result, err = r.q.ExecContext(ctx,
+			`UPDATE food_item_variants SET quantity = quantity + $1
+			WHERE id = $2 AND food_item_id = $3
+			AND food_item_id IN (SELECT id FROM food_items WHERE restaurant_id = $4)`,
+			quantity, variantID, id, restaurantID,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// loadOptions fills in the variants and modifier groups of food items of a
+// restaurant, reading those of the food items with the given IDs, or of
+// every food item when there are none
+func (r *MenuRepo) loadOptions(ctx context.Context, restaurantID int, foodItemIDs []int, foodItems []models.FoodItem) error {
+	byID := make(map[int]*models.FoodItem, len(foodItems))
+	for i := range foodItems {
+		byID[foodItems[i].ID] = &foodItems[i]
+	}
+
+	// Variants
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT v.food_item_id, v.id, v.name, v.price, v.quantity
+		FROM food_item_variants v
+		JOIN food_items f ON f.id = v.food_item_id
+		WHERE f.restaurant_id = $1 AND (cardinality($2::bigint[]) = 0 OR f.id = ANY($2))
+		ORDER BY v.id`,
+		restaurantID, pq.Array(int64s(foodItemIDs)),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var variant models.Variant
+		if err := rows.Scan(&itemID, &variant.ID, &variant.Name, &variant.Price, &variant.Quantity); err != nil {
+			return err
+		}
+		if item, ok := byID[itemID]; ok {
+			item.Variants = append(item.Variants, variant)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Modifier groups with their modifiers, one row per modifier
+	rows, err = r.q.QueryContext(ctx,
+		`SELECT g.food_item_id, g.id, g.name, g.min_selections, g.max_selections, m.id, m.name, m.price_delta
+		FROM modifier_groups g
+		JOIN modifiers m ON m.group_id = g.id
+		JOIN food_items f ON f.id = g.food_item_id
+		WHERE f.restaurant_id = $1 AND (cardinality($2::bigint[]) = 0 OR f.id = ANY($2))
+		ORDER BY g.id, m.id`,
+		restaurantID, pq.Array(int64s(foodItemIDs)),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var group models.ModifierGroup
+		var modifier models.Modifier
+		err := rows.Scan(&itemID, &group.ID, &group.Name, &group.MinSelections, &group.MaxSelections,
+			&modifier.ID, &modifier.Name, &modifier.PriceDelta)
+		if err != nil {
+			return err
+		}
+		item, ok := byID[itemID]
+		if !ok {
+			continue
+		}
+
+		if n := len(item.ModifierGroups); n > 0 && item.ModifierGroups[n-1].ID == group.ID {
+			item.ModifierGroups[n-1].Modifiers = append(item.ModifierGroups[n-1].Modifiers, modifier)
+			continue
+		}
+		group.Modifiers = []models.Modifier{modifier}
+		item.ModifierGroups = append(item.ModifierGroups, group)
+	}
+	return rows.Err()
+}
+
+// int64s converts IDs for pq.Array, which has no array type for int. The
+// result is never nil, so an empty list is sent as an empty array.
+func int64s(ids []int) []int64 {
+	converted := make([]int64, len(ids))
+	for i, id := range ids {
+		converted[i] = int64(id)
+	}
+	return converted
+}
+
+// scanBundles reads the rows of bundleQuery, which must be ordered so that
+// the options of a slot and the slots of a bundle are adjacent
+func scanBundles(rows *sql.Rows) ([]models.Bundle, error) {
+	defer rows.Close()
+
+	var bundles []models.Bundle
+	for rows.Next() {
+		var bundle models.Bundle
+		var slot models.BundleSlot
+		var option models.BundleOption
+		err := rows.Scan(&bundle.ID, &bundle.RestaurantID, &bundle.Name, &bundle.Price, &slot.ID, &slot.Name, &slot.Quantity,
+			&option.ID, &option.FoodItemID, &option.VariantID, &option.Name, &option.PriceDelta)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := len(bundles); n == 0 || bundles[n-1].ID != bundle.ID {
+			bundles = append(bundles, bundle)
+		}
+		current := &bundles[len(bundles)-1]
+		if n := len(current.Slots); n == 0 || current.Slots[n-1].ID != slot.ID {
+			current.Slots = append(current.Slots, slot)
+		}
+		currentSlot := &current.Slots[len(current.Slots)-1]
+		currentSlot.Options = append(currentSlot.Options, option)
+	}
+	return bundles, rows.Err()
+}
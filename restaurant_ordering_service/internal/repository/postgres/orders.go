@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// OrderRepo stores orders in the orders and order_items tables
+type OrderRepo struct {
+	conn
+}
+
+// Create inserts the order with its items and bundles, setting their IDs
+func (r *OrderRepo) Create(ctx context.Context, order *models.Order) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err := r.q.QueryRowContext(ctx,
+		"INSERT INTO orders (restaurant_id, user_id, tax, total_price, currency, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		order.RestaurantID, order.UserID, order.Tax, order.TotalPrice, order.Currency, order.Status,
+	).Scan(&order.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range order.OrderItems {
+		order.OrderItems[i].OrderID = order.ID
+		if err := r.createItem(ctx, &order.OrderItems[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range order.Bundles {
+		bundle := &order.Bundles[i]
+		bundle.OrderID = order.ID
+		err := r.q.QueryRowContext(ctx,
+			"INSERT INTO order_bundles (order_id, bundle_id, name, quantity, unit_price) VALUES ($1, $2, $3, $4, $5) RETURNING id",
+			bundle.OrderID, bundle.BundleID, bundle.Name, bundle.Quantity, bundle.UnitPrice,
+		).Scan(&bundle.ID)
+		if err != nil {
+			return err
+		}
+
+		for j := range bundle.Items {
+			bundle.Items[j].OrderID, bundle.Items[j].OrderBundleID = order.ID, bundle.ID
+			if err := r.createItem(ctx, &bundle.Items[j]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createItem inserts an order item with its modifiers, setting its ID
+func (r *OrderRepo) createItem(ctx context.Context, item *models.OrderItem) error {
+	err := r.q.QueryRowContext(ctx,
+		`INSERT INTO order_items (order_id, order_bundle_id, food_item_id, variant_id, variant_name, quantity, unit_price)
+		VALUES ($1, NULLIF($2, 0), $3, NULLIF($4, 0), $5, $6, $7) RETURNING id`,
+		item.OrderID, item.OrderBundleID, item.FoodItemID, item.VariantID, item.Variant, item.Quantity, item.UnitPrice,
+	).Scan(&item.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, modifier := range item.Modifiers {
+		_, err := r.q.ExecContext(ctx,
+			"INSERT INTO order_item_modifiers (order_item_id, modifier_id, name, price_delta) VALUES ($1, $2, $3, $4)",
+			item.ID, modifier.ModifierID, modifier.Name, modifier.PriceDelta,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the order with its items and bundles
+func (r *OrderRepo) Get(ctx context.Context, id int) (models.Order, error) {
+	return r.get(ctx, id, "SELECT id, restaurant_id, user_id, tax, total_price, currency, status FROM orders WHERE id = $1")
+}
+
+// GetForUpdate returns the order with its items and bundles, holding a row lock on the
+// order until the transaction ends so concurrent payments are serialized
+func (r *OrderRepo) GetForUpdate(ctx context.Context, id int) (models.Order, error) {
+	return r.get(ctx, id, "SELECT id, restaurant_id, user_id, tax, total_price, currency, status FROM orders WHERE id = $1 FOR UPDATE")
+}
+
+func (r *OrderRepo) get(ctx context.Context, id int, query string) (models.Order, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var order models.Order
+	err := r.q.QueryRowContext(ctx, query, id).
+		Scan(&order.ID, &order.RestaurantID, &order.UserID, &order.Tax, &order.TotalPrice, &order.Currency, &order.Status)
+	if err != nil {
+		return order, notFound(err)
+	}
+
+	// Items of the order and of its bundles, split up once their modifiers
+	// are loaded
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT id, order_id, COALESCE(order_bundle_id, 0), food_item_id, COALESCE(variant_id, 0), variant_name, quantity, unit_price
+		FROM order_items WHERE order_id = $1 ORDER BY id`,
+		id,
+	)
+	if err != nil {
+		return order, err
+	}
+	defer rows.Close()
+
+	var items []models.OrderItem
+	itemIndex := make(map[int]int)
+	for rows.Next() {
+		var item models.OrderItem
+		err := rows.Scan(&item.ID, &item.OrderID, &item.OrderBundleID, &item.FoodItemID, &item.VariantID, &item.Variant, &item.Quantity, &item.UnitPrice)
+		if err != nil {
+			return order, err
+		}
+		itemIndex[item.ID] = len(items)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return order, err
+	}
+
+	// Modifiers of the items
+	rows, err = r.q.QueryContext(ctx,
+		`SELECT m.order_item_id, m.modifier_id, m.name, m.price_delta
+		FROM order_item_modifiers m
+		JOIN order_items i ON i.id = m.order_item_id
+		WHERE i.order_id = $1
+		ORDER BY m.order_item_id, m.modifier_id`,
+		id,
+	)
+	if err != nil {
+		return order, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var itemID int
+		var modifier models.OrderItemModifier
+		if err := rows.Scan(&itemID, &modifier.ModifierID, &modifier.Name, &modifier.PriceDelta); err != nil {
+			return order, err
+		}
+		item := &items[itemIndex[itemID]]
+		item.Modifiers = append(item.Modifiers, modifier)
+	}
+	if err := rows.Err(); err != nil {
+		return order, err
+	}
+
+	// Bundles
+	rows, err = r.q.QueryContext(ctx,
+		"SELECT id, order_id, bundle_id, name, quantity, unit_price FROM order_bundles WHERE order_id = $1 ORDER BY id",
+		id,
+	)
+	if err != nil {
+		return order, err
+	}
+	defer rows.Close()
+
+	bundleIndex := make(map[int]int)
+	for rows.Next() {
+		var bundle models.OrderBundle
+		if err := rows.Scan(&bundle.ID, &bundle.OrderID, &bundle.BundleID, &bundle.Name, &bundle.Quantity, &bundle.UnitPrice); err != nil {
+			return order, err
+		}
+		bundleIndex[bundle.ID] = len(order.Bundles)
+		order.Bundles = append(order.Bundles, bundle)
+	}
+	if err := rows.Err(); err != nil {
+		return order, err
+	}
+
+	order.OrderItems = make([]models.OrderItem, 0, len(items))
+	for _, item := range items {
+		if item.OrderBundleID == 0 {
+			order.OrderItems = append(order.OrderItems, item)
+			continue
+		}
+		bundle := &order.Bundles[bundleIndex[item.OrderBundleID]]
+		bundle.Items = append(bundle.Items, item)
+	}
+	return order, nil
+}
+
+// UpdateStatus changes the status of an order only if it is still in status
+// from, so a stale read can never overwrite a concurrent transition
+func (r *OrderRepo) UpdateStatus(ctx context.Context, id int, from, to string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE orders SET status = $1 WHERE id = $2 AND status = $3",
+		to, id, from,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrStatusChanged
+	}
+	return nil
+}
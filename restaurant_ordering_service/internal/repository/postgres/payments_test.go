@@ -0,0 +1,273 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/migrations"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/restaurant_ordering_service/internal/service"
+	"github.com/shared/apperrors"
+)
+
+// testDatabaseURL names the environment variable with the connection string
+// of a Postgres database the tests may create schemas in. The tests that
+// need Postgres are skipped without it.
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+// openTestDB migrates a new schema of the test database and returns a pool
+// whose connections use it. The schema is dropped when the test ends.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseURL)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURL)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		admin.Close()
+	})
+
+	// lib/pq passes unknown settings on to the server as run-time parameters
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+	db, err := sql.Open("postgres", dsn+separator+"search_path="+schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return db
+}
+
+// payFixture pays for orders of the default restaurant over Postgres
+type payFixture struct {
+	db         *sql.DB
+	uow        *UnitOfWork
+	orders     *service.Orders
+	restaurant models.Restaurant
+	userID     int
+}
+
+func newPayFixture(t *testing.T) *payFixture {
+	t.Helper()
+
+	f := &payFixture{db: openTestDB(t)}
+	f.uow = NewUnitOfWork(f.db, 5*time.Second, 10*time.Second)
+	repos := NewRepos(f.db, 5*time.Second)
+
+	var err error
+	f.restaurant, err = repos.Restaurants.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.db.QueryRow("INSERT INTO users (username, password, email) VALUES ('customer', 'secret', 'customer@example.com') RETURNING id").
+		Scan(&f.userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inventory := service.NewInventory(f.uow, repos.Inventory, repos.Menu, repos.Alerts, func(context.Context, []models.InventoryEvent) {})
+	f.orders = service.NewOrders(f.uow, repos.Orders, inventory, func(context.Context, models.Order, map[int]string) {})
+	return f
+}
+
+// addFoodItem inserts a food item of the restaurant with quantity in stock
+func (f *payFixture) addFoodItem(t *testing.T, name string, quantity int) int {
+	t.Helper()
+
+	var id int
+	err := f.db.QueryRow("INSERT INTO food_items (restaurant_id, name, price, quantity) VALUES ($1, $2, 10, $3) RETURNING id",
+		f.restaurant.ID, name, quantity).Scan(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+// place places an order of the items for the customer
+func (f *payFixture) place(t *testing.T, items ...models.OrderItemRequest) models.Order {
+	t.Helper()
+
+	order, err := f.orders.Place(context.Background(), f.userID, f.restaurant, models.OrderRequest{Items: items})
+	if err != nil {
+		t.Fatalf("Place() error = %v", err)
+	}
+	return order
+}
+
+// stock returns the quantity of a food item left in stock
+func (f *payFixture) stock(t *testing.T, foodItemID int) int {
+	t.Helper()
+
+	var quantity int
+	if err := f.db.QueryRow("SELECT quantity FROM food_items WHERE id = $1", foodItemID).Scan(&quantity); err != nil {
+		t.Fatal(err)
+	}
+	return quantity
+}
+
+func TestPayOrderConcurrently(t *testing.T) {
+	f := newPayFixture(t)
+	dosa := f.addFoodItem(t, "Dosa", 10)
+	order := f.place(t, models.OrderItemRequest{FoodItemID: dosa, Quantity: 3})
+
+	// Every payment of the order races the others; only one may complete
+	// it and take its items out of stock
+	const payments = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, payments)
+	for i := 0; i < payments; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.orders.Pay(context.Background(), f.userID, order.ID)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	paid := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			paid++
+		case !apperrors.HasCode(err, apperrors.CodeOrderNotPending):
+			t.Errorf("Pay() error = %v, want nil or %s", err, apperrors.CodeOrderNotPending)
+		}
+	}
+	if paid != 1 {
+		t.Errorf("%d payments succeeded, want 1", paid)
+	}
+	if got := f.stock(t, dosa); got != 7 {
+		t.Errorf("stock = %d, want 7", got)
+	}
+}
+
+func TestPayOverlappingOrdersConcurrently(t *testing.T) {
+	f := newPayFixture(t)
+	dosa := f.addFoodItem(t, "Dosa", 100)
+	vada := f.addFoodItem(t, "Vada", 100)
+
+	// The orders share both items, listed in either order, so that paying
+	// them would take the same rows in opposite orders without the locks
+	// being taken in ID order
+	const orders = 20
+	var placed []models.Order
+	for i := 0; i < orders; i++ {
+		items := []models.OrderItemRequest{{FoodItemID: dosa, Quantity: 2}, {FoodItemID: vada, Quantity: 3}}
+		if i%2 == 1 {
+			items[0], items[1] = items[1], items[0]
+		}
+		placed = append(placed, f.place(t, items...))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, orders)
+	for _, order := range placed {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, err := f.orders.Pay(context.Background(), f.userID, id)
+			errs <- err
+		}(order.ID)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Pay() error = %v", err)
+		}
+	}
+	if got := f.stock(t, dosa); got != 100-2*orders {
+		t.Errorf("stock of Dosa = %d, want %d", got, 100-2*orders)
+	}
+	if got := f.stock(t, vada); got != 100-3*orders {
+		t.Errorf("stock of Vada = %d, want %d", got, 100-3*orders)
+	}
+}
+
+func TestGetForUpdateWaitsForTheLockingTransaction(t *testing.T) {
+	f := newPayFixture(t)
+	dosa := f.addFoodItem(t, "Dosa", 10)
+	order := f.place(t, models.OrderItemRequest{FoodItemID: dosa, Quantity: 1})
+
+	locked, release := make(chan struct{}), make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- f.uow.Do(context.Background(), func(repos repository.Repos) error {
+			if _, err := repos.Orders.GetForUpdate(context.Background(), order.ID); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return repos.Orders.UpdateStatus(context.Background(), order.ID, "pending", "completed")
+		})
+	}()
+	<-locked
+
+	// The second transaction reads the order only once the first commits,
+	// and so sees it completed and cannot move it out of pending again
+	read := make(chan models.Order, 1)
+	second := make(chan error, 1)
+	go func() {
+		second <- f.uow.Do(context.Background(), func(repos repository.Repos) error {
+			order, err := repos.Orders.GetForUpdate(context.Background(), order.ID)
+			if err != nil {
+				return err
+			}
+			read <- order
+			return repos.Orders.UpdateStatus(context.Background(), order.ID, "pending", "completed")
+		})
+	}()
+
+	select {
+	case <-read:
+		t.Fatal("GetForUpdate() returned while another transaction held the lock")
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("first transaction: %v", err)
+	}
+
+	if got := <-read; got.Status != "completed" {
+		t.Errorf("Status = %q after waiting for the lock, want completed", got.Status)
+	}
+	if err := <-second; !errors.Is(err, repository.ErrStatusChanged) {
+		t.Errorf("second transaction error = %v, want %v", err, repository.ErrStatusChanged)
+	}
+}
@@ -0,0 +1,139 @@
+// Package postgres implements the repositories on top of database/sql
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/logging"
+)
+
+// Postgres error codes for transactions that can safely be retried
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// uniqueViolation is the Postgres error code of a duplicate key
+const uniqueViolation = "23505"
+
+const (
+	// maxAttempts is how often a unit of work is tried before giving up
+	maxAttempts = 3
+
+	// retryBackoff is the base delay between attempts, grown linearly and
+	// jittered so that competing transactions do not collide again
+	retryBackoff = 20 * time.Millisecond
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx, so the same repository
+// code runs inside and outside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn is the connection or transaction a repository runs on, together with
+// the timeout applied to each of its calls
+type conn struct {
+	q       querier
+	timeout time.Duration
+}
+
+// withTimeout bounds a single repository call
+func (c conn) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// NewRepos returns repositories that run each statement on its own, giving
+// up on any call that takes longer than queryTimeout
+func NewRepos(db *sql.DB, queryTimeout time.Duration) repository.Repos {
+	return reposFor(conn{q: db, timeout: queryTimeout})
+}
+
+func reposFor(c conn) repository.Repos {
+	return repository.Repos{
+		Restaurants: &RestaurantRepo{c},
+		Users:       &UserRepo{c},
+		Menu:        &MenuRepo{c},
+		Orders:      &OrderRepo{c},
+		Kitchen:     &KitchenRepo{c},
+		Inventory:   &InventoryRepo{c},
+		Alerts:      &AlertRepo{c},
+	}
+}
+
+// UnitOfWork runs functions inside a database transaction
+type UnitOfWork struct {
+	db           *sql.DB
+	queryTimeout time.Duration
+	txTimeout    time.Duration
+}
+
+// NewUnitOfWork creates a unit of work backed by db. Each repository call is
+// bounded by queryTimeout and the whole transaction by txTimeout.
+func NewUnitOfWork(db *sql.DB, queryTimeout, txTimeout time.Duration) *UnitOfWork {
+	return &UnitOfWork{db: db, queryTimeout: queryTimeout, txTimeout: txTimeout}
+}
+
+// Do runs fn with repositories bound to a new transaction. The transaction
+// is rolled back if ctx is cancelled, for example when the client
+// disconnects. Transactions aborted by a serialization failure or deadlock
+// are retried with a short backoff.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	for attempt := 1; ; attempt++ {
+		err := u.do(ctx, fn)
+		if err == nil || attempt == maxAttempts || !retryable(err) {
+			return err
+		}
+
+		backoff := time.Duration(attempt)*retryBackoff + rand.N(retryBackoff)
+		logging.For(logging.ComponentDB).WarnContext(ctx, "Retrying transaction",
+			"attempt", attempt, "backoff", backoff.String(), "error", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// do runs a single attempt of a unit of work
+func (u *UnitOfWork) do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	ctx, cancel := context.WithTimeout(ctx, u.txTimeout)
+	defer cancel()
+
+	tx, err := u.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(reposFor(conn{q: tx, timeout: u.queryTimeout})); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// retryable reports whether err aborted the transaction in a way that
+// succeeds when the transaction is simply run again
+func retryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
+
+// notFound translates sql.ErrNoRows into repository.ErrNotFound
+func notFound(err error) error {
+	if err == sql.ErrNoRows {
+		return repository.ErrNotFound
+	}
+	return err
+}
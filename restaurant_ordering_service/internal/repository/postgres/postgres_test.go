@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// txDriver is a database/sql driver whose connections only begin, commit and
+// roll back transactions, counting each
+type txDriver struct {
+	mu        sync.Mutex
+	commits   int
+	rollbacks int
+}
+
+func (d *txDriver) Open(string) (driver.Conn, error) { return &txConn{d: d}, nil }
+
+type txConn struct{ d *txDriver }
+
+func (c *txConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *txConn) Close() error              { return nil }
+func (c *txConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *txConn) Commit() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.commits++
+	return nil
+}
+
+func (c *txConn) Rollback() error {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.rollbacks++
+	return nil
+}
+
+// newTxDB opens a database on a new txDriver
+func newTxDB(t *testing.T, name string) (*sql.DB, *txDriver) {
+	t.Helper()
+
+	d := &txDriver{}
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
+
+func TestUnitOfWorkRetries(t *testing.T) {
+	db, d := newTxDB(t, "tx-retries")
+	uow := NewUnitOfWork(db, time.Second, time.Second)
+	permanent := errors.New("permanent")
+
+	tests := []struct {
+		name      string
+		errs      []error // Returned by successive attempts, nil after the last
+		wantErr   error
+		attempts  int
+		commits   int
+		rollbacks int
+	}{
+		{"commits at once", nil, nil, 1, 1, 0},
+		{"retries a deadlock", []error{&pq.Error{Code: deadlockDetected}}, nil, 2, 1, 1},
+		{"retries a wrapped serialization failure", []error{
+			fmt.Errorf("decrementing stock: %w", &pq.Error{Code: serializationFailure}),
+			&pq.Error{Code: deadlockDetected},
+		}, nil, 3, 1, 2},
+		{"gives up after the last attempt", []error{
+			&pq.Error{Code: deadlockDetected},
+			&pq.Error{Code: deadlockDetected},
+			&pq.Error{Code: deadlockDetected},
+		}, &pq.Error{Code: deadlockDetected}, maxAttempts, 0, maxAttempts},
+		{"does not retry other errors", []error{permanent}, permanent, 1, 0, 1},
+		{"does not retry a duplicate key", []error{&pq.Error{Code: uniqueViolation}}, &pq.Error{Code: uniqueViolation}, 1, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d.commits, d.rollbacks = 0, 0
+			attempts := 0
+			err := uow.Do(context.Background(), func(repository.Repos) error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			})
+
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Do() error = %v, want nil", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.attempts || d.commits != tt.commits || d.rollbacks != tt.rollbacks {
+				t.Errorf("attempts, commits, rollbacks = %d, %d, %d, want %d, %d, %d",
+					attempts, d.commits, d.rollbacks, tt.attempts, tt.commits, tt.rollbacks)
+			}
+		})
+	}
+}
+
+// TestUnitOfWorkRetriesDeadlockVictims runs overlapping units of work of
+// which Postgres picks some as deadlock victims, and checks that each is run
+// again until every one commits
+func TestUnitOfWorkRetriesDeadlockVictims(t *testing.T) {
+	db, d := newTxDB(t, "tx-deadlocks")
+	uow := NewUnitOfWork(db, time.Second, time.Second)
+
+	const workers = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(victim bool) {
+			defer wg.Done()
+			first := true
+			errs <- uow.Do(context.Background(), func(repository.Repos) error {
+				if victim && first {
+					first = false
+					return &pq.Error{Code: deadlockDetected, Message: "deadlock detected"}
+				}
+				return nil
+			})
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Do() error = %v", err)
+		}
+	}
+	if d.commits != workers || d.rollbacks != workers/2 {
+		t.Errorf("commits, rollbacks = %d, %d, want %d, %d", d.commits, d.rollbacks, workers, workers/2)
+	}
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+)
+
+// RestaurantRepo reads the restaurants table and maintains the opening hours
+// in restaurant_hours and restaurant_hours_exceptions
+type RestaurantRepo struct {
+	conn
+}
+
+// restaurantColumns selects a restaurant with its ordering settings
+const restaurantColumns = `id, slug, name, timezone, currency, tax_rate, last_order_minutes,
+	paused_at, paused_until, pause_reason`
+
+// List returns every restaurant
+func (r *RestaurantRepo) List(ctx context.Context) ([]models.Restaurant, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx, "SELECT "+restaurantColumns+" FROM restaurants ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var restaurants []models.Restaurant
+	for rows.Next() {
+		var restaurant models.Restaurant
+		if err := scanRestaurant(rows, &restaurant); err != nil {
+			return nil, err
+		}
+		restaurants = append(restaurants, restaurant)
+	}
+	return restaurants, rows.Err()
+}
+
+// Get returns a restaurant by ID
+func (r *RestaurantRepo) Get(ctx context.Context, id int) (models.Restaurant, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var restaurant models.Restaurant
+	err := scanRestaurant(r.q.QueryRowContext(ctx, "SELECT "+restaurantColumns+" FROM restaurants WHERE id = $1", id), &restaurant)
+	return restaurant, notFound(err)
+}
+
+// GetBySlug returns a restaurant by slug
+func (r *RestaurantRepo) GetBySlug(ctx context.Context, slug string) (models.Restaurant, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var restaurant models.Restaurant
+	err := scanRestaurant(r.q.QueryRowContext(ctx, "SELECT "+restaurantColumns+" FROM restaurants WHERE slug = $1", slug), &restaurant)
+	return restaurant, notFound(err)
+}
+
+// scanRestaurant reads a row of restaurantColumns
+func scanRestaurant(row interface{ Scan(dest ...any) error }, restaurant *models.Restaurant) error {
+	return row.Scan(&restaurant.ID, &restaurant.Slug, &restaurant.Name, &restaurant.Timezone,
+		&restaurant.Currency, &restaurant.TaxRate, &restaurant.LastOrderMinutes,
+		&restaurant.PausedAt, &restaurant.PausedUntil, &restaurant.PauseReason)
+}
+
+// WeeklyHours returns the opening hours ordered by weekday and time
+func (r *RestaurantRepo) WeeklyHours(ctx context.Context, restaurantID int) ([]models.OpeningHours, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT weekday, to_char(opens_at, 'HH24:MI'), to_char(closes_at, 'HH24:MI')
+		FROM restaurant_hours WHERE restaurant_id = $1 ORDER BY weekday, opens_at`,
+		restaurantID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hours []models.OpeningHours
+	for rows.Next() {
+		var h models.OpeningHours
+		if err := rows.Scan(&h.Weekday, &h.OpensAt, &h.ClosesAt); err != nil {
+			return nil, err
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
+
+// SetWeeklyHours replaces the opening hours and the last-order cutoff. It
+// runs several statements, so callers run it in a unit of work.
+func (r *RestaurantRepo) SetWeeklyHours(ctx context.Context, restaurantID, lastOrderMinutes int, hours []models.OpeningHours) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx, "UPDATE restaurants SET last_order_minutes = $1 WHERE id = $2", lastOrderMinutes, restaurantID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+
+	if _, err := r.q.ExecContext(ctx, "DELETE FROM restaurant_hours WHERE restaurant_id = $1", restaurantID); err != nil {
+		return err
+	}
+	for _, h := range hours {
+		_, err := r.q.ExecContext(ctx,
+			"INSERT INTO restaurant_hours (restaurant_id, weekday, opens_at, closes_at) VALUES ($1, $2, $3, $4)",
+			restaurantID, h.Weekday, h.OpensAt, h.ClosesAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exceptions returns the exceptions dated from from to to inclusive
+func (r *RestaurantRepo) Exceptions(ctx context.Context, restaurantID int, from, to string) ([]models.HoursException, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.q.QueryContext(ctx,
+		`SELECT to_char(date, 'YYYY-MM-DD'), closed, COALESCE(to_char(opens_at, 'HH24:MI'), ''),
+			COALESCE(to_char(closes_at, 'HH24:MI'), ''), note
+		FROM restaurant_hours_exceptions
+		WHERE restaurant_id = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date`,
+		restaurantID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exceptions []models.HoursException
+	for rows.Next() {
+		var e models.HoursException
+		if err := rows.Scan(&e.Date, &e.Closed, &e.OpensAt, &e.ClosesAt, &e.Note); err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, e)
+	}
+	return exceptions, rows.Err()
+}
+
+// SetException creates or replaces the exception on its date
+func (r *RestaurantRepo) SetException(ctx context.Context, restaurantID int, exception models.HoursException) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.q.ExecContext(ctx,
+		`INSERT INTO restaurant_hours_exceptions (restaurant_id, date, closed, opens_at, closes_at, note)
+		VALUES ($1, $2, $3, NULLIF($4, '')::time, NULLIF($5, '')::time, $6)
+		ON CONFLICT (restaurant_id, date) DO UPDATE
+		SET closed = EXCLUDED.closed, opens_at = EXCLUDED.opens_at, closes_at = EXCLUDED.closes_at, note = EXCLUDED.note`,
+		restaurantID, exception.Date, exception.Closed, exception.OpensAt, exception.ClosesAt, exception.Note,
+	)
+	return err
+}
+
+// DeleteException removes the exception on date
+func (r *RestaurantRepo) DeleteException(ctx context.Context, restaurantID int, date string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"DELETE FROM restaurant_hours_exceptions WHERE restaurant_id = $1 AND date = $2",
+		restaurantID, date,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// SetPause pauses ordering from pausedAt until until, or resumes it when
+// pausedAt is nil
+func (r *RestaurantRepo) SetPause(ctx context.Context, restaurantID int, pausedAt, until *time.Time, reason string) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.q.ExecContext(ctx,
+		"UPDATE restaurants SET paused_at = $1, paused_until = $2, pause_reason = $3 WHERE id = $4",
+		pausedAt, until, reason, restaurantID,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
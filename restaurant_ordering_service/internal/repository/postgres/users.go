@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/restaurant_ordering_service/internal/models"
+)
+
+// UserRepo reads users from the users table
+type UserRepo struct {
+	conn
+}
+
+// GetByUsername returns the user including their password
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+	err := r.q.QueryRowContext(ctx,
+		"SELECT id, username, password, email, address, role, COALESCE(restaurant_id, 0) FROM users WHERE username = $1",
+		username,
+	).Scan(&user.ID, &user.Username, &user.Password, &user.Email, &user.Address, &user.Role, &user.RestaurantID)
+	return user, notFound(err)
+}
+
+// GetByID returns the user without their password
+func (r *UserRepo) GetByID(ctx context.Context, id int) (models.User, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+	err := r.q.QueryRowContext(ctx,
+		"SELECT id, username, email, address, role, COALESCE(restaurant_id, 0) FROM users WHERE id = $1",
+		id,
+	).Scan(&user.ID, &user.Username, &user.Email, &user.Address, &user.Role, &user.RestaurantID)
+	return user, notFound(err)
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+
+	// ErrInsufficientStock is returned when a food item does not have enough
+	// quantity left to fulfil an order
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrStatusChanged is returned when an order is no longer in the status
+	// a conditional update expected
+	ErrStatusChanged = errors.New("order status changed")
+
+	// ErrKitchenStateChanged is returned when a kitchen ticket or item is no
+	// longer in the state a conditional update expected
+	ErrKitchenStateChanged = errors.New("kitchen state changed")
+
+	// ErrDuplicate is returned when a record would take a name that must be
+	// unique
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+// UserRepo reads users
+type UserRepo interface {
+	// GetByUsername returns the user including their password
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+	// GetByID returns the user without their password
+	GetByID(ctx context.Context, id int) (models.User, error)
+}
+
+// RestaurantRepo reads restaurants and maintains their opening hours.
+// Dates are YYYY-MM-DD and times HH:MM, in the restaurant's timezone.
+type RestaurantRepo interface {
+	List(ctx context.Context) ([]models.Restaurant, error)
+	Get(ctx context.Context, id int) (models.Restaurant, error)
+	GetBySlug(ctx context.Context, slug string) (models.Restaurant, error)
+	// WeeklyHours returns the opening hours ordered by weekday and time
+	WeeklyHours(ctx context.Context, restaurantID int) ([]models.OpeningHours, error)
+	// SetWeeklyHours replaces the opening hours and the last-order cutoff
+	SetWeeklyHours(ctx context.Context, restaurantID, lastOrderMinutes int, hours []models.OpeningHours) error
+	// Exceptions returns the exceptions dated from from to to inclusive
+	Exceptions(ctx context.Context, restaurantID int, from, to string) ([]models.HoursException, error)
+	// SetException creates or replaces the exception on its date
+	SetException(ctx context.Context, restaurantID int, exception models.HoursException) error
+	DeleteException(ctx context.Context, restaurantID int, date string) error
+	// SetPause pauses ordering from pausedAt until until, which may be nil,
+	// or resumes it when pausedAt is nil
+	SetPause(ctx context.Context, restaurantID int, pausedAt, until *time.Time, reason string) error
+}
+
+// MenuRepo reads food items and maintains their stock. Every call is scoped
+// to a restaurant, so the food items of other restaurants are never seen.
+// Food items are returned with their variants and modifier groups, and
+// bundles with their slots and options.
+type MenuRepo interface {
+	List(ctx context.Context, restaurantID int) ([]models.FoodItem, error)
+	// ListForUpdate returns the food items with the given IDs ordered by ID,
+	// leaving out those that do not exist, and locks them in that order
+	// until the surrounding transaction ends
+	ListForUpdate(ctx context.Context, restaurantID int, ids []int) ([]models.FoodItem, error)
+	Get(ctx context.Context, restaurantID, id int) (models.FoodItem, error)
+	ListBundles(ctx context.Context, restaurantID int) ([]models.Bundle, error)
+	GetBundle(ctx context.Context, restaurantID, id int) (models.Bundle, error)
+	// DecrementStock removes quantity from a food item's stock, returning
+	// ErrInsufficientStock if not enough is left
+	DecrementStock(ctx context.Context, restaurantID, id, quantity int) error
+	// DecrementVariantStock removes quantity from a variant's stock,
+	// returning ErrInsufficientStock if not enough is left
+	DecrementVariantStock(ctx context.Context, restaurantID, variantID, quantity int) error
+	// SetStockSettings changes the low-stock threshold of a food item and
+	// whether it leaves the menu while sold out
+	SetStockSettings(ctx context.Context, restaurantID, id, threshold int, hideWhenSoldOut bool) error
+	// Restock adds quantity to the stock of a food item, or of its variant
+	// unless variantID is 0, returning ErrNotFound if there is no such item
+	Restock(ctx context.Context, restaurantID, id, variantID, quantity int) error
+}
+
+// OrderRepo stores orders with their items and bundles. Unlike menu and
+// kitchen calls, order calls are not scoped to a restaurant: an order ID is
+// unique across restaurants, customers are not tied to one and pay for an
+// order by its ID alone, and access is checked against the customer who
+// placed it. The restaurant is read from the order instead, and everything
+// an order then touches, such as stock and its kitchen ticket, is scoped
+// to it.
+type OrderRepo interface {
+	// Create inserts the order with its items and bundles, setting their IDs
+	Create(ctx context.Context, order *models.Order) error
+	// Get returns the order with its items and bundles
+	Get(ctx context.Context, id int) (models.Order, error)
+	// GetForUpdate returns the order with its items and bundles and locks
+	// the order until the surrounding transaction ends
+	GetForUpdate(ctx context.Context, id int) (models.Order, error)
+	// UpdateStatus changes the status of an order from one value to another,
+	// returning ErrStatusChanged if it is no longer in status from
+	UpdateStatus(ctx context.Context, id int, from, to string) error
+}
+
+// KitchenRepo stores the kitchen tickets of paid orders and the preparation
+// of their items. Every call but CreateTicket is scoped to the restaurant
+// of the order, so one kitchen never sees or changes another's tickets.
+type KitchenRepo interface {
+	// CreateTicket opens the ticket of a paid order. A ticket belongs to the
+	// restaurant of its order, so none is given.
+	CreateTicket(ctx context.Context, orderID int) error
+	// Queue returns the tickets that have not been bumped, highest priority
+	// and then oldest first, with their items
+	Queue(ctx context.Context, restaurantID int) ([]models.KitchenTicket, error)
+	// GetTicket returns a ticket with its items, bumped or not
+	GetTicket(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error)
+	// GetItem returns an order item that has a ticket
+	GetItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error)
+	// StartItem marks an item as started, returning ErrKitchenStateChanged
+	// if it already was
+	StartItem(ctx context.Context, restaurantID, itemID int) error
+	// FinishItem marks a started item as done, returning
+	// ErrKitchenStateChanged if it was not started or already done
+	FinishItem(ctx context.Context, restaurantID, itemID int) error
+	// SetPriority changes the priority of a ticket
+	SetPriority(ctx context.Context, restaurantID, orderID, priority int) error
+	// Bump takes a ticket off the queue, returning ErrKitchenStateChanged if
+	// it already was
+	Bump(ctx context.Context, restaurantID, orderID int) error
+	// Recall puts a bumped ticket back on the queue, returning
+	// ErrKitchenStateChanged if it was not bumped
+	Recall(ctx context.Context, restaurantID, orderID int) error
+	// PrepTimes returns the average preparation time of each food item that
+	// has been prepared
+	PrepTimes(ctx context.Context, restaurantID int) ([]models.PrepTime, error)
+}
+
+// InventoryRepo stores the ingredients of restaurants, the recipes that use
+// them and the movements of their stock. Every call is scoped to a
+// restaurant.
+type InventoryRepo interface {
+	// ListIngredients returns the ingredients ordered by name
+	ListIngredients(ctx context.Context, restaurantID int) ([]models.Ingredient, error)
+	GetIngredient(ctx context.Context, restaurantID, id int) (models.Ingredient, error)
+	// CreateIngredient inserts an ingredient, setting its ID, and returns
+	// ErrDuplicate if the restaurant already has one with its name
+	CreateIngredient(ctx context.Context, ingredient *models.Ingredient) error
+	SetThreshold(ctx context.Context, restaurantID, id int, threshold float64) error
+	// AdjustStock adds the change of a movement to the stock of its
+	// ingredient and records the movement, setting its ID, QuantityAfter
+	// and CreatedAt. It returns ErrInsufficientStock if the stock would go
+	// below zero.
+	AdjustStock(ctx context.Context, restaurantID int, movement *models.StockMovement) error
+	// Movements returns the latest limit movements, newest first, of one
+	// ingredient or of all of them when ingredientID is 0
+	Movements(ctx context.Context, restaurantID, ingredientID, limit int) ([]models.StockMovement, error)
+	// Recipes returns the recipes of the food items and then of the
+	// modifiers, with the names and units of their ingredients
+	Recipes(ctx context.Context, restaurantID int) ([]models.Recipe, error)
+	// SetRecipe replaces the recipe of a food item or of a modifier
+	SetRecipe(ctx context.Context, restaurantID int, recipe models.Recipe) error
+}
+
+// AlertRepo stores the stock alerts of restaurants
+type AlertRepo interface {
+	// Create inserts an alert, setting its ID and CreatedAt
+	Create(ctx context.Context, alert *models.StockAlert) error
+	// List returns the latest limit alerts, newest first, or only the open
+	// ones
+	List(ctx context.Context, restaurantID int, openOnly bool, limit int) ([]models.StockAlert, error)
+	// Acknowledge closes an open alert on behalf of a staff member, leaving
+	// a closed one as it is, and returns it
+	Acknowledge(ctx context.Context, restaurantID, id, userID int) (models.StockAlert, error)
+	// Resolve closes the open alerts of an ingredient, or of a food item or
+	// its variant, whose stock has recovered
+	Resolve(ctx context.Context, restaurantID, ingredientID, foodItemID, variantID int) error
+}
+
+// Repos groups the repositories that share a connection or transaction
+type Repos struct {
+	Restaurants RestaurantRepo
+	Users       UserRepo
+	Menu        MenuRepo
+	Orders      OrderRepo
+	Kitchen     KitchenRepo
+	Inventory   InventoryRepo
+	Alerts      AlertRepo
+}
+
+// UnitOfWork runs a function against repositories bound to a single
+// transaction. The transaction is committed if fn returns nil and rolled
+// back otherwise. Implementations may call fn again after a serialization
+// failure or deadlock, so fn must not carry state over between calls.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repos) error) error
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// Bundles returns every bundle of a restaurant, flagging those and the
+// options that cannot be ordered now. A bundle is sold out once any of its
+// slots has no option in stock.
+func (s *Menu) Bundles(ctx context.Context, restaurant models.Restaurant) ([]models.Bundle, error) {
+	bundles, err := s.menu.ListBundles(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, inStock, err := s.stock(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := availability(ctx, s.restaurants, restaurant, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range bundles {
+		bundle := &bundles[i]
+
+		soldOut := false
+		for j := range bundle.Slots {
+			slotInStock := false
+			for k := range bundle.Slots[j].Options {
+				option := &bundle.Slots[j].Options[k]
+				optionInStock := inStock[stockKey{option.FoodItemID, option.VariantID}]
+				option.Available = availability.AcceptingOrders && optionInStock
+				slotInStock = slotInStock || optionInStock
+			}
+			soldOut = soldOut || !slotInStock
+		}
+
+		switch {
+		case !availability.AcceptingOrders:
+			bundle.UnavailableReason = availability.Reason
+		case soldOut:
+			bundle.UnavailableReason = models.UnavailableSoldOut
+		default:
+			bundle.Available = true
+		}
+	}
+	if bundles == nil {
+		bundles = []models.Bundle{}
+	}
+	return bundles, nil
+}
+
+// buildOrderBundle builds the order bundle for a bundle of an order request,
+// filling each slot with its only option or with the option chosen. Its unit
+// price is that of the bundle plus the price deltas of the options, and its
+// items are priced at zero. foodItems is given the names of the food items.
+// index is the position of the bundle in the request, used to name invalid
+// fields.
+func buildOrderBundle(ctx context.Context, menu repository.MenuRepo, restaurantID int, request models.OrderBundleRequest, index int, foodItems map[int]string) (models.OrderBundle, error) {
+	bundle, err := menu.GetBundle(ctx, restaurantID, request.BundleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.OrderBundle{}, errBundleNotFound(request.BundleID)
+		}
+		return models.OrderBundle{}, err
+	}
+
+	orderBundle := models.OrderBundle{
+		BundleID:  bundle.ID,
+		Name:      bundle.Name,
+		Quantity:  request.Quantity,
+		UnitPrice: bundle.Price,
+	}
+
+	// Each slot is chosen at most once, and must be a slot of the bundle
+	choices := make(map[int]int) // Option ID by slot ID
+	for _, choice := range request.Choices {
+		if _, ok := choices[choice.SlotID]; ok {
+			return orderBundle, apperrors.Validation(models.FieldError{
+				Field:   fmt.Sprintf("bundles[%d].choices", index),
+				Message: "must not repeat a slot",
+			})
+		}
+		if _, ok := findSlot(bundle, choice.SlotID); !ok {
+			return orderBundle, errBundleOptionNotFound(bundle.ID, choice.SlotID, choice.OptionID)
+		}
+		choices[choice.SlotID] = choice.OptionID
+	}
+
+	for _, slot := range bundle.Slots {
+		// A slot with a single option is fixed, the others need a choice
+		optionID, chosen := choices[slot.ID]
+		if !chosen {
+			if len(slot.Options) != 1 {
+				return orderBundle, errBundleChoiceRequired(bundle, slot)
+			}
+			optionID = slot.Options[0].ID
+		}
+		option, ok := findOption(slot, optionID)
+		if !ok {
+			return orderBundle, errBundleOptionNotFound(bundle.ID, slot.ID, optionID)
+		}
+
+		foodItem, err := menu.Get(ctx, restaurantID, option.FoodItemID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return orderBundle, errFoodItemNotFound(option.FoodItemID)
+			}
+			return orderBundle, err
+		}
+		item := models.OrderItem{
+			FoodItemID: foodItem.ID,
+			Quantity:   slot.Quantity * request.Quantity,
+		}
+
+		// As when ordered on its own, a food item with variants is only
+		// stocked as one of them
+		switch {
+		case len(foodItem.Variants) > 0 && option.VariantID == 0:
+			return orderBundle, errVariantRequired(foodItem)
+		case option.VariantID != 0:
+			variant, ok := findVariant(foodItem, option.VariantID)
+			if !ok {
+				return orderBundle, errVariantNotFound(foodItem.ID, option.VariantID)
+			}
+			item.VariantID, item.Variant = variant.ID, variant.Name
+		}
+
+		orderBundle.Items = append(orderBundle.Items, item)
+		orderBundle.UnitPrice += option.PriceDelta
+		foodItems[foodItem.ID] = foodItem.Name
+	}
+
+	orderBundle.UnitPrice = math.Max(math.Round(orderBundle.UnitPrice*100)/100, 0)
+	return orderBundle, nil
+}
+
+// findSlot returns the slot of a bundle with the ID
+func findSlot(bundle models.Bundle, id int) (models.BundleSlot, bool) {
+	for _, slot := range bundle.Slots {
+		if slot.ID == id {
+			return slot, true
+		}
+	}
+	return models.BundleSlot{}, false
+}
+
+// findOption returns the option of a slot with the ID
+func findOption(slot models.BundleSlot, id int) (models.BundleOption, bool) {
+	for _, option := range slot.Options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return models.BundleOption{}, false
+}
+
+// errBundleNotFound reports a bundle that does not exist
+func errBundleNotFound(id int) error {
+	return apperrors.NotFound(apperrors.CodeBundleNotFound, "Bundle not found: "+strconv.Itoa(id)).
+		WithDetail("bundle_id", id)
+}
+
+// errBundleChoiceRequired reports a slot with several options ordered
+// without choosing one
+func errBundleChoiceRequired(bundle models.Bundle, slot models.BundleSlot) error {
+	optionIDs := make([]int, 0, len(slot.Options))
+	for _, option := range slot.Options {
+		optionIDs = append(optionIDs, option.ID)
+	}
+	return apperrors.Invalid(apperrors.CodeBundleChoiceRequired, "Choose the "+slot.Name+" of "+bundle.Name).
+		WithDetail("bundle_id", bundle.ID).
+		WithDetail("slot_id", slot.ID).
+		WithDetail("option_ids", optionIDs)
+}
+
+// errBundleOptionNotFound reports a slot or option that the bundle does not
+// have
+func errBundleOptionNotFound(bundleID, slotID, optionID int) error {
+	return apperrors.NotFound(apperrors.CodeBundleOptionNotFound, "Bundle option not found: "+strconv.Itoa(optionID)).
+		WithDetail("bundle_id", bundleID).
+		WithDetail("slot_id", slotID).
+		WithDetail("option_id", optionID)
+}
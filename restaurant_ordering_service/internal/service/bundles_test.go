@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository/memory"
+	"github.com/shared/apperrors"
+)
+
+func TestBuildOrderBundle(t *testing.T) {
+	store := memory.NewStore()
+	restaurant := store.AddRestaurant(models.Restaurant{Slug: "main", Name: "Main"})
+	add := func(item models.FoodItem) models.FoodItem {
+		item.RestaurantID = restaurant.ID
+		return store.AddFoodItem(item)
+	}
+	rice := add(models.FoodItem{Name: "Rice", Price: 3, Quantity: 100})
+	dal := add(models.FoodItem{Name: "Dal", Price: 4, Quantity: 100})
+	paneer := add(models.FoodItem{Name: "Paneer", Price: 6, Quantity: 100})
+	lassi := add(models.FoodItem{Name: "Lassi", Price: 3, Variants: []models.Variant{
+		{Name: "Sweet", Price: 3, Quantity: 50},
+		{Name: "Salted", Price: 3, Quantity: 50},
+	}})
+	sweet := lassi.Variants[0]
+
+	// A thali of two portions of rice, a curry of choice and a sweet lassi
+	thali := store.AddBundle(models.Bundle{
+		RestaurantID: restaurant.ID,
+		Name:         "Thali",
+		Price:        12,
+		Slots: []models.BundleSlot{
+			{Name: "Rice", Quantity: 2, Options: []models.BundleOption{{FoodItemID: rice.ID}}},
+			{Name: "Curry", Options: []models.BundleOption{
+				{FoodItemID: dal.ID, PriceDelta: -1.5},
+				{FoodItemID: paneer.ID, PriceDelta: 2.25},
+			}},
+			{Name: "Drink", Options: []models.BundleOption{{FoodItemID: lassi.ID, VariantID: sweet.ID}}},
+		},
+	})
+	riceSlot, currySlot := thali.Slots[0], thali.Slots[1]
+	dalOption, paneerOption := currySlot.Options[0], currySlot.Options[1]
+
+	// Bundles whose options no longer match the menu
+	broken := func(option models.BundleOption) models.Bundle {
+		return store.AddBundle(models.Bundle{
+			RestaurantID: restaurant.ID,
+			Name:         "Broken",
+			Price:        5,
+			Slots:        []models.BundleSlot{{Name: "Main", Options: []models.BundleOption{option}}},
+		})
+	}
+	removedItem := broken(models.BundleOption{FoodItemID: 999, Name: "Removed"})
+	noVariant := broken(models.BundleOption{FoodItemID: lassi.ID})
+	removedVariant := broken(models.BundleOption{FoodItemID: lassi.ID, VariantID: 998, Name: "Lassi (Mango)"})
+
+	t.Run("fills fixed slots and the choice", func(t *testing.T) {
+		foodItems := make(map[int]string)
+		bundle, err := buildOrderBundle(context.Background(), store.Repos().Menu, restaurant.ID, models.OrderBundleRequest{
+			BundleID: thali.ID,
+			Quantity: 3,
+			Choices:  []models.BundleChoice{{SlotID: currySlot.ID, OptionID: paneerOption.ID}},
+		}, 0, foodItems)
+		if err != nil {
+			t.Fatalf("buildOrderBundle() error = %v", err)
+		}
+
+		if bundle.UnitPrice != 14.25 {
+			t.Errorf("UnitPrice = %v, want 14.25 with the price delta of paneer", bundle.UnitPrice)
+		}
+		want := []models.OrderItem{
+			{FoodItemID: rice.ID, Quantity: 6},
+			{FoodItemID: paneer.ID, Quantity: 3},
+			{FoodItemID: lassi.ID, VariantID: sweet.ID, Variant: "Sweet", Quantity: 3},
+		}
+		if len(bundle.Items) != len(want) {
+			t.Fatalf("Items = %+v, want %+v", bundle.Items, want)
+		}
+		for i, item := range bundle.Items {
+			if item.FoodItemID != want[i].FoodItemID || item.VariantID != want[i].VariantID ||
+				item.Variant != want[i].Variant || item.Quantity != want[i].Quantity || item.UnitPrice != 0 {
+				t.Errorf("Items[%d] = %+v, want %+v", i, item, want[i])
+			}
+		}
+		if foodItems[paneer.ID] != "Paneer" || foodItems[lassi.ID] != "Lassi" {
+			t.Errorf("food item names = %v, want those of the items", foodItems)
+		}
+	})
+
+	t.Run("takes off a discount", func(t *testing.T) {
+		bundle, err := buildOrderBundle(context.Background(), store.Repos().Menu, restaurant.ID, models.OrderBundleRequest{
+			BundleID: thali.ID,
+			Quantity: 1,
+			Choices:  []models.BundleChoice{{SlotID: currySlot.ID, OptionID: dalOption.ID}},
+		}, 0, make(map[int]string))
+		if err != nil {
+			t.Fatalf("buildOrderBundle() error = %v", err)
+		}
+		if bundle.UnitPrice != 10.5 {
+			t.Errorf("UnitPrice = %v, want 10.5", bundle.UnitPrice)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		request models.OrderBundleRequest
+		code    apperrors.Code
+	}{
+		{"unknown bundle", models.OrderBundleRequest{BundleID: 999, Quantity: 1}, apperrors.CodeBundleNotFound},
+		{"choice required", models.OrderBundleRequest{BundleID: thali.ID, Quantity: 1}, apperrors.CodeBundleChoiceRequired},
+		{"repeated slot", models.OrderBundleRequest{BundleID: thali.ID, Quantity: 1, Choices: []models.BundleChoice{
+			{SlotID: currySlot.ID, OptionID: dalOption.ID},
+			{SlotID: currySlot.ID, OptionID: paneerOption.ID},
+		}}, apperrors.CodeValidationFailed},
+		{"unknown slot", models.OrderBundleRequest{BundleID: thali.ID, Quantity: 1, Choices: []models.BundleChoice{
+			{SlotID: 999, OptionID: dalOption.ID},
+		}}, apperrors.CodeBundleOptionNotFound},
+		{"option of another slot", models.OrderBundleRequest{BundleID: thali.ID, Quantity: 1, Choices: []models.BundleChoice{
+			{SlotID: currySlot.ID, OptionID: riceSlot.Options[0].ID},
+		}}, apperrors.CodeBundleOptionNotFound},
+		{"food item removed from the menu", models.OrderBundleRequest{BundleID: removedItem.ID, Quantity: 1}, apperrors.CodeFoodItemNotFound},
+		{"food item with variants without one", models.OrderBundleRequest{BundleID: noVariant.ID, Quantity: 1}, apperrors.CodeVariantRequired},
+		{"variant removed from the menu", models.OrderBundleRequest{BundleID: removedVariant.ID, Quantity: 1}, apperrors.CodeVariantNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildOrderBundle(context.Background(), store.Repos().Menu, restaurant.ID, tt.request, 0, make(map[int]string))
+			if !apperrors.HasCode(err, tt.code) {
+				t.Errorf("buildOrderBundle() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
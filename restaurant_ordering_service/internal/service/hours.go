@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// Layouts of the dates and times of opening hours
+const (
+	dateLayout  = "2006-01-02"
+	clockLayout = "15:04"
+)
+
+// scheduleDays is how far ahead availability looks for the next opening
+// and for the end of the current opening
+const scheduleDays = 14
+
+// Hours returns the weekly hours of a restaurant and its exceptions from
+// today on
+func (s *Restaurants) Hours(ctx context.Context, restaurant models.Restaurant) (models.RestaurantHours, error) {
+	today, err := localDay(restaurant, time.Now())
+	if err != nil {
+		return models.RestaurantHours{}, err
+	}
+
+	weekly, err := s.restaurants.WeeklyHours(ctx, restaurant.ID)
+	if err != nil {
+		return models.RestaurantHours{}, err
+	}
+	exceptions, err := s.restaurants.Exceptions(ctx, restaurant.ID, today.Format(dateLayout), today.AddDate(1, 0, 0).Format(dateLayout))
+	if err != nil {
+		return models.RestaurantHours{}, err
+	}
+
+	hours := models.RestaurantHours{
+		Timezone:         restaurant.Timezone,
+		LastOrderMinutes: restaurant.LastOrderMinutes,
+		Weekly:           weekly,
+		Exceptions:       exceptions,
+	}
+	if hours.Weekly == nil {
+		hours.Weekly = []models.OpeningHours{}
+	}
+	if hours.Exceptions == nil {
+		hours.Exceptions = []models.HoursException{}
+	}
+	return hours, nil
+}
+
+// SetHours replaces the weekly hours and the last-order cutoff of a
+// restaurant
+func (s *Restaurants) SetHours(ctx context.Context, restaurant models.Restaurant, request models.HoursRequest) (models.RestaurantHours, error) {
+	var fields []models.FieldError
+	for i, h := range request.Weekly {
+		prefix := "weekly[" + strconv.Itoa(i) + "]."
+		fields = append(fields, checkClock(prefix+"opens_at", h.OpensAt)...)
+		fields = append(fields, checkClock(prefix+"closes_at", h.ClosesAt)...)
+	}
+	if len(fields) > 0 {
+		return models.RestaurantHours{}, apperrors.Validation(fields...)
+	}
+
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		return repos.Restaurants.SetWeeklyHours(ctx, restaurant.ID, request.LastOrderMinutes, request.Weekly)
+	})
+	if err != nil {
+		return models.RestaurantHours{}, err
+	}
+
+	restaurant.LastOrderMinutes = request.LastOrderMinutes
+	return s.Hours(ctx, restaurant)
+}
+
+// SetException sets the hours of a restaurant on date, or closes it that
+// day
+func (s *Restaurants) SetException(ctx context.Context, restaurant models.Restaurant, date string, request models.HoursExceptionRequest) (models.HoursException, error) {
+	var fields []models.FieldError
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		fields = append(fields, models.FieldError{Field: "date", Message: "must be a date such as 2025-12-25"})
+	}
+	if !request.Closed {
+		fields = append(fields, checkClock("opens_at", request.OpensAt)...)
+		fields = append(fields, checkClock("closes_at", request.ClosesAt)...)
+	}
+	if len(fields) > 0 {
+		return models.HoursException{}, apperrors.Validation(fields...)
+	}
+
+	exception := models.HoursException{
+		Date:     date,
+		Closed:   request.Closed,
+		OpensAt:  request.OpensAt,
+		ClosesAt: request.ClosesAt,
+		Note:     request.Note,
+	}
+	if exception.Closed {
+		exception.OpensAt, exception.ClosesAt = "", ""
+	}
+	if err := s.restaurants.SetException(ctx, restaurant.ID, exception); err != nil {
+		return models.HoursException{}, err
+	}
+	return exception, nil
+}
+
+// DeleteException restores the weekly hours of a restaurant on date
+func (s *Restaurants) DeleteException(ctx context.Context, restaurant models.Restaurant, date string) error {
+	err := s.restaurants.DeleteException(ctx, restaurant.ID, date)
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperrors.NotFound(apperrors.CodeHoursExceptionNotFound, "The opening hours have no exception on "+date).
+			WithDetail("date", date)
+	}
+	return err
+}
+
+// Pause stops a restaurant from taking orders for the requested number of
+// minutes, or until Resume when it is zero
+func (s *Restaurants) Pause(ctx context.Context, restaurant models.Restaurant, request models.PauseRequest) (models.Availability, error) {
+	now := time.Now()
+	restaurant.PausedAt, restaurant.PausedUntil, restaurant.PauseReason = &now, nil, request.Reason
+	if request.Minutes > 0 {
+		until := now.Add(time.Duration(request.Minutes) * time.Minute)
+		restaurant.PausedUntil = &until
+	}
+
+	err := s.restaurants.SetPause(ctx, restaurant.ID, restaurant.PausedAt, restaurant.PausedUntil, restaurant.PauseReason)
+	if err != nil {
+		return models.Availability{}, err
+	}
+	return availability(ctx, s.restaurants, restaurant, now)
+}
+
+// Resume lets a paused restaurant take orders again
+func (s *Restaurants) Resume(ctx context.Context, restaurant models.Restaurant) (models.Availability, error) {
+	restaurant.PausedAt, restaurant.PausedUntil, restaurant.PauseReason = nil, nil, ""
+	if err := s.restaurants.SetPause(ctx, restaurant.ID, nil, nil, ""); err != nil {
+		return models.Availability{}, err
+	}
+	return availability(ctx, s.restaurants, restaurant, time.Now())
+}
+
+// Availability tells whether a restaurant takes orders now
+func (s *Restaurants) Availability(ctx context.Context, restaurant models.Restaurant) (models.Availability, error) {
+	return availability(ctx, s.restaurants, restaurant, time.Now())
+}
+
+// window is a period in which a restaurant is open
+type window struct {
+	opens, closes time.Time
+}
+
+// availability tells whether a restaurant takes orders at now, from its
+// weekly hours, the exceptions to them, its last-order cutoff and whether
+// staff paused ordering
+func availability(ctx context.Context, restaurants repository.RestaurantRepo, restaurant models.Restaurant, now time.Time) (models.Availability, error) {
+	today, err := localDay(restaurant, now)
+	if err != nil {
+		return models.Availability{}, err
+	}
+	first, last := today.AddDate(0, 0, -1), today.AddDate(0, 0, scheduleDays)
+
+	weekly, err := restaurants.WeeklyHours(ctx, restaurant.ID)
+	if err != nil {
+		return models.Availability{}, err
+	}
+	exceptionList, err := restaurants.Exceptions(ctx, restaurant.ID, first.Format(dateLayout), last.Format(dateLayout))
+	if err != nil {
+		return models.Availability{}, err
+	}
+	exceptions := make(map[string]models.HoursException, len(exceptionList))
+	for _, exception := range exceptionList {
+		exceptions[exception.Date] = exception
+	}
+
+	// Collect the windows from yesterday on, since its last one may run
+	// past midnight, and join those that touch
+	var windows []window
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		dayWindows, err := windowsOn(day, weekly, exceptions)
+		if err != nil {
+			return models.Availability{}, fmt.Errorf("opening hours of restaurant %d: %w", restaurant.ID, err)
+		}
+		windows = append(windows, dayWindows...)
+	}
+	windows = mergeWindows(windows)
+
+	// Find the current window, or else the next one. A window that lasts
+	// until the end of the schedule has no known closing time.
+	var result models.Availability
+	end := last.AddDate(0, 0, 1)
+	for _, w := range windows {
+		if w.opens.After(now) {
+			opens := w.opens
+			result.NextOpensAt = &opens
+			break
+		}
+		if now.Before(w.closes) {
+			result.Open = true
+			if w.closes.Before(end) {
+				closes := w.closes
+				result.ClosesAt = &closes
+			}
+			break
+		}
+	}
+
+	exception, hasException := exceptions[today.Format(dateLayout)]
+	result.Note = exception.Note
+	switch {
+	case !result.Open && hasException && exception.Closed:
+		result.Reason = models.UnavailableHoliday
+	case !result.Open:
+		result.Reason = models.UnavailableClosed
+	}
+	if result.ClosesAt != nil && restaurant.LastOrderMinutes > 0 {
+		lastOrder := result.ClosesAt.Add(-time.Duration(restaurant.LastOrderMinutes) * time.Minute)
+		result.LastOrderAt = &lastOrder
+		if !now.Before(lastOrder) {
+			result.Reason = models.UnavailableLastOrders
+		}
+	}
+	if result.Reason == "" && isPaused(restaurant, now) {
+		result.Reason = models.UnavailablePaused
+		result.PausedUntil = restaurant.PausedUntil
+	}
+	result.AcceptingOrders = result.Reason == ""
+	return result, nil
+}
+
+// windowsOn returns the windows that open on day, which is a local
+// midnight. Exceptions replace the weekly hours, and a restaurant without
+// weekly hours is open all day.
+func windowsOn(day time.Time, weekly []models.OpeningHours, exceptions map[string]models.HoursException) ([]window, error) {
+	if exception, ok := exceptions[day.Format(dateLayout)]; ok {
+		if exception.Closed {
+			return nil, nil
+		}
+		w, err := windowAt(day, exception.OpensAt, exception.ClosesAt)
+		return []window{w}, err
+	}
+	if len(weekly) == 0 {
+		return []window{{opens: day, closes: day.AddDate(0, 0, 1)}}, nil
+	}
+
+	var windows []window
+	for _, h := range weekly {
+		if h.Weekday != int(day.Weekday()) {
+			continue
+		}
+		w, err := windowAt(day, h.OpensAt, h.ClosesAt)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+// windowAt returns the window opening on day at opensAt. It runs past
+// midnight when closesAt is not after opensAt.
+func windowAt(day time.Time, opensAt, closesAt string) (window, error) {
+	opens, err := clockOn(day, opensAt)
+	if err != nil {
+		return window{}, err
+	}
+	closes, err := clockOn(day, closesAt)
+	if err != nil {
+		return window{}, err
+	}
+	if !closes.After(opens) {
+		closes, _ = clockOn(day.AddDate(0, 0, 1), closesAt)
+	}
+	return window{opens: opens, closes: closes}, nil
+}
+
+// clockOn returns the time of day clock, as HH:MM, on day
+func clockOn(day time.Time, clock string) (time.Time, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, day.Location()), nil
+}
+
+// mergeWindows sorts windows and joins those that overlap or touch
+func mergeWindows(windows []window) []window {
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].opens.Before(windows[j].opens)
+	})
+
+	var merged []window
+	for _, w := range windows {
+		if n := len(merged); n > 0 && !w.opens.After(merged[n-1].closes) {
+			if w.closes.After(merged[n-1].closes) {
+				merged[n-1].closes = w.closes
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
+
+// localDay returns the local midnight starting the day of now at a
+// restaurant
+func localDay(restaurant models.Restaurant, now time.Time) (time.Time, error) {
+	location, err := time.LoadLocation(restaurant.Timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timezone of restaurant %d: %w", restaurant.ID, err)
+	}
+	local := now.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location), nil
+}
+
+// isPaused reports whether staff paused ordering at a restaurant at now
+func isPaused(restaurant models.Restaurant, now time.Time) bool {
+	return restaurant.PausedAt != nil && (restaurant.PausedUntil == nil || now.Before(*restaurant.PausedUntil))
+}
+
+// checkClock validates a time of day such as 09:30
+func checkClock(field, clock string) []models.FieldError {
+	if _, err := time.Parse(clockLayout, clock); err != nil {
+		return []models.FieldError{{Field: field, Message: "must be a time such as 09:30"}}
+	}
+	return nil
+}
+
+// checkAvailable reports a restaurant that does not take orders
+func checkAvailable(restaurant models.Restaurant, availability models.Availability) error {
+	switch availability.Reason {
+	case "":
+		return nil
+	case models.UnavailablePaused:
+		err := apperrors.Invalid(apperrors.CodeOrderingPaused, restaurant.Name+" is not taking orders right now").
+			WithDetail("restaurant_id", restaurant.ID)
+		if availability.PausedUntil != nil {
+			err = err.WithDetail("paused_until", *availability.PausedUntil)
+		}
+		if restaurant.PauseReason != "" {
+			err = err.WithDetail("reason", restaurant.PauseReason)
+		}
+		return err
+	case models.UnavailableLastOrders:
+		return apperrors.Invalid(apperrors.CodeLastOrdersPassed,
+			fmt.Sprintf("%s took its last orders at %s", restaurant.Name, availability.LastOrderAt.Format(clockLayout))).
+			WithDetail("restaurant_id", restaurant.ID).
+			WithDetail("last_order_at", *availability.LastOrderAt).
+			WithDetail("closes_at", *availability.ClosesAt)
+	}
+
+	message := restaurant.Name + " is closed"
+	if availability.Note != "" {
+		message += " for " + availability.Note
+	}
+	err := apperrors.Invalid(apperrors.CodeRestaurantClosed, message).
+		WithDetail("restaurant_id", restaurant.ID).
+		WithDetail("reason", availability.Reason)
+	if availability.NextOpensAt != nil {
+		err = err.WithDetail("next_opens_at", *availability.NextOpensAt)
+	}
+	return err
+}
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	_ "time/tzdata" // The time zones of the tests, whatever the host ships
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository/memory"
+)
+
+// everyDay opens a restaurant for lunch and for dinner until 02:00 on every
+// day of the week
+var everyDay = func() []models.OpeningHours {
+	var hours []models.OpeningHours
+	for weekday := 0; weekday < 7; weekday++ {
+		hours = append(hours,
+			models.OpeningHours{Weekday: weekday, OpensAt: "11:00", ClosesAt: "15:00"},
+			models.OpeningHours{Weekday: weekday, OpensAt: "18:00", ClosesAt: "02:00"},
+		)
+	}
+	return hours
+}()
+
+// timeIn returns the time of value, such as 2025-03-03 12:00, in the
+// timezone
+func timeIn(t *testing.T, timezone, value string) time.Time {
+	t.Helper()
+
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestAvailability(t *testing.T) {
+	const kolkata, london = "Asia/Kolkata", "Europe/London"
+	ist := func(value string) time.Time { return timeIn(t, kolkata, value) }
+	at := func(value string) *time.Time {
+		at := ist(value)
+		return &at
+	}
+
+	// 2025-03-03 is a Monday, and 2025-03-30 the Sunday on which London's
+	// clocks go forward from 01:00 to 02:00
+	tests := []struct {
+		name             string
+		timezone         string
+		weekly           []models.OpeningHours
+		exceptions       []models.HoursException
+		lastOrderMinutes int
+		pausedAt         *time.Time
+		pausedUntil      *time.Time
+		now              time.Time
+		want             models.Availability
+	}{
+		{
+			name:   "open for lunch",
+			weekly: everyDay,
+			now:    ist("2025-03-03 12:00"),
+			want:   models.Availability{Open: true, AcceptingOrders: true, ClosesAt: at("2025-03-03 15:00")},
+		},
+		{
+			name:   "between lunch and dinner",
+			weekly: everyDay,
+			now:    ist("2025-03-03 16:00"),
+			want:   models.Availability{Reason: models.UnavailableClosed, NextOpensAt: at("2025-03-03 18:00")},
+		},
+		{
+			name:   "after midnight in the window opened the day before",
+			weekly: everyDay,
+			now:    ist("2025-03-04 01:00"),
+			want:   models.Availability{Open: true, AcceptingOrders: true, ClosesAt: at("2025-03-04 02:00")},
+		},
+		{
+			name:   "after the window past midnight closes",
+			weekly: everyDay,
+			now:    ist("2025-03-04 02:00"),
+			want:   models.Availability{Reason: models.UnavailableClosed, NextOpensAt: at("2025-03-04 11:00")},
+		},
+		{
+			name:   "past midnight locally while still the day before in UTC",
+			weekly: everyDay,
+			now:    time.Date(2025, 3, 2, 20, 0, 0, 0, time.UTC), // 01:30 on Monday in Kolkata
+			want:   models.Availability{Open: true, AcceptingOrders: true, ClosesAt: at("2025-03-03 02:00")},
+		},
+		{
+			name: "windows that touch are one",
+			weekly: []models.OpeningHours{
+				{Weekday: 1, OpensAt: "10:00", ClosesAt: "14:00"},
+				{Weekday: 1, OpensAt: "14:00", ClosesAt: "22:00"},
+			},
+			now:  ist("2025-03-03 12:00"),
+			want: models.Availability{Open: true, AcceptingOrders: true, ClosesAt: at("2025-03-03 22:00")},
+		},
+		{
+			name: "no weekly hours is open all day",
+			now:  ist("2025-03-03 03:00"),
+			want: models.Availability{Open: true, AcceptingOrders: true},
+		},
+		{
+			name:       "closed for a holiday",
+			weekly:     everyDay,
+			exceptions: []models.HoursException{{Date: "2025-03-03", Closed: true, Note: "Holi"}},
+			now:        ist("2025-03-03 12:00"),
+			want: models.Availability{
+				Reason:      models.UnavailableHoliday,
+				Note:        "Holi",
+				NextOpensAt: at("2025-03-04 11:00"),
+			},
+		},
+		{
+			name:       "the window of the day before runs into a holiday",
+			weekly:     everyDay,
+			exceptions: []models.HoursException{{Date: "2025-03-03", Closed: true, Note: "Holi"}},
+			now:        ist("2025-03-03 01:00"),
+			want:       models.Availability{Open: true, AcceptingOrders: true, Note: "Holi", ClosesAt: at("2025-03-03 02:00")},
+		},
+		{
+			name:       "an exception replaces the weekly hours",
+			weekly:     everyDay,
+			exceptions: []models.HoursException{{Date: "2025-03-03", OpensAt: "09:00", ClosesAt: "13:00", Note: "Half day"}},
+			now:        ist("2025-03-03 14:00"),
+			want: models.Availability{
+				Reason:      models.UnavailableClosed,
+				Note:        "Half day",
+				NextOpensAt: at("2025-03-04 11:00"),
+			},
+		},
+		{
+			name:             "just before the last-order cutoff",
+			weekly:           everyDay,
+			lastOrderMinutes: 30,
+			now:              ist("2025-03-03 14:29"),
+			want: models.Availability{
+				Open:            true,
+				AcceptingOrders: true,
+				ClosesAt:        at("2025-03-03 15:00"),
+				LastOrderAt:     at("2025-03-03 14:30"),
+			},
+		},
+		{
+			name:             "at the last-order cutoff",
+			weekly:           everyDay,
+			lastOrderMinutes: 30,
+			now:              ist("2025-03-03 14:30"),
+			want: models.Availability{
+				Open:        true,
+				Reason:      models.UnavailableLastOrders,
+				ClosesAt:    at("2025-03-03 15:00"),
+				LastOrderAt: at("2025-03-03 14:30"),
+			},
+		},
+		{
+			name:     "paused until resumed",
+			weekly:   everyDay,
+			pausedAt: at("2025-03-03 11:30"),
+			now:      ist("2025-03-03 12:00"),
+			want:     models.Availability{Open: true, Reason: models.UnavailablePaused, ClosesAt: at("2025-03-03 15:00")},
+		},
+		{
+			name:        "paused for a while",
+			weekly:      everyDay,
+			pausedAt:    at("2025-03-03 11:30"),
+			pausedUntil: at("2025-03-03 12:30"),
+			now:         ist("2025-03-03 12:00"),
+			want: models.Availability{
+				Open:        true,
+				Reason:      models.UnavailablePaused,
+				ClosesAt:    at("2025-03-03 15:00"),
+				PausedUntil: at("2025-03-03 12:30"),
+			},
+		},
+		{
+			name:        "the pause is over",
+			weekly:      everyDay,
+			pausedAt:    at("2025-03-03 11:30"),
+			pausedUntil: at("2025-03-03 12:30"),
+			now:         ist("2025-03-03 12:30"),
+			want:        models.Availability{Open: true, AcceptingOrders: true, ClosesAt: at("2025-03-03 15:00")},
+		},
+		{
+			name:     "closed while paused",
+			weekly:   everyDay,
+			pausedAt: at("2025-03-03 11:30"),
+			now:      ist("2025-03-03 16:00"),
+			want:     models.Availability{Reason: models.UnavailableClosed, NextOpensAt: at("2025-03-03 18:00")},
+		},
+		{
+			name:     "the night the clocks go forward",
+			timezone: london,
+			weekly:   []models.OpeningHours{{Weekday: 6, OpensAt: "18:00", ClosesAt: "02:00"}},
+			now:      timeIn(t, london, "2025-03-30 00:30"),
+			want: models.Availability{
+				Open:            true,
+				AcceptingOrders: true,
+				ClosesAt:        ptr(time.Date(2025, 3, 30, 1, 0, 0, 0, time.UTC)), // 02:00 BST, half an hour later
+			},
+		},
+		{
+			name:     "the morning after the clocks go back",
+			timezone: london,
+			weekly:   []models.OpeningHours{{Weekday: 0, OpensAt: "09:00", ClosesAt: "17:00"}},
+			now:      timeIn(t, london, "2025-10-26 08:00"),
+			want: models.Availability{
+				Reason:      models.UnavailableClosed,
+				NextOpensAt: ptr(time.Date(2025, 10, 26, 9, 0, 0, 0, time.UTC)), // 09:00 GMT
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.timezone == "" {
+				tt.timezone = kolkata
+			}
+			store := memory.NewStore()
+			restaurant := store.AddRestaurant(models.Restaurant{
+				Slug:        "main",
+				Name:        "Main",
+				Timezone:    tt.timezone,
+				PausedAt:    tt.pausedAt,
+				PausedUntil: tt.pausedUntil,
+			})
+			restaurant.LastOrderMinutes = tt.lastOrderMinutes
+			repo := store.Repos().Restaurants
+			ctx := context.Background()
+			if err := repo.SetWeeklyHours(ctx, restaurant.ID, tt.lastOrderMinutes, tt.weekly); err != nil {
+				t.Fatal(err)
+			}
+			for _, exception := range tt.exceptions {
+				if err := repo.SetException(ctx, restaurant.ID, exception); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := availability(ctx, repo, restaurant, tt.now)
+			if err != nil {
+				t.Fatalf("availability() error = %v", err)
+			}
+			if got.Open != tt.want.Open || got.AcceptingOrders != tt.want.AcceptingOrders ||
+				got.Reason != tt.want.Reason || got.Note != tt.want.Note {
+				t.Errorf("Open, AcceptingOrders, Reason, Note = %v, %v, %q, %q, want %v, %v, %q, %q",
+					got.Open, got.AcceptingOrders, got.Reason, got.Note,
+					tt.want.Open, tt.want.AcceptingOrders, tt.want.Reason, tt.want.Note)
+			}
+			checkTime(t, "ClosesAt", got.ClosesAt, tt.want.ClosesAt)
+			checkTime(t, "LastOrderAt", got.LastOrderAt, tt.want.LastOrderAt)
+			checkTime(t, "NextOpensAt", got.NextOpensAt, tt.want.NextOpensAt)
+			checkTime(t, "PausedUntil", got.PausedUntil, tt.want.PausedUntil)
+		})
+	}
+}
+
+// checkTime reports an optional time of an availability that is not the
+// instant wanted
+func checkTime(t *testing.T, field string, got, want *time.Time) {
+	t.Helper()
+
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil || !got.Equal(*want):
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,515 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// InventoryPublishFunc publishes inventory events in order without blocking
+// the caller
+type InventoryPublishFunc func(ctx context.Context, events []models.InventoryEvent)
+
+// Inventory maintains the ingredients of restaurants, the recipes that use
+// them and the stock of food items, and raises stock alerts for the staff.
+// Paid orders take their items and ingredients out of stock through Orders.
+type Inventory struct {
+	uow       repository.UnitOfWork
+	inventory repository.InventoryRepo
+	menu      repository.MenuRepo
+	alerts    repository.AlertRepo
+	publish   InventoryPublishFunc
+}
+
+// NewInventory creates an Inventory that changes stock in a unit of work and
+// publishes the events of the stock alerts it raises, kept in alerts, with
+// publish. Recipes are checked against the food items and modifiers of menu.
+func NewInventory(uow repository.UnitOfWork, inventory repository.InventoryRepo, menu repository.MenuRepo, alerts repository.AlertRepo, publish InventoryPublishFunc) *Inventory {
+	return &Inventory{uow: uow, inventory: inventory, menu: menu, alerts: alerts, publish: publish}
+}
+
+// Ingredients returns the ingredients of a restaurant, flagging those low on
+// stock
+func (s *Inventory) Ingredients(ctx context.Context, restaurantID int) ([]models.Ingredient, error) {
+	ingredients, err := s.inventory.ListIngredients(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ingredients {
+		flagLowStock(&ingredients[i])
+	}
+	if ingredients == nil {
+		ingredients = []models.Ingredient{}
+	}
+	return ingredients, nil
+}
+
+// CreateIngredient adds an ingredient to a restaurant. Its initial stock is
+// recorded as a restock by the staff member.
+func (s *Inventory) CreateIngredient(ctx context.Context, restaurantID, userID int, request models.IngredientRequest) (models.Ingredient, error) {
+	var ingredient models.Ingredient
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		ingredient = models.Ingredient{
+			RestaurantID:      restaurantID,
+			Name:              request.Name,
+			Unit:              request.Unit,
+			LowStockThreshold: request.LowStockThreshold,
+		}
+		if err := repos.Inventory.CreateIngredient(ctx, &ingredient); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return apperrors.Conflict(apperrors.CodeIngredientExists, "Ingredient already exists: "+request.Name).
+					WithDetail("name", request.Name)
+			}
+			return err
+		}
+		if request.Quantity == 0 {
+			return nil
+		}
+
+		movement := models.StockMovement{
+			IngredientID: ingredient.ID,
+			Change:       request.Quantity,
+			Reason:       models.StockReasonRestock,
+			UserID:       userID,
+			Note:         "Initial stock",
+		}
+		if err := repos.Inventory.AdjustStock(ctx, restaurantID, &movement); err != nil {
+			return err
+		}
+		ingredient.Quantity = movement.QuantityAfter
+		return nil
+	})
+	if err != nil {
+		return models.Ingredient{}, err
+	}
+
+	flagLowStock(&ingredient)
+	return ingredient, nil
+}
+
+// SetThreshold changes the low-stock threshold of an ingredient
+func (s *Inventory) SetThreshold(ctx context.Context, restaurantID, id int, request models.ThresholdRequest) (models.Ingredient, error) {
+	err := s.inventory.SetThreshold(ctx, restaurantID, id, *request.LowStockThreshold)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.Ingredient{}, errIngredientNotFound(id)
+	}
+	if err != nil {
+		return models.Ingredient{}, err
+	}
+	return s.ingredient(ctx, restaurantID, id)
+}
+
+// Adjust changes the stock of an ingredient on behalf of a staff member,
+// recording why, and publishes the stock alerts raised for it and for the
+// food items that use it. Stock above the threshold again resolves the open
+// alerts of the ingredient.
+func (s *Inventory) Adjust(ctx context.Context, restaurantID, userID, id int, request models.StockAdjustmentRequest) (models.Ingredient, error) {
+	// Restocks add stock and waste takes it out, corrections go either way
+	switch {
+	case request.Reason == models.StockReasonRestock && request.Change < 0:
+		return models.Ingredient{}, apperrors.Validation(models.FieldError{Field: "change", Message: "must be positive for a restock"})
+	case request.Reason == models.StockReasonWaste && request.Change > 0:
+		return models.Ingredient{}, apperrors.Validation(models.FieldError{Field: "change", Message: "must be negative for waste"})
+	}
+
+	var ingredient models.Ingredient
+	var alerts []models.StockAlert
+
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		var err error
+		ingredient, err = repos.Inventory.GetIngredient(ctx, restaurantID, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errIngredientNotFound(id)
+			}
+			return err
+		}
+
+		movement := models.StockMovement{
+			IngredientID: id,
+			Change:       request.Change,
+			Reason:       request.Reason,
+			UserID:       userID,
+			Note:         request.Note,
+		}
+		menuAlerts, err := trackStock(ctx, repos, restaurantID, nil, []int{id}, func() error {
+			err := repos.Inventory.AdjustStock(ctx, restaurantID, &movement)
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return errIngredientOutOfStock(ingredient, -request.Change)
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		alerts = nil
+		if alert, ok := lowStockAlert(ingredient, movement); ok {
+			if err := repos.Alerts.Create(ctx, &alert); err != nil {
+				return err
+			}
+			alerts = append(alerts, alert)
+		} else if quantityBefore(movement) <= ingredient.LowStockThreshold && movement.QuantityAfter > ingredient.LowStockThreshold {
+			if err := repos.Alerts.Resolve(ctx, restaurantID, id, 0, 0); err != nil {
+				return err
+			}
+		}
+		alerts = append(alerts, menuAlerts...)
+		ingredient.Quantity = movement.QuantityAfter
+		return nil
+	})
+	if err != nil {
+		return models.Ingredient{}, err
+	}
+
+	s.announce(ctx, alerts)
+	flagLowStock(&ingredient)
+	return ingredient, nil
+}
+
+// RestockFoodItem adds stock to a food item, or to the variant picked for a
+// food item with variants, and publishes the stock alerts raised. Stock back
+// above zero or the threshold resolves the open alerts of the food item.
+func (s *Inventory) RestockFoodItem(ctx context.Context, restaurantID, id int, request models.RestockRequest) (models.FoodItem, error) {
+	var foodItem models.FoodItem
+	var alerts []models.StockAlert
+
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		var err error
+		foodItem, err = repos.Menu.Get(ctx, restaurantID, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errFoodItemNotFound(id)
+			}
+			return err
+		}
+
+		// Food items with variants keep their stock on the variants
+		switch {
+		case len(foodItem.Variants) > 0 && request.VariantID == 0:
+			return errVariantRequired(foodItem)
+		case request.VariantID != 0:
+			if _, ok := findVariant(foodItem, request.VariantID); !ok {
+				return errVariantNotFound(id, request.VariantID)
+			}
+		}
+
+		alerts, err = trackStock(ctx, repos, restaurantID, []int{id}, nil, func() error {
+			return repos.Menu.Restock(ctx, restaurantID, id, request.VariantID, request.Quantity)
+		})
+		if err != nil {
+			return err
+		}
+		foodItem, err = repos.Menu.Get(ctx, restaurantID, id)
+		return err
+	})
+	if err != nil {
+		return models.FoodItem{}, err
+	}
+
+	s.announce(ctx, alerts)
+	return foodItem, nil
+}
+
+// SetStockSettings changes the low-stock threshold of a food item and
+// whether it leaves the menu while sold out
+func (s *Inventory) SetStockSettings(ctx context.Context, restaurantID, id int, request models.StockSettingsRequest) (models.FoodItem, error) {
+	err := s.menu.SetStockSettings(ctx, restaurantID, id, *request.LowStockThreshold, *request.HideWhenSoldOut)
+	if errors.Is(err, repository.ErrNotFound) {
+		return models.FoodItem{}, errFoodItemNotFound(id)
+	}
+	if err != nil {
+		return models.FoodItem{}, err
+	}
+
+	foodItem, err := s.menu.Get(ctx, restaurantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return foodItem, errFoodItemNotFound(id)
+	}
+	return foodItem, err
+}
+
+// Alerts returns the latest limit stock alerts of a restaurant, newest
+// first, or only the open ones unless all is set
+func (s *Inventory) Alerts(ctx context.Context, restaurantID int, all bool, limit int) ([]models.StockAlert, error) {
+	alerts, err := s.alerts.List(ctx, restaurantID, !all, limit)
+	if alerts == nil {
+		alerts = []models.StockAlert{}
+	}
+	return alerts, err
+}
+
+// Acknowledge closes a stock alert on behalf of a staff member
+func (s *Inventory) Acknowledge(ctx context.Context, restaurantID, userID, id int) (models.StockAlert, error) {
+	alert, err := s.alerts.Acknowledge(ctx, restaurantID, id, userID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return alert, apperrors.NotFound(apperrors.CodeAlertNotFound, "Stock alert not found: "+strconv.Itoa(id)).
+			WithDetail("alert_id", id)
+	}
+	return alert, err
+}
+
+// Movements returns the latest limit stock movements of a restaurant,
+// newest first, of one ingredient unless ingredientID is 0
+func (s *Inventory) Movements(ctx context.Context, restaurantID, ingredientID, limit int) ([]models.StockMovement, error) {
+	if ingredientID != 0 {
+		if _, err := s.ingredient(ctx, restaurantID, ingredientID); err != nil {
+			return nil, err
+		}
+	}
+
+	movements, err := s.inventory.Movements(ctx, restaurantID, ingredientID, limit)
+	if movements == nil {
+		movements = []models.StockMovement{}
+	}
+	return movements, err
+}
+
+// Recipes returns the recipes of the food items and modifiers of a
+// restaurant
+func (s *Inventory) Recipes(ctx context.Context, restaurantID int) ([]models.Recipe, error) {
+	recipes, err := s.inventory.Recipes(ctx, restaurantID)
+	if recipes == nil {
+		recipes = []models.Recipe{}
+	}
+	return recipes, err
+}
+
+// SetFoodItemRecipe replaces the recipe of a food item
+func (s *Inventory) SetFoodItemRecipe(ctx context.Context, restaurantID, foodItemID int, request models.RecipeRequest) (models.Recipe, error) {
+	if _, err := s.menu.Get(ctx, restaurantID, foodItemID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.Recipe{}, errFoodItemNotFound(foodItemID)
+		}
+		return models.Recipe{}, err
+	}
+	return s.setRecipe(ctx, restaurantID, models.Recipe{FoodItemID: foodItemID}, request)
+}
+
+// SetModifierRecipe replaces the recipe of a modifier
+func (s *Inventory) SetModifierRecipe(ctx context.Context, restaurantID, modifierID int, request models.RecipeRequest) (models.Recipe, error) {
+	foodItems, err := s.menu.List(ctx, restaurantID)
+	if err != nil {
+		return models.Recipe{}, err
+	}
+	for _, foodItem := range foodItems {
+		for _, group := range foodItem.ModifierGroups {
+			for _, modifier := range group.Modifiers {
+				if modifier.ID == modifierID {
+					return s.setRecipe(ctx, restaurantID, models.Recipe{ModifierID: modifierID}, request)
+				}
+			}
+		}
+	}
+	return models.Recipe{}, apperrors.NotFound(apperrors.CodeModifierNotFound, "Modifier not found: "+strconv.Itoa(modifierID)).
+		WithDetail("modifier_id", modifierID)
+}
+
+// setRecipe checks the ingredients of a recipe request and replaces the
+// recipe with them
+func (s *Inventory) setRecipe(ctx context.Context, restaurantID int, recipe models.Recipe, request models.RecipeRequest) (models.Recipe, error) {
+	recipe.Ingredients = []models.RecipeIngredient{}
+	seen := make(map[int]bool)
+	for i, line := range request.Ingredients {
+		if seen[line.IngredientID] {
+			return models.Recipe{}, apperrors.Validation(models.FieldError{
+				Field:   fmt.Sprintf("ingredients[%d].ingredient_id", i),
+				Message: "must not repeat an ingredient",
+			})
+		}
+		seen[line.IngredientID] = true
+
+		ingredient, err := s.ingredient(ctx, restaurantID, line.IngredientID)
+		if err != nil {
+			return models.Recipe{}, err
+		}
+		line.Name, line.Unit = ingredient.Name, ingredient.Unit
+		recipe.Ingredients = append(recipe.Ingredients, line)
+	}
+
+	if err := s.inventory.SetRecipe(ctx, restaurantID, recipe); err != nil {
+		return models.Recipe{}, err
+	}
+	return recipe, nil
+}
+
+// ingredient returns an ingredient of a restaurant, flagged if low on stock
+func (s *Inventory) ingredient(ctx context.Context, restaurantID, id int) (models.Ingredient, error) {
+	ingredient, err := s.inventory.GetIngredient(ctx, restaurantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ingredient, errIngredientNotFound(id)
+	}
+	flagLowStock(&ingredient)
+	return ingredient, err
+}
+
+// announce publishes the events of stock alerts in the order they were
+// raised
+func (s *Inventory) announce(ctx context.Context, alerts []models.StockAlert) {
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+	events := make([]models.InventoryEvent, 0, len(alerts))
+	for _, alert := range alerts {
+		metrics.StockAlerts.WithLabelValues(alert.Type).Inc()
+		events = append(events, alert.Event())
+	}
+	s.publish(ctx, events)
+}
+
+// ingredientUse returns how much of each ingredient the items of an order
+// use, by ingredient ID, as given by the recipes of the food items and
+// modifiers
+func ingredientUse(ctx context.Context, repos repository.Repos, order models.Order) (map[int]float64, error) {
+	recipes, err := repos.Inventory.Recipes(ctx, order.RestaurantID)
+	if err != nil || len(recipes) == 0 {
+		return nil, err
+	}
+	foodItemRecipes := make(map[int][]models.RecipeIngredient)
+	modifierRecipes := make(map[int][]models.RecipeIngredient)
+	for _, recipe := range recipes {
+		if recipe.ModifierID != 0 {
+			modifierRecipes[recipe.ModifierID] = recipe.Ingredients
+		} else {
+			foodItemRecipes[recipe.FoodItemID] = recipe.Ingredients
+		}
+	}
+
+	// Sum what the items and their modifiers use of each ingredient
+	used := make(map[int]float64)
+	for _, item := range order.AllItems() {
+		for _, line := range foodItemRecipes[item.FoodItemID] {
+			used[line.IngredientID] += line.Quantity * float64(item.Quantity)
+		}
+		for _, modifier := range item.Modifiers {
+			for _, line := range modifierRecipes[modifier.ModifierID] {
+				used[line.IngredientID] += line.Quantity * float64(item.Quantity)
+			}
+		}
+	}
+	return used, nil
+}
+
+// consumeIngredients takes the ingredients used by a paid order, as returned
+// by ingredientUse, out of stock in ingredient ID order so that payments
+// cannot deadlock. It returns the alerts raised for the ingredients that
+// became low on stock.
+func consumeIngredients(ctx context.Context, repos repository.Repos, order models.Order, used map[int]float64) ([]models.StockAlert, error) {
+	ingredientIDs := make([]int, 0, len(used))
+	for id := range used {
+		ingredientIDs = append(ingredientIDs, id)
+	}
+	sort.Ints(ingredientIDs)
+
+	var alerts []models.StockAlert
+	for _, id := range ingredientIDs {
+		ingredient, err := repos.Inventory.GetIngredient(ctx, order.RestaurantID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		quantity := math.Round(used[id]*1000) / 1000
+		movement := models.StockMovement{
+			IngredientID: id,
+			Change:       -quantity,
+			Reason:       models.StockReasonOrder,
+			OrderID:      order.ID,
+			UserID:       order.UserID,
+		}
+		if err := repos.Inventory.AdjustStock(ctx, order.RestaurantID, &movement); err != nil {
+			if errors.Is(err, repository.ErrInsufficientStock) {
+				return nil, errIngredientOutOfStock(ingredient, quantity)
+			}
+			return nil, err
+		}
+		if alert, ok := lowStockAlert(ingredient, movement); ok {
+			if err := repos.Alerts.Create(ctx, &alert); err != nil {
+				return nil, err
+			}
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// lowStockAlert returns the alert of an ingredient that a movement took to
+// or below its threshold, reporting false if it was already low or still is
+// not
+func lowStockAlert(ingredient models.Ingredient, movement models.StockMovement) (models.StockAlert, bool) {
+	if quantityBefore(movement) <= ingredient.LowStockThreshold || movement.QuantityAfter > ingredient.LowStockThreshold {
+		return models.StockAlert{}, false
+	}
+	return models.StockAlert{
+		Type:              models.InventoryLowStock,
+		RestaurantID:      ingredient.RestaurantID,
+		IngredientID:      ingredient.ID,
+		Name:              ingredient.Name,
+		Unit:              ingredient.Unit,
+		Quantity:          movement.QuantityAfter,
+		LowStockThreshold: ingredient.LowStockThreshold,
+	}, true
+}
+
+// quantityBefore returns the stock of an ingredient just before a movement.
+// It is taken from the movement, which was recorded under the lock of the
+// ingredient row, rather than from an earlier read that a concurrent
+// movement may have made stale.
+func quantityBefore(movement models.StockMovement) float64 {
+	return math.Round((movement.QuantityAfter-movement.Change)*1000) / 1000
+}
+
+// portions returns how many portions of each food item with a recipe the
+// ingredients in stock are enough for
+func portions(ctx context.Context, inventory repository.InventoryRepo, restaurantID int) (map[int]int, error) {
+	recipes, err := inventory.Recipes(ctx, restaurantID)
+	if err != nil || len(recipes) == 0 {
+		return nil, err
+	}
+	ingredients, err := inventory.ListIngredients(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+	stock := make(map[int]float64, len(ingredients))
+	for _, ingredient := range ingredients {
+		stock[ingredient.ID] = ingredient.Quantity
+	}
+
+	counts := make(map[int]int)
+	for _, recipe := range recipes {
+		if recipe.ModifierID != 0 {
+			continue
+		}
+		count := math.MaxInt
+		for _, line := range recipe.Ingredients {
+			count = min(count, int(math.Floor(stock[line.IngredientID]/line.Quantity+1e-9)))
+		}
+		counts[recipe.FoodItemID] = count
+	}
+	return counts, nil
+}
+
+// flagLowStock sets whether an ingredient is at or below its threshold
+func flagLowStock(ingredient *models.Ingredient) {
+	ingredient.LowStock = ingredient.Quantity <= ingredient.LowStockThreshold
+}
+
+// errIngredientNotFound reports an ingredient that does not exist
+func errIngredientNotFound(id int) error {
+	return apperrors.NotFound(apperrors.CodeIngredientNotFound, "Ingredient not found: "+strconv.Itoa(id)).
+		WithDetail("ingredient_id", id)
+}
+
+// errIngredientOutOfStock reports an ingredient with less stock than is
+// needed
+func errIngredientOutOfStock(ingredient models.Ingredient, requested float64) error {
+	return apperrors.Invalid(apperrors.CodeOutOfStock, "Not enough of ingredient: "+ingredient.Name).
+		WithDetail("ingredient_id", ingredient.ID).
+		WithDetail("requested", requested).
+		WithDetail("available", ingredient.Quantity)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/restaurant_ordering_service/internal/models"
+)
+
+func TestLowStockAlert(t *testing.T) {
+	// The ingredient was read with 12 in stock, but concurrent movements may
+	// have changed it before the movement locked its row
+	ingredient := models.Ingredient{ID: 1, Name: "Rice", Unit: "kg", Quantity: 12, LowStockThreshold: 10}
+
+	tests := []struct {
+		name     string
+		movement models.StockMovement
+		want     bool
+	}{
+		{"crosses the threshold", models.StockMovement{Change: -3, QuantityAfter: 9}, true},
+		{"lands on the threshold", models.StockMovement{Change: -0.3, QuantityAfter: 10}, true},
+		{"stays above the threshold", models.StockMovement{Change: -1, QuantityAfter: 11}, false},
+		{"already low since the read", models.StockMovement{Change: -1, QuantityAfter: 8}, false},
+		{"fell low since the read", models.StockMovement{Change: -0.1, QuantityAfter: 9.95}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert, ok := lowStockAlert(ingredient, tt.movement)
+			if ok != tt.want {
+				t.Fatalf("lowStockAlert() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && alert.Quantity != tt.movement.QuantityAfter {
+				t.Errorf("Quantity = %v, want %v", alert.Quantity, tt.movement.QuantityAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+	"github.com/shared/logging"
+)
+
+// AnnounceFunc shows a new ticket on the kitchen displays
+type AnnounceFunc func(ticket models.KitchenTicket)
+
+// Kitchen runs the kitchen display: the queue of paid orders by station, the
+// preparation of their items and the bumping and recalling of tickets
+type Kitchen struct {
+	kitchen  repository.KitchenRepo
+	announce AnnounceFunc
+}
+
+// NewKitchen creates a Kitchen that announces new tickets with announce
+func NewKitchen(kitchen repository.KitchenRepo, announce AnnounceFunc) *Kitchen {
+	return &Kitchen{kitchen: kitchen, announce: announce}
+}
+
+// Queue returns the active tickets of a restaurant grouped by station, each
+// with only the items its station prepares. If station is not empty only
+// that station is returned.
+func (k *Kitchen) Queue(ctx context.Context, restaurantID int, station string) ([]models.KitchenStation, error) {
+	tickets, err := k.kitchen.Queue(ctx, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	byStation := make(map[string][]models.KitchenTicket)
+	if station != "" {
+		byStation[station] = []models.KitchenTicket{}
+	}
+	for _, ticket := range tickets {
+		for name, stationTicket := range splitByStation(ticket) {
+			if station == "" || name == station {
+				byStation[name] = append(byStation[name], stationTicket)
+			}
+		}
+	}
+
+	stations := make([]models.KitchenStation, 0, len(byStation))
+	for name, stationTickets := range byStation {
+		stations = append(stations, models.KitchenStation{Station: name, Tickets: stationTickets})
+	}
+	sort.Slice(stations, func(i, j int) bool {
+		return stations[i].Station < stations[j].Station
+	})
+	return stations, nil
+}
+
+// ForStation returns the ticket with only the items station prepares. It
+// reports false if the station has nothing to prepare for the ticket.
+func ForStation(ticket models.KitchenTicket, station string) (models.KitchenTicket, bool) {
+	stationTicket, ok := splitByStation(ticket)[station]
+	return stationTicket, ok
+}
+
+// StartItem marks an item of a ticket as being prepared
+func (k *Kitchen) StartItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error) {
+	item, err := k.getItem(ctx, restaurantID, itemID)
+	if err != nil {
+		return item, err
+	}
+	if item.StartedAt != nil {
+		return item, errItemState(apperrors.CodeItemStarted, "Item has already been started", itemID)
+	}
+
+	if err := k.kitchen.StartItem(ctx, restaurantID, itemID); err != nil {
+		if errors.Is(err, repository.ErrKitchenStateChanged) {
+			return item, errItemState(apperrors.CodeItemStarted, "Item has already been started", itemID)
+		}
+		return item, err
+	}
+	return k.getItem(ctx, restaurantID, itemID)
+}
+
+// FinishItem marks a started item of a ticket as done and records how long
+// it took
+func (k *Kitchen) FinishItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error) {
+	item, err := k.getItem(ctx, restaurantID, itemID)
+	if err != nil {
+		return item, err
+	}
+	if err := checkFinishable(item); err != nil {
+		return item, err
+	}
+
+	if err := k.kitchen.FinishItem(ctx, restaurantID, itemID); err != nil {
+		if errors.Is(err, repository.ErrKitchenStateChanged) {
+			// Report the state the item changed to in the meantime
+			if current, getErr := k.getItem(ctx, restaurantID, itemID); getErr == nil {
+				if stateErr := checkFinishable(current); stateErr != nil {
+					return current, stateErr
+				}
+			}
+		}
+		return item, err
+	}
+
+	item, err = k.getItem(ctx, restaurantID, itemID)
+	if err != nil {
+		return item, err
+	}
+	metrics.PrepTime.WithLabelValues(item.Station).Observe(item.DoneAt.Sub(*item.StartedAt).Seconds())
+	return item, nil
+}
+
+// SetPriority changes the priority of a ticket
+func (k *Kitchen) SetPriority(ctx context.Context, restaurantID, orderID, priority int) (models.KitchenTicket, error) {
+	if err := k.kitchen.SetPriority(ctx, restaurantID, orderID, priority); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return models.KitchenTicket{}, errTicketNotFound(orderID)
+		}
+		return models.KitchenTicket{}, err
+	}
+	return k.getTicket(ctx, restaurantID, orderID)
+}
+
+// Bump takes a ticket off the queue once it has been served
+func (k *Kitchen) Bump(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error) {
+	if err := k.kitchen.Bump(ctx, restaurantID, orderID); err != nil {
+		if errors.Is(err, repository.ErrKitchenStateChanged) {
+			if _, err := k.getTicket(ctx, restaurantID, orderID); err != nil {
+				return models.KitchenTicket{}, err
+			}
+			return models.KitchenTicket{}, apperrors.Conflict(apperrors.CodeTicketBumped, "Ticket has already been bumped").
+				WithDetail("order_id", orderID)
+		}
+		return models.KitchenTicket{}, err
+	}
+	return k.getTicket(ctx, restaurantID, orderID)
+}
+
+// Recall puts a bumped ticket back on the queue
+func (k *Kitchen) Recall(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error) {
+	if err := k.kitchen.Recall(ctx, restaurantID, orderID); err != nil {
+		if errors.Is(err, repository.ErrKitchenStateChanged) {
+			if _, err := k.getTicket(ctx, restaurantID, orderID); err != nil {
+				return models.KitchenTicket{}, err
+			}
+			return models.KitchenTicket{}, apperrors.Conflict(apperrors.CodeTicketNotBumped, "Ticket is still on the queue").
+				WithDetail("order_id", orderID)
+		}
+		return models.KitchenTicket{}, err
+	}
+	return k.getTicket(ctx, restaurantID, orderID)
+}
+
+// PrepTimes returns the average preparation time of each food item of a
+// restaurant
+func (k *Kitchen) PrepTimes(ctx context.Context, restaurantID int) ([]models.PrepTime, error) {
+	prepTimes, err := k.kitchen.PrepTimes(ctx, restaurantID)
+	if prepTimes == nil {
+		prepTimes = []models.PrepTime{}
+	}
+	return prepTimes, err
+}
+
+// HandleOrderEvent announces the ticket of an order that was paid. It is
+// called with the order events of every replica, so that each replica's
+// kitchen displays see every new ticket.
+func (k *Kitchen) HandleOrderEvent(ctx context.Context, event models.OrderEvent) {
+	if event.Status != "completed" {
+		return
+	}
+
+	ticket, err := k.kitchen.GetTicket(ctx, event.RestaurantID, event.OrderID)
+	if err != nil {
+		logging.For(logging.ComponentApp).ErrorContext(ctx, "Failed to load new kitchen ticket", "order_id", event.OrderID, "error", err)
+		return
+	}
+	k.announce(ticket)
+}
+
+// getTicket returns a ticket, reporting one that does not exist
+func (k *Kitchen) getTicket(ctx context.Context, restaurantID, orderID int) (models.KitchenTicket, error) {
+	ticket, err := k.kitchen.GetTicket(ctx, restaurantID, orderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ticket, errTicketNotFound(orderID)
+	}
+	return ticket, err
+}
+
+// getItem returns an item of a ticket, reporting one that does not exist
+func (k *Kitchen) getItem(ctx context.Context, restaurantID, itemID int) (models.KitchenItem, error) {
+	item, err := k.kitchen.GetItem(ctx, restaurantID, itemID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return item, apperrors.NotFound(apperrors.CodeItemNotFound, "Ticket item not found").
+			WithDetail("item_id", itemID)
+	}
+	return item, err
+}
+
+// splitByStation splits a ticket into one per station that prepares some of
+// its items
+func splitByStation(ticket models.KitchenTicket) map[string]models.KitchenTicket {
+	tickets := make(map[string]models.KitchenTicket)
+	for _, item := range ticket.Items {
+		stationTicket, ok := tickets[item.Station]
+		if !ok {
+			stationTicket = ticket
+			stationTicket.Items = nil
+		}
+		stationTicket.Items = append(stationTicket.Items, item)
+		tickets[item.Station] = stationTicket
+	}
+	return tickets
+}
+
+// checkFinishable reports an item that cannot be marked as done
+func checkFinishable(item models.KitchenItem) error {
+	switch {
+	case item.StartedAt == nil:
+		return errItemState(apperrors.CodeItemNotStarted, "Item has not been started", item.ID)
+	case item.DoneAt != nil:
+		return errItemState(apperrors.CodeItemDone, "Item is already done", item.ID)
+	}
+	return nil
+}
+
+// errTicketNotFound reports an order that has no kitchen ticket
+func errTicketNotFound(orderID int) error {
+	return apperrors.NotFound(apperrors.CodeTicketNotFound, "Ticket not found").
+		WithDetail("order_id", orderID)
+}
+
+// errItemState reports an item that is not in the state an action requires
+func errItemState(code apperrors.Code, message string, itemID int) error {
+	return apperrors.Conflict(code, message).WithDetail("item_id", itemID)
+}
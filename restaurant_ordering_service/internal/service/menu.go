@@ -0,0 +1,116 @@
+// Package service holds the business logic of the restaurant service. The
+// REST handlers and the gRPC server both call it, so that a rule is enforced
+// the same way whichever API a caller uses. Errors are apperrors, which each
+// API renders in its own way.
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// Menu reads the food items
+type Menu struct {
+	menu        repository.MenuRepo
+	restaurants repository.RestaurantRepo
+	inventory   repository.InventoryRepo
+}
+
+// NewMenu creates a Menu. The opening hours of the restaurants decide
+// whether their food items can be ordered, and the ingredients in inventory
+// whether those with a recipe are in stock.
+func NewMenu(menu repository.MenuRepo, restaurants repository.RestaurantRepo, inventory repository.InventoryRepo) *Menu {
+	return &Menu{menu: menu, restaurants: restaurants, inventory: inventory}
+}
+
+// List returns the food items of a restaurant, flagging those and the
+// variants that cannot be ordered now because the restaurant does not take
+// orders or they are sold out. Sold out food items that are hidden while
+// sold out are left out.
+func (s *Menu) List(ctx context.Context, restaurant models.Restaurant) ([]models.FoodItem, error) {
+	return s.list(ctx, restaurant, true)
+}
+
+// ListAll returns every food item of a restaurant flagged like List,
+// including those hidden while sold out, for its staff
+func (s *Menu) ListAll(ctx context.Context, restaurant models.Restaurant) ([]models.FoodItem, error) {
+	return s.list(ctx, restaurant, false)
+}
+
+// list returns the flagged food items of a restaurant, leaving out those
+// hidden while sold out if hide is set
+func (s *Menu) list(ctx context.Context, restaurant models.Restaurant, hide bool) ([]models.FoodItem, error) {
+	foodItems, inStock, err := s.stock(ctx, restaurant.ID)
+	if err != nil {
+		return nil, err
+	}
+	availability, err := availability(ctx, s.restaurants, restaurant, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make([]models.FoodItem, 0, len(foodItems))
+	for _, item := range foodItems {
+		// An item with variants is in stock while any of them is
+		itemInStock := inStock[stockKey{item.ID, 0}]
+		if len(item.Variants) > 0 {
+			itemInStock = false
+			for j := range item.Variants {
+				variantInStock := inStock[stockKey{item.ID, item.Variants[j].ID}]
+				item.Variants[j].Available = availability.AcceptingOrders && variantInStock
+				itemInStock = itemInStock || variantInStock
+			}
+		}
+		if hide && item.HideWhenSoldOut && !itemInStock {
+			continue
+		}
+
+		switch {
+		case !availability.AcceptingOrders:
+			item.UnavailableReason = availability.Reason
+		case !itemInStock:
+			item.UnavailableReason = models.UnavailableSoldOut
+		default:
+			item.Available = true
+		}
+		listed = append(listed, item)
+	}
+	return listed, nil
+}
+
+// stock returns the food items of a restaurant and which of them and their
+// variants are in stock. A food item with a recipe is only in stock while
+// its ingredients are enough for a portion.
+func (s *Menu) stock(ctx context.Context, restaurantID int) ([]models.FoodItem, map[stockKey]bool, error) {
+	foodItems, levels, err := stockLevels(ctx, s.menu, s.inventory, restaurantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	inStock := make(map[stockKey]bool, len(levels))
+	for key, level := range levels {
+		inStock[key] = level.quantity > 0
+	}
+	return foodItems, inStock, nil
+}
+
+// Get returns a single food item of a restaurant
+func (s *Menu) Get(ctx context.Context, restaurantID, id int) (models.FoodItem, error) {
+	foodItem, err := s.menu.Get(ctx, restaurantID, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return foodItem, errFoodItemNotFound(id)
+	}
+	return foodItem, err
+}
+
+// errFoodItemNotFound reports a food item that does not exist
+func errFoodItemNotFound(id int) error {
+	return apperrors.NotFound(apperrors.CodeFoodItemNotFound, "Food item not found: "+strconv.Itoa(id)).
+		WithDetail("food_item_id", id)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+// priceOrderItem builds the order item for an item of an order request,
+// checking the variant and modifiers picked against the food item. Its unit
+// price is that of the variant, or else of the food item, plus the price
+// deltas of the modifiers. index is the position of the item in the
+// request, used to name invalid fields.
+func priceOrderItem(foodItem models.FoodItem, request models.OrderItemRequest, index int) (models.OrderItem, error) {
+	item := models.OrderItem{
+		FoodItemID: foodItem.ID,
+		Quantity:   request.Quantity,
+		UnitPrice:  foodItem.Price,
+	}
+
+	// A food item with variants is always ordered as one of them
+	switch {
+	case len(foodItem.Variants) > 0 && request.VariantID == 0:
+		return item, errVariantRequired(foodItem)
+	case request.VariantID != 0:
+		variant, ok := findVariant(foodItem, request.VariantID)
+		if !ok {
+			return item, errVariantNotFound(foodItem.ID, request.VariantID)
+		}
+		item.VariantID, item.Variant, item.UnitPrice = variant.ID, variant.Name, variant.Price
+	}
+
+	// Find the group of each modifier picked
+	groupOf := make(map[int]int) // Index of the group by modifier ID
+	modifiers := make(map[int]models.Modifier)
+	for i, group := range foodItem.ModifierGroups {
+		for _, modifier := range group.Modifiers {
+			groupOf[modifier.ID] = i
+			modifiers[modifier.ID] = modifier
+		}
+	}
+
+	selected := make([]int, len(foodItem.ModifierGroups))
+	for _, modifierID := range request.ModifierIDs {
+		modifier, ok := modifiers[modifierID]
+		if !ok {
+			return item, errModifierNotFound(foodItem.ID, modifierID)
+		}
+		for _, picked := range item.Modifiers {
+			if picked.ModifierID == modifierID {
+				return item, apperrors.Validation(models.FieldError{
+					Field:   fmt.Sprintf("items[%d].modifier_ids", index),
+					Message: "must not repeat a modifier",
+				})
+			}
+		}
+
+		selected[groupOf[modifierID]]++
+		item.Modifiers = append(item.Modifiers, models.OrderItemModifier{
+			ModifierID: modifier.ID,
+			Name:       modifier.Name,
+			PriceDelta: modifier.PriceDelta,
+		})
+		item.UnitPrice += modifier.PriceDelta
+	}
+
+	// Every group must have between its minimum and maximum picked
+	for i, group := range foodItem.ModifierGroups {
+		if selected[i] < group.MinSelections || selected[i] > group.MaxSelections {
+			return item, errModifierSelection(foodItem, group, selected[i])
+		}
+	}
+
+	sort.Slice(item.Modifiers, func(i, j int) bool {
+		return item.Modifiers[i].ModifierID < item.Modifiers[j].ModifierID
+	})
+	// Discounts never take the price below zero
+	item.UnitPrice = math.Max(math.Round(item.UnitPrice*100)/100, 0)
+	return item, nil
+}
+
+// findVariant returns the variant of a food item with the ID
+func findVariant(foodItem models.FoodItem, id int) (models.Variant, bool) {
+	for _, variant := range foodItem.Variants {
+		if variant.ID == id {
+			return variant, true
+		}
+	}
+	return models.Variant{}, false
+}
+
+// itemName names an order item for messages, with its variant if it has one
+func itemName(foodItem models.FoodItem, variant string) string {
+	if variant == "" {
+		return foodItem.Name
+	}
+	return foodItem.Name + " (" + variant + ")"
+}
+
+// errVariantRequired reports a food item ordered without picking one of its
+// variants
+func errVariantRequired(foodItem models.FoodItem) error {
+	variantIDs := make([]int, 0, len(foodItem.Variants))
+	for _, variant := range foodItem.Variants {
+		variantIDs = append(variantIDs, variant.ID)
+	}
+	return apperrors.Invalid(apperrors.CodeVariantRequired, "Pick a variant of "+foodItem.Name).
+		WithDetail("food_item_id", foodItem.ID).
+		WithDetail("variant_ids", variantIDs)
+}
+
+// errVariantNotFound reports a variant that the food item does not have
+func errVariantNotFound(foodItemID, variantID int) error {
+	return apperrors.NotFound(apperrors.CodeVariantNotFound, "Variant not found: "+strconv.Itoa(variantID)).
+		WithDetail("food_item_id", foodItemID).
+		WithDetail("variant_id", variantID)
+}
+
+// errModifierNotFound reports a modifier that the food item does not offer
+func errModifierNotFound(foodItemID, modifierID int) error {
+	return apperrors.NotFound(apperrors.CodeModifierNotFound, "Modifier not found: "+strconv.Itoa(modifierID)).
+		WithDetail("food_item_id", foodItemID).
+		WithDetail("modifier_id", modifierID)
+}
+
+// errModifierSelection reports too few or too many modifiers picked from a
+// group
+func errModifierSelection(foodItem models.FoodItem, group models.ModifierGroup, selected int) error {
+	message := fmt.Sprintf("Pick between %d and %d of %s for %s", group.MinSelections, group.MaxSelections, group.Name, foodItem.Name)
+	if group.MinSelections == group.MaxSelections {
+		message = fmt.Sprintf("Pick %d of %s for %s", group.MinSelections, group.Name, foodItem.Name)
+	}
+	return apperrors.Invalid(apperrors.CodeModifierSelection, message).
+		WithDetail("food_item_id", foodItem.ID).
+		WithDetail("modifier_group_id", group.ID).
+		WithDetail("min_selections", group.MinSelections).
+		WithDetail("max_selections", group.MaxSelections).
+		WithDetail("selected", selected)
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/shared/apperrors"
+)
+
+func TestPriceOrderItem(t *testing.T) {
+	dosa := models.FoodItem{
+		ID:    1,
+		Name:  "Dosa",
+		Price: 8,
+		Variants: []models.Variant{
+			{ID: 11, Name: "Small", Price: 6},
+			{ID: 12, Name: "Large", Price: 10},
+		},
+		ModifierGroups: []models.ModifierGroup{
+			{ID: 21, Name: "Spice", MinSelections: 1, MaxSelections: 1, Modifiers: []models.Modifier{
+				{ID: 31, Name: "Mild"},
+				{ID: 32, Name: "Hot", PriceDelta: 0.5},
+			}},
+			{ID: 22, Name: "Extras", MinSelections: 0, MaxSelections: 2, Modifiers: []models.Modifier{
+				{ID: 41, Name: "Cheese", PriceDelta: 1.5},
+				{ID: 42, Name: "Butter", PriceDelta: 1},
+				{ID: 43, Name: "Staff meal", PriceDelta: -20},
+			}},
+		},
+	}
+	vada := models.FoodItem{ID: 2, Name: "Vada", Price: 4.2}
+
+	tests := []struct {
+		name      string
+		foodItem  models.FoodItem
+		request   models.OrderItemRequest
+		code      apperrors.Code // Of the error, if the item cannot be ordered
+		unitPrice float64
+		modifiers []int
+	}{
+		{
+			name:      "plain food item",
+			foodItem:  vada,
+			request:   models.OrderItemRequest{FoodItemID: vada.ID, Quantity: 2},
+			unitPrice: 4.2,
+		},
+		{
+			name:      "variant and modifiers",
+			foodItem:  dosa,
+			request:   models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 12, ModifierIDs: []int{41, 32}},
+			unitPrice: 12,
+			modifiers: []int{32, 41},
+		},
+		{
+			name:      "discount clamped at zero",
+			foodItem:  dosa,
+			request:   models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 11, ModifierIDs: []int{31, 43}},
+			unitPrice: 0,
+			modifiers: []int{31, 43},
+		},
+		{
+			name:     "variant required",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, ModifierIDs: []int{31}},
+			code:     apperrors.CodeVariantRequired,
+		},
+		{
+			name:     "unknown variant",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 99, ModifierIDs: []int{31}},
+			code:     apperrors.CodeVariantNotFound,
+		},
+		{
+			name:     "variant of a food item without variants",
+			foodItem: vada,
+			request:  models.OrderItemRequest{FoodItemID: vada.ID, Quantity: 1, VariantID: 11},
+			code:     apperrors.CodeVariantNotFound,
+		},
+		{
+			name:     "unknown modifier",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 11, ModifierIDs: []int{31, 99}},
+			code:     apperrors.CodeModifierNotFound,
+		},
+		{
+			name:     "repeated modifier",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 11, ModifierIDs: []int{31, 41, 41}},
+			code:     apperrors.CodeValidationFailed,
+		},
+		{
+			name:     "fewer than the minimum of a group",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 11, ModifierIDs: []int{41}},
+			code:     apperrors.CodeModifierSelection,
+		},
+		{
+			name:     "more than the maximum of a group",
+			foodItem: dosa,
+			request:  models.OrderItemRequest{FoodItemID: dosa.ID, Quantity: 1, VariantID: 11, ModifierIDs: []int{31, 32}},
+			code:     apperrors.CodeModifierSelection,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, err := priceOrderItem(tt.foodItem, tt.request, 0)
+			if tt.code != "" {
+				if !apperrors.HasCode(err, tt.code) {
+					t.Errorf("priceOrderItem() error = %v, want %s", err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("priceOrderItem() error = %v", err)
+			}
+
+			if item.UnitPrice != tt.unitPrice || item.Quantity != tt.request.Quantity || item.VariantID != tt.request.VariantID {
+				t.Errorf("UnitPrice, Quantity, VariantID = %v, %d, %d, want %v, %d, %d",
+					item.UnitPrice, item.Quantity, item.VariantID, tt.unitPrice, tt.request.Quantity, tt.request.VariantID)
+			}
+			var modifiers []int
+			for _, modifier := range item.Modifiers {
+				modifiers = append(modifiers, modifier.ModifierID)
+			}
+			if len(modifiers) != len(tt.modifiers) {
+				t.Fatalf("modifiers = %v, want %v", modifiers, tt.modifiers)
+			}
+			for i := range modifiers {
+				if modifiers[i] != tt.modifiers[i] {
+					t.Errorf("modifiers = %v, want %v in ID order", modifiers, tt.modifiers)
+					break
+				}
+			}
+		})
+	}
+}
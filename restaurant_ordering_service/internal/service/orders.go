@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// PublishFunc publishes an order event without blocking the caller
+type PublishFunc func(ctx context.Context, order models.Order, foodItems map[int]string)
+
+// Orders places, pays for and looks up orders
+type Orders struct {
+	uow       repository.UnitOfWork
+	orders    repository.OrderRepo
+	inventory *Inventory
+	publish   PublishFunc
+}
+
+// NewOrders creates an Orders that changes orders in a unit of work, reads
+// them from orders and publishes the resulting order events with publish.
+// Paid orders announce the stock alerts they raised through inventory.
+func NewOrders(uow repository.UnitOfWork, orders repository.OrderRepo, inventory *Inventory, publish PublishFunc) *Orders {
+	return &Orders{uow: uow, orders: orders, inventory: inventory, publish: publish}
+}
+
+// Get returns an order with its items
+func (s *Orders) Get(ctx context.Context, id int) (models.Order, error) {
+	order, err := s.orders.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return order, errOrderNotFound(id)
+	}
+	return order, err
+}
+
+// GetOwned returns an order of the user with its items
+func (s *Orders) GetOwned(ctx context.Context, userID, id int) (models.Order, error) {
+	order, err := s.Get(ctx, id)
+	if err != nil {
+		return order, err
+	}
+	if order.UserID != userID {
+		return models.Order{}, errOrderNotOwned()
+	}
+	return order, nil
+}
+
+// Place creates an order of items and bundles for the user at a restaurant
+// and publishes it. The restaurant's tax is added to the total.
+func (s *Orders) Place(ctx context.Context, userID int, restaurant models.Restaurant, request models.OrderRequest) (models.Order, error) {
+	if len(request.Items) == 0 && len(request.Bundles) == 0 {
+		return models.Order{}, apperrors.Validation(models.FieldError{
+			Field:   "items",
+			Message: "must have at least one item or bundle",
+		})
+	}
+
+	var order models.Order
+	var foodItems map[int]string // Map to store food item names for event publishing
+
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		// Only take orders within the opening hours, before the last-order
+		// cutoff and while ordering is not paused
+		availability, err := availability(ctx, repos.Restaurants, restaurant, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := checkAvailable(restaurant, availability); err != nil {
+			return err
+		}
+
+		// Start from scratch in case the unit of work is retried
+		order = models.Order{
+			RestaurantID: restaurant.ID,
+			UserID:       userID,
+			OrderItems:   make([]models.OrderItem, 0, len(request.Items)),
+			Currency:     restaurant.Currency,
+			Status:       "pending",
+		}
+		foodItems = make(map[int]string)
+
+		// Calculate total price and check if items exist on the restaurant's
+		// menu with the variants and modifiers picked
+		for i, item := range request.Items {
+			foodItem, err := repos.Menu.Get(ctx, restaurant.ID, item.FoodItemID)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return errFoodItemNotFound(item.FoodItemID)
+				}
+				return err
+			}
+
+			orderItem, err := priceOrderItem(foodItem, item, i)
+			if err != nil {
+				return err
+			}
+			order.TotalPrice += orderItem.UnitPrice * float64(item.Quantity)
+			order.OrderItems = append(order.OrderItems, orderItem)
+			foodItems[item.FoodItemID] = foodItem.Name
+		}
+
+		// Add the bundles with the items that fill their slots
+		for i, bundle := range request.Bundles {
+			orderBundle, err := buildOrderBundle(ctx, repos.Menu, restaurant.ID, bundle, i, foodItems)
+			if err != nil {
+				return err
+			}
+			order.TotalPrice += orderBundle.UnitPrice * float64(bundle.Quantity)
+			order.Bundles = append(order.Bundles, orderBundle)
+		}
+		order.Tax = taxOn(restaurant, order.TotalPrice)
+		order.TotalPrice += order.Tax
+
+		// Turn away items that are sold out now rather than at payment
+		if err := checkStock(ctx, repos, order); err != nil {
+			return err
+		}
+
+		// Create the order and its items
+		return repos.Orders.Create(ctx, &order)
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	metrics.OrdersPlaced.Inc()
+
+	// Publish order event to Kafka
+	s.publish(ctx, order, foodItems)
+	return order, nil
+}
+
+// Pay completes a pending order of the user, taking its items and their
+// ingredients out of stock, and publishes it along with the stock alerts it
+// raised
+func (s *Orders) Pay(ctx context.Context, userID, orderID int) (models.Order, error) {
+	var order models.Order
+	var foodItems map[int]string // For Kafka event
+	var alerts []models.StockAlert
+
+	err := s.uow.Do(ctx, func(repos repository.Repos) error {
+		foodItems = make(map[int]string)
+
+		// Get order details to verify ownership, locking the order so that a
+		// concurrent payment waits and then sees it is no longer pending
+		var err error
+		order, err = repos.Orders.GetForUpdate(ctx, orderID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return errOrderNotFound(orderID)
+			}
+			return err
+		}
+
+		// Ensure the order belongs to the authenticated user
+		if order.UserID != userID {
+			return errOrderNotOwned()
+		}
+
+		// Ensure the order is in 'pending' status
+		if order.Status != "pending" {
+			return errOrderNotPending(order.ID)
+		}
+
+		// Take the items and the ingredients of their recipes out of stock,
+		// raising the alerts of the items it leaves low or sold out
+		items := stockByItem(order.AllItems())
+		foodItemIDs := make([]int, 0, len(items))
+		for _, item := range items {
+			foodItemIDs = append(foodItemIDs, item.FoodItemID)
+		}
+		used, err := ingredientUse(ctx, repos, order)
+		if err != nil {
+			return err
+		}
+		ingredientIDs := make([]int, 0, len(used))
+		for id := range used {
+			ingredientIDs = append(ingredientIDs, id)
+		}
+
+		var ingredientAlerts []models.StockAlert
+		menuAlerts, err := trackStock(ctx, repos, order.RestaurantID, foodItemIDs, ingredientIDs, func() error {
+			// Update food item and variant quantities, including those of the
+			// components of bundles. The food items were locked in ID order
+			// by trackStock, so transactions touching the same items cannot
+			// deadlock.
+			for _, item := range items {
+				foodItem, err := repos.Menu.Get(ctx, order.RestaurantID, item.FoodItemID)
+				if err != nil {
+					return err
+				}
+
+				available := foodItem.Quantity
+				if item.VariantID != 0 {
+					variant, ok := findVariant(foodItem, item.VariantID)
+					if !ok {
+						return errVariantNotFound(item.FoodItemID, item.VariantID)
+					}
+					available = variant.Quantity
+					err = repos.Menu.DecrementVariantStock(ctx, order.RestaurantID, item.VariantID, item.Quantity)
+				} else {
+					err = repos.Menu.DecrementStock(ctx, order.RestaurantID, item.FoodItemID, item.Quantity)
+				}
+				if err != nil {
+					if errors.Is(err, repository.ErrInsufficientStock) {
+						return errFoodItemOutOfStock(foodItem, item, available)
+					}
+					return err
+				}
+
+				foodItems[item.FoodItemID] = foodItem.Name
+			}
+
+			lowStock, err := consumeIngredients(ctx, repos, order, used)
+			ingredientAlerts = lowStock
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		alerts = append(ingredientAlerts, menuAlerts...)
+
+		// Update order status
+		if err := repos.Orders.UpdateStatus(ctx, order.ID, "pending", "completed"); err != nil {
+			if errors.Is(err, repository.ErrStatusChanged) {
+				return errOrderNotPending(order.ID)
+			}
+			return err
+		}
+		order.Status = "completed"
+
+		// Send the paid order to the kitchen
+		return repos.Kitchen.CreateTicket(ctx, order.ID)
+	})
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	metrics.OrdersCompleted.Inc()
+	metrics.Revenue.Add(order.TotalPrice)
+
+	// Publish completed order event to Kafka asynchronously
+	s.publish(ctx, order, foodItems)
+	s.inventory.announce(ctx, alerts)
+	return order, nil
+}
+
+// IsFinal reports whether an order in status will not change again
+func IsFinal(status string) bool {
+	return status == "completed" || status == "cancelled"
+}
+
+// stockByItem sums the quantity ordered of each food item and variant,
+// sorted by food item ID and then variant ID
+func stockByItem(items []models.OrderItem) []models.OrderItem {
+	quantities := make(map[stockKey]int)
+	variants := make(map[int]string) // Name by variant ID
+	for _, item := range items {
+		quantities[stockKey{item.FoodItemID, item.VariantID}] += item.Quantity
+		variants[item.VariantID] = item.Variant
+	}
+
+	totals := make([]models.OrderItem, 0, len(quantities))
+	for key, quantity := range quantities {
+		totals = append(totals, models.OrderItem{
+			FoodItemID: key.foodItemID,
+			VariantID:  key.variantID,
+			Variant:    variants[key.variantID],
+			Quantity:   quantity,
+		})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].FoodItemID != totals[j].FoodItemID {
+			return totals[i].FoodItemID < totals[j].FoodItemID
+		}
+		return totals[i].VariantID < totals[j].VariantID
+	})
+	return totals
+}
+
+// errOrderNotFound reports an order that does not exist
+func errOrderNotFound(id int) error {
+	return apperrors.NotFound(apperrors.CodeOrderNotFound, "Order not found").
+		WithDetail("order_id", id)
+}
+
+// errOrderNotOwned reports an order of another user
+func errOrderNotOwned() error {
+	return apperrors.Forbidden(apperrors.CodeOrderNotOwned, "You do not have permission to process this order")
+}
+
+// errOrderNotPending reports an order that can no longer be paid for
+func errOrderNotPending(orderID int) error {
+	return apperrors.Invalid(apperrors.CodeOrderNotPending, "Order is not in pending status").
+		WithDetail("order_id", orderID)
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository/memory"
+	"github.com/shared/apperrors"
+)
+
+// orderFixture is a restaurant with a customer and a food item, served by
+// Orders over the in-memory repositories
+type orderFixture struct {
+	store      *memory.Store
+	orders     *Orders
+	restaurant models.Restaurant
+	customer   models.User
+	foodItem   models.FoodItem
+
+	mu        sync.Mutex
+	published []models.Order
+}
+
+// newOrderFixture creates a fixture whose food item has quantity in stock
+func newOrderFixture(t *testing.T, quantity int) *orderFixture {
+	t.Helper()
+
+	f := &orderFixture{store: memory.NewStore()}
+	f.restaurant = f.store.AddRestaurant(models.Restaurant{Slug: "main", Name: "Main", TaxRate: 0.05})
+	f.customer = f.store.AddUser(models.User{Username: "customer", Password: "secret"})
+	f.foodItem = f.store.AddFoodItem(models.FoodItem{
+		RestaurantID: f.restaurant.ID,
+		Name:         "Dosa",
+		Price:        10,
+		Quantity:     quantity,
+	})
+
+	repos := f.store.Repos()
+	inventory := NewInventory(f.store, repos.Inventory, repos.Menu, repos.Alerts, func(context.Context, []models.InventoryEvent) {})
+	f.orders = NewOrders(f.store, repos.Orders, inventory, func(_ context.Context, order models.Order, _ map[int]string) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.published = append(f.published, order)
+	})
+	return f
+}
+
+// place places an order of quantity of the food item for the customer
+func (f *orderFixture) place(t *testing.T, quantity int) models.Order {
+	t.Helper()
+
+	order, err := f.orders.Place(context.Background(), f.customer.ID, f.restaurant, models.OrderRequest{
+		Items: []models.OrderItemRequest{{FoodItemID: f.foodItem.ID, Quantity: quantity}},
+	})
+	if err != nil {
+		t.Fatalf("Place() error = %v", err)
+	}
+	return order
+}
+
+// stock returns the quantity of the food item left in stock
+func (f *orderFixture) stock(t *testing.T) int {
+	t.Helper()
+
+	foodItem, err := f.store.Repos().Menu.Get(context.Background(), f.restaurant.ID, f.foodItem.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return foodItem.Quantity
+}
+
+func TestPlaceOrderAddsTax(t *testing.T) {
+	f := newOrderFixture(t, 10)
+
+	order := f.place(t, 2)
+	if order.Status != "pending" {
+		t.Errorf("Status = %q, want pending", order.Status)
+	}
+	if order.Tax != 1 || order.TotalPrice != 21 {
+		t.Errorf("Tax, TotalPrice = %v, %v, want 1, 21", order.Tax, order.TotalPrice)
+	}
+	if len(f.published) != 1 || f.published[0].ID != order.ID {
+		t.Errorf("published %v, want the placed order", f.published)
+	}
+	if got := f.stock(t); got != 10 {
+		t.Errorf("stock = %d after placing, want it untouched until payment", got)
+	}
+}
+
+func TestPlaceOrderRejects(t *testing.T) {
+	f := newOrderFixture(t, 1)
+
+	tests := []struct {
+		name    string
+		request models.OrderRequest
+		code    apperrors.Code
+	}{
+		{"no items", models.OrderRequest{}, apperrors.CodeValidationFailed},
+		{"unknown food item", models.OrderRequest{
+			Items: []models.OrderItemRequest{{FoodItemID: 999, Quantity: 1}},
+		}, apperrors.CodeFoodItemNotFound},
+		{"more than in stock", models.OrderRequest{
+			Items: []models.OrderItemRequest{{FoodItemID: f.foodItem.ID, Quantity: 2}},
+		}, apperrors.CodeOutOfStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.orders.Place(context.Background(), f.customer.ID, f.restaurant, tt.request)
+			if !apperrors.HasCode(err, tt.code) {
+				t.Errorf("Place() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+	if len(f.published) != 0 {
+		t.Errorf("published %d orders, want none", len(f.published))
+	}
+}
+
+func TestPayOrderTakesItemsOutOfStock(t *testing.T) {
+	f := newOrderFixture(t, 10)
+	order := f.place(t, 3)
+
+	paid, err := f.orders.Pay(context.Background(), f.customer.ID, order.ID)
+	if err != nil {
+		t.Fatalf("Pay() error = %v", err)
+	}
+	if paid.Status != "completed" {
+		t.Errorf("Status = %q, want completed", paid.Status)
+	}
+	if got := f.stock(t); got != 7 {
+		t.Errorf("stock = %d, want 7", got)
+	}
+
+	// The order can only be paid for once
+	_, err = f.orders.Pay(context.Background(), f.customer.ID, order.ID)
+	if !apperrors.HasCode(err, apperrors.CodeOrderNotPending) {
+		t.Errorf("second Pay() error = %v, want %s", err, apperrors.CodeOrderNotPending)
+	}
+	if got := f.stock(t); got != 7 {
+		t.Errorf("stock = %d after the second payment, want 7", got)
+	}
+}
+
+func TestPayOrderRejects(t *testing.T) {
+	f := newOrderFixture(t, 10)
+	order := f.place(t, 1)
+	other := f.store.AddUser(models.User{Username: "other"})
+
+	tests := []struct {
+		name    string
+		userID  int
+		orderID int
+		code    apperrors.Code
+	}{
+		{"unknown order", f.customer.ID, 999, apperrors.CodeOrderNotFound},
+		{"order of another user", other.ID, order.ID, apperrors.CodeOrderNotOwned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.orders.Pay(context.Background(), tt.userID, tt.orderID)
+			if !apperrors.HasCode(err, tt.code) {
+				t.Errorf("Pay() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestPayOrderSoldOutSincePlaced(t *testing.T) {
+	f := newOrderFixture(t, 2)
+	first := f.place(t, 2)
+	second := f.place(t, 2)
+
+	if _, err := f.orders.Pay(context.Background(), f.customer.ID, first.ID); err != nil {
+		t.Fatalf("Pay() error = %v", err)
+	}
+	_, err := f.orders.Pay(context.Background(), f.customer.ID, second.ID)
+	if !apperrors.HasCode(err, apperrors.CodeOutOfStock) {
+		t.Fatalf("Pay() error = %v, want %s", err, apperrors.CodeOutOfStock)
+	}
+
+	// The failed payment leaves the order pending and the stock alone
+	order, err := f.orders.Get(context.Background(), second.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != "pending" {
+		t.Errorf("Status = %q, want pending", order.Status)
+	}
+	if got := f.stock(t); got != 0 {
+		t.Errorf("stock = %d, want 0", got)
+	}
+}
+
+func TestPayOrderVariantRemovedSincePlaced(t *testing.T) {
+	f := newOrderFixture(t, 10)
+	lassi := f.store.AddFoodItem(models.FoodItem{RestaurantID: f.restaurant.ID, Name: "Lassi", Price: 3, Variants: []models.Variant{
+		{Name: "Sweet", Price: 3, Quantity: 5},
+		{Name: "Mango", Price: 4, Quantity: 5},
+	}})
+	order, err := f.orders.Place(context.Background(), f.customer.ID, f.restaurant, models.OrderRequest{
+		Items: []models.OrderItemRequest{{FoodItemID: lassi.ID, VariantID: lassi.Variants[1].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("Place() error = %v", err)
+	}
+
+	lassi.Variants = lassi.Variants[:1]
+	f.store.AddFoodItem(lassi)
+	_, err = f.orders.Pay(context.Background(), f.customer.ID, order.ID)
+	if !apperrors.HasCode(err, apperrors.CodeVariantNotFound) {
+		t.Errorf("Pay() error = %v, want %s", err, apperrors.CodeVariantNotFound)
+	}
+}
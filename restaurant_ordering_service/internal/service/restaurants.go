@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// Restaurants looks up restaurants and maintains their opening hours
+type Restaurants struct {
+	uow         repository.UnitOfWork
+	restaurants repository.RestaurantRepo
+}
+
+// NewRestaurants creates a Restaurants. Weekly hours are replaced in a unit
+// of work so that a restaurant is never seen without them.
+func NewRestaurants(uow repository.UnitOfWork, restaurants repository.RestaurantRepo) *Restaurants {
+	return &Restaurants{uow: uow, restaurants: restaurants}
+}
+
+// List returns every restaurant
+func (s *Restaurants) List(ctx context.Context) ([]models.Restaurant, error) {
+	restaurants, err := s.restaurants.List(ctx)
+	if restaurants == nil {
+		restaurants = []models.Restaurant{}
+	}
+	return restaurants, err
+}
+
+// Get returns a restaurant by ID
+func (s *Restaurants) Get(ctx context.Context, id int) (models.Restaurant, error) {
+	restaurant, err := s.restaurants.Get(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return restaurant, errRestaurantNotFound(strconv.Itoa(id))
+	}
+	return restaurant, err
+}
+
+// Resolve returns the restaurant named by ref, which is either its ID or
+// its slug
+func (s *Restaurants) Resolve(ctx context.Context, ref string) (models.Restaurant, error) {
+	var restaurant models.Restaurant
+	var err error
+	if id, convErr := strconv.Atoi(ref); convErr == nil {
+		restaurant, err = s.restaurants.Get(ctx, id)
+	} else {
+		restaurant, err = s.restaurants.GetBySlug(ctx, ref)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return restaurant, errRestaurantNotFound(ref)
+	}
+	return restaurant, err
+}
+
+// taxOn returns the tax of a restaurant on subtotal, rounded to cents
+func taxOn(restaurant models.Restaurant, subtotal float64) float64 {
+	return math.Round(subtotal*restaurant.TaxRate*100) / 100
+}
+
+// errRestaurantNotFound reports a restaurant that does not exist
+func errRestaurantNotFound(ref string) error {
+	return apperrors.NotFound(apperrors.CodeRestaurantNotFound, "Restaurant not found: "+ref).
+		WithDetail("restaurant", ref)
+}
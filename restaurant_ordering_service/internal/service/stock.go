@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/restaurant_ordering_service/internal/metrics"
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/shared/apperrors"
+)
+
+// stockKey identifies a food item, or one of its variants, in stock
+type stockKey struct{ foodItemID, variantID int }
+
+// stockLevel is the stock of a food item, or of one of its variants
+type stockLevel struct {
+	name      string
+	variant   string
+	quantity  int // The lower of its own stock and the portions its ingredients are enough for
+	threshold int
+}
+
+// stockLevels returns the food items of a restaurant and the stock levels of
+// those without variants and of the variants of the others
+func stockLevels(ctx context.Context, menu repository.MenuRepo, inventory repository.InventoryRepo, restaurantID int) ([]models.FoodItem, map[stockKey]stockLevel, error) {
+	foodItems, err := menu.List(ctx, restaurantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	levels, err := levelsOf(ctx, inventory, restaurantID, foodItems)
+	if err != nil {
+		return nil, nil, err
+	}
+	return foodItems, levels, nil
+}
+
+// lockStockLevels locks the food items of a restaurant with the given IDs
+// and returns their stock levels
+func lockStockLevels(ctx context.Context, repos repository.Repos, restaurantID int, ids []int) (map[stockKey]stockLevel, error) {
+	foodItems, err := repos.Menu.ListForUpdate(ctx, restaurantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	return levelsOf(ctx, repos.Inventory, restaurantID, foodItems)
+}
+
+// levelsOf returns the stock levels of the food items without variants and
+// of the variants of the others
+func levelsOf(ctx context.Context, inventory repository.InventoryRepo, restaurantID int, foodItems []models.FoodItem) (map[stockKey]stockLevel, error) {
+	counts, err := portions(ctx, inventory, restaurantID)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[stockKey]stockLevel)
+	for _, item := range foodItems {
+		level := stockLevel{name: item.Name, threshold: item.LowStockThreshold}
+		if len(item.Variants) == 0 {
+			level.quantity = item.Quantity
+			if n, ok := counts[item.ID]; ok {
+				level.quantity = min(level.quantity, n)
+			}
+			levels[stockKey{item.ID, 0}] = level
+		}
+		for _, variant := range item.Variants {
+			level.variant, level.quantity = variant.Name, variant.Quantity
+			if n, ok := counts[item.ID]; ok {
+				level.quantity = min(level.quantity, n)
+			}
+			levels[stockKey{item.ID, variant.ID}] = level
+		}
+	}
+	return levels, nil
+}
+
+// trackStock runs change, which changes the stock of the given food items of
+// a restaurant or of the given ingredients, and raises the stock alerts of
+// the food items and variants it left low on stock, sold out or back in
+// stock. A food item back in stock or above its threshold again resolves its
+// open alerts. It returns the alerts raised, in food item and variant order.
+//
+// The food items changed, and those whose recipes use the ingredients
+// changed, are locked in ID order before their stock is read, so that
+// concurrent changes cannot slip in between the reads before and after the
+// change. Every stock change goes through here, and takes the locks of
+// ingredients only after those of food items.
+func trackStock(ctx context.Context, repos repository.Repos, restaurantID int, foodItemIDs, ingredientIDs []int, change func() error) ([]models.StockAlert, error) {
+	ids, err := affectedFoodItems(ctx, repos.Inventory, restaurantID, foodItemIDs, ingredientIDs)
+	if err != nil {
+		return nil, err
+	}
+	before, err := lockStockLevels(ctx, repos, restaurantID, ids)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(); err != nil {
+		return nil, err
+	}
+	after, err := lockStockLevels(ctx, repos, restaurantID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]stockKey, 0, len(after))
+	for key := range after {
+		if _, ok := before[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].foodItemID != keys[j].foodItemID {
+			return keys[i].foodItemID < keys[j].foodItemID
+		}
+		return keys[i].variantID < keys[j].variantID
+	})
+
+	var alerts []models.StockAlert
+	raise := func(key stockKey, level stockLevel, alertType string, acknowledged bool) error {
+		alert := models.StockAlert{
+			RestaurantID:      restaurantID,
+			Type:              alertType,
+			FoodItemID:        key.foodItemID,
+			VariantID:         key.variantID,
+			Name:              level.name,
+			Variant:           level.variant,
+			Quantity:          float64(level.quantity),
+			LowStockThreshold: float64(level.threshold),
+		}
+		if acknowledged {
+			now := time.Now().UTC()
+			alert.AcknowledgedAt = &now
+		}
+		if err := repos.Alerts.Create(ctx, &alert); err != nil {
+			return err
+		}
+		alerts = append(alerts, alert)
+		return nil
+	}
+
+	for _, key := range keys {
+		was, now := before[key].quantity, after[key]
+		switch {
+		case was > 0 && now.quantity == 0:
+			err = raise(key, now, models.MenuItemSoldOut, false)
+		case was == 0 && now.quantity > 0:
+			// Nobody needs to act on an item back in stock, so its alert is
+			// raised closed, along with a new one if it is still low
+			err = repos.Alerts.Resolve(ctx, restaurantID, 0, key.foodItemID, key.variantID)
+			if err == nil {
+				err = raise(key, now, models.MenuItemRestored, true)
+			}
+			if err == nil && now.quantity <= now.threshold {
+				err = raise(key, now, models.MenuItemLowStock, false)
+			}
+		case was > now.threshold && now.quantity <= now.threshold:
+			err = raise(key, now, models.MenuItemLowStock, false)
+		case was <= now.threshold && now.quantity > now.threshold:
+			err = repos.Alerts.Resolve(ctx, restaurantID, 0, key.foodItemID, key.variantID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return alerts, nil
+}
+
+// affectedFoodItems returns, in ID order, the given food items together with
+// the food items whose recipes use the given ingredients, and so whose
+// portions change along with them
+func affectedFoodItems(ctx context.Context, inventory repository.InventoryRepo, restaurantID int, foodItemIDs, ingredientIDs []int) ([]int, error) {
+	affected := make(map[int]bool)
+	for _, id := range foodItemIDs {
+		affected[id] = true
+	}
+
+	if len(ingredientIDs) > 0 {
+		changed := make(map[int]bool, len(ingredientIDs))
+		for _, id := range ingredientIDs {
+			changed[id] = true
+		}
+		recipes, err := inventory.Recipes(ctx, restaurantID)
+		if err != nil {
+			return nil, err
+		}
+		for _, recipe := range recipes {
+			if recipe.ModifierID != 0 {
+				continue
+			}
+			for _, line := range recipe.Ingredients {
+				if changed[line.IngredientID] {
+					affected[recipe.FoodItemID] = true
+				}
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(affected))
+	for id := range affected {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// checkStock returns an error if a restaurant has less stock left of the
+// food items and variants of an order than it asks for
+func checkStock(ctx context.Context, repos repository.Repos, order models.Order) error {
+	foodItems, levels, err := stockLevels(ctx, repos.Menu, repos.Inventory, order.RestaurantID)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]models.FoodItem, len(foodItems))
+	for _, foodItem := range foodItems {
+		byID[foodItem.ID] = foodItem
+	}
+
+	for _, item := range stockByItem(order.AllItems()) {
+		level := levels[stockKey{item.FoodItemID, item.VariantID}]
+		if level.quantity < item.Quantity {
+			return errFoodItemOutOfStock(byID[item.FoodItemID], item, level.quantity)
+		}
+	}
+	return nil
+}
+
+// errFoodItemOutOfStock reports a food item, or a variant of it, with less
+// stock left than is needed
+func errFoodItemOutOfStock(foodItem models.FoodItem, item models.OrderItem, available int) error {
+	metrics.StockOuts.WithLabelValues(foodItem.Name).Inc()
+	outOfStock := apperrors.Invalid(apperrors.CodeOutOfStock, "Not enough quantity for food item: "+itemName(foodItem, item.Variant)).
+		WithDetail("food_item_id", foodItem.ID).
+		WithDetail("requested", item.Quantity).
+		WithDetail("available", available)
+	if item.VariantID != 0 {
+		outOfStock = outOfStock.WithDetail("variant_id", item.VariantID)
+	}
+	return outOfStock
+}
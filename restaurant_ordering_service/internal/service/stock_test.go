@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/restaurant_ordering_service/internal/models"
+	"github.com/restaurant_ordering_service/internal/repository"
+	"github.com/restaurant_ordering_service/internal/repository/memory"
+)
+
+// lockingMenu records the food items locked through a menu repository and
+// counts the times the whole menu is listed
+type lockingMenu struct {
+	repository.MenuRepo
+	locked [][]int
+	listed int
+}
+
+func (m *lockingMenu) List(ctx context.Context, restaurantID int) ([]models.FoodItem, error) {
+	m.listed++
+	return m.MenuRepo.List(ctx, restaurantID)
+}
+
+func (m *lockingMenu) ListForUpdate(ctx context.Context, restaurantID int, ids []int) ([]models.FoodItem, error) {
+	m.locked = append(m.locked, ids)
+	return m.MenuRepo.ListForUpdate(ctx, restaurantID, ids)
+}
+
+func TestTrackStockLocksTouchedFoodItems(t *testing.T) {
+	store := memory.NewStore()
+	restaurant := store.AddRestaurant(models.Restaurant{Slug: "main", Name: "Main"})
+	rice := store.AddIngredient(models.Ingredient{RestaurantID: restaurant.ID, Name: "Rice", Unit: "kg", Quantity: 10})
+	dosa := store.AddFoodItem(models.FoodItem{RestaurantID: restaurant.ID, Name: "Dosa", Price: 10, Quantity: 100, LowStockThreshold: 5})
+	store.AddFoodItem(models.FoodItem{RestaurantID: restaurant.ID, Name: "Vada", Price: 5, Quantity: 100})
+	idli := store.AddFoodItem(models.FoodItem{RestaurantID: restaurant.ID, Name: "Idli", Price: 5, Quantity: 100})
+	store.AddRecipe(models.Recipe{FoodItemID: dosa.ID, Ingredients: []models.RecipeIngredient{{IngredientID: rice.ID, Quantity: 1}}})
+
+	// Wasting rice leaves enough for 4 dosas, and Idli is changed directly;
+	// Vada is touched by neither
+	var menu *lockingMenu
+	var alerts []models.StockAlert
+	err := store.Do(context.Background(), func(repos repository.Repos) error {
+		menu = &lockingMenu{MenuRepo: repos.Menu}
+		repos.Menu = menu
+		var err error
+		alerts, err = trackStock(context.Background(), repos, restaurant.ID, []int{idli.ID}, []int{rice.ID}, func() error {
+			return repos.Inventory.AdjustStock(context.Background(), restaurant.ID, &models.StockMovement{
+				IngredientID: rice.ID,
+				Change:       -6,
+				Reason:       models.StockReasonWaste,
+			})
+		})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("trackStock() error = %v", err)
+	}
+
+	want := []int{dosa.ID, idli.ID}
+	if len(menu.locked) != 2 || !reflect.DeepEqual(menu.locked[0], want) || !reflect.DeepEqual(menu.locked[1], want) {
+		t.Errorf("locked %v, want %v before and after the change", menu.locked, want)
+	}
+	if menu.listed != 0 {
+		t.Errorf("listed the whole menu %d times, want none", menu.listed)
+	}
+	if len(alerts) != 1 || alerts[0].FoodItemID != dosa.ID || alerts[0].Type != models.MenuItemLowStock || alerts[0].Quantity != 4 {
+		t.Errorf("alerts %+v, want Dosa low on stock with 4 left", alerts)
+	}
+}
@@ -0,0 +1,8 @@
+// Package client is a typed Go client for the Restaurant Ordering Service.
+// The Client type, the request and response types and the operation methods
+// in client_gen.go are generated from the service's OpenAPI document; run go
+// generate after changing a route. New creates a client for the service at
+// its base URL, for example http://restaurant-service:8080.
+package client
+
+//go:generate go run ../../cmd/openapi-gen -spec ../../api/openapi.json -client client_gen.go
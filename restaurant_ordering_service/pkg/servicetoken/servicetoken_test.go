@@ -0,0 +1,110 @@
+package servicetoken
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var testSecret = []byte("test-secret")
+
+// sign returns a token with claims signed by method with key
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims jwt.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	valid := func() jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			Subject:   "feedback-service",
+			Audience:  jwt.ClaimStrings{"restaurant-service"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}
+	}
+	with := func(change func(*jwt.RegisteredClaims)) jwt.RegisteredClaims {
+		claims := valid()
+		change(&claims)
+		return claims
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := Sign(testSecret, "feedback-service", "restaurant-service", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		service string // Empty if the token must be rejected
+	}{
+		{"signed by Sign", signed, "feedback-service"},
+		{"valid claims", sign(t, jwt.SigningMethodHS256, testSecret, valid()), "feedback-service"},
+		{"one of several audiences", sign(t, jwt.SigningMethodHS512, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"kitchen-service", "restaurant-service"}
+		})), "feedback-service"},
+		{"another secret", sign(t, jwt.SigningMethodHS256, []byte("other-secret"), valid()), ""},
+		{"another audience", sign(t, jwt.SigningMethodHS256, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.Audience = jwt.ClaimStrings{"kitchen-service"}
+		})), ""},
+		{"no audience", sign(t, jwt.SigningMethodHS256, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.Audience = nil
+		})), ""},
+		{"expired", sign(t, jwt.SigningMethodHS256, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = jwt.NewNumericDate(now.Add(-time.Second))
+		})), ""},
+		{"no expiry", sign(t, jwt.SigningMethodHS256, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.ExpiresAt = nil
+		})), ""},
+		{"no subject", sign(t, jwt.SigningMethodHS256, testSecret, with(func(c *jwt.RegisteredClaims) {
+			c.Subject = ""
+		})), ""},
+		{"RSA signature", sign(t, jwt.SigningMethodRS256, rsaKey, valid()), ""},
+		{"no signature", sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid()), ""},
+		// A user JWT of the REST API, signed with the same secret
+		{"user token", sign(t, jwt.SigningMethodHS256, testSecret, jwt.MapClaims{
+			"user_id":  1,
+			"username": "admin",
+			"role":     "admin",
+			"exp":      now.Add(time.Hour).Unix(),
+		}), ""},
+		{"malformed", "not-a-token", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := Verify(testSecret, tt.token, "restaurant-service")
+			if tt.service == "" {
+				if err == nil {
+					t.Errorf("Verify() = %q, want an error", service)
+				}
+				return
+			}
+			if err != nil || service != tt.service {
+				t.Errorf("Verify() = %q, %v, want %q", service, err, tt.service)
+			}
+		})
+	}
+}
+
+func TestSignRequiresServiceAndAudience(t *testing.T) {
+	if _, err := Sign(testSecret, "", "restaurant-service", time.Minute); err == nil {
+		t.Error("Sign() without a service did not fail")
+	}
+	if _, err := Sign(testSecret, "feedback-service", "", time.Minute); err == nil {
+		t.Error("Sign() without an audience did not fail")
+	}
+}
@@ -0,0 +1,110 @@
+package apperrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/shared/response"
+)
+
+var registerFieldNames sync.Once
+
+// useJSONFieldNames makes validation errors report fields by their JSON name
+// rather than the Go struct field name
+func useJSONFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// FromBinding converts an error from gin's ShouldBind* into a validation
+// error listing every invalid field
+func FromBinding(err error) *Error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]response.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, response.FieldError{
+				Field:   fieldPath(fieldErr.Namespace()),
+				Message: fieldMessage(fieldErr),
+			})
+		}
+		return Validation(fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Validation(response.FieldError{
+			Field:   jsonFieldPath(typeErr.Field),
+			Message: "must be a " + typeErr.Type.String(),
+		})
+	}
+
+	return &Error{Kind: KindInvalid, Code: CodeInvalidRequest, Message: "Invalid request format", Err: err}
+}
+
+// fieldPath strips the request struct name from a validator namespace such
+// as "OrderRequest.items[0].quantity"
+func fieldPath(namespace string) string {
+	_, path, found := strings.Cut(namespace, ".")
+	if !found {
+		return namespace
+	}
+	return path
+}
+
+// jsonFieldPath rewrites encoding/json paths such as "items.0.quantity" in
+// the validator style "items[0].quantity"
+func jsonFieldPath(path string) string {
+	parts := strings.Split(path, ".")
+	var b strings.Builder
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			b.WriteString("[" + part + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// fieldMessage describes a failed validation rule
+func fieldMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s item(s)", fieldErr.Param())
+		}
+		return "must be at least " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s item(s)", fieldErr.Param())
+		}
+		return "must be at most " + fieldErr.Param()
+	default:
+		return "failed the " + fieldErr.Tag() + " rule"
+	}
+}
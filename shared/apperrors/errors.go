@@ -0,0 +1,216 @@
+// Package apperrors defines the errors handlers return to clients. Every
+// error has a Kind, which decides the HTTP status, and a stable Code that
+// clients can match on. The underlying cause is logged but never rendered.
+// The codes of both services are listed here, so that a code means the same
+// thing whichever service returns it.
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/shared/response"
+)
+
+// Kind is the broad class of an error
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnavailable
+	KindCanceled
+	KindTooManyRequests
+)
+
+// statusClientClosedRequest is recorded when the client went away before a
+// response could be written
+const statusClientClosedRequest = 499
+
+var statuses = map[Kind]int{
+	KindInternal:     http.StatusInternalServerError,
+	KindInvalid:      http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindUnavailable:  http.StatusServiceUnavailable,
+	KindCanceled:     statusClientClosedRequest,
+
+	KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// Code identifies a specific error condition. Codes are part of the API and
+// must not change once published.
+type Code string
+
+// Codes shared by every endpoint
+const (
+	CodeInvalidRequest     Code = "INVALID_REQUEST"
+	CodeValidationFailed   Code = "VALIDATION_FAILED"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeInvalidCredentials Code = "INVALID_CREDENTIALS"
+	CodeTimeout            Code = "TIMEOUT"
+	CodeClientClosed       Code = "CLIENT_CLOSED_REQUEST"
+	CodeInternal           Code = "INTERNAL"
+)
+
+// Codes about orders, returned by both services
+const (
+	CodeOrderNotFound Code = "ORDER_NOT_FOUND"
+	CodeOrderNotOwned Code = "ORDER_NOT_OWNED"
+)
+
+// Codes specific to the restaurant service
+const (
+	CodeUserNotFound           Code = "USER_NOT_FOUND"
+	CodeFoodItemNotFound       Code = "FOOD_ITEM_NOT_FOUND"
+	CodeOrderNotPending        Code = "ORDER_NOT_PENDING"
+	CodeOutOfStock             Code = "OUT_OF_STOCK"
+	CodeServiceNotAllowed      Code = "SERVICE_NOT_ALLOWED"
+	CodeTooManyStreams         Code = "TOO_MANY_STREAMS"
+	CodeShuttingDown           Code = "SHUTTING_DOWN"
+	CodeRoleRequired           Code = "ROLE_REQUIRED"
+	CodeTicketNotFound         Code = "TICKET_NOT_FOUND"
+	CodeTicketBumped           Code = "TICKET_ALREADY_BUMPED"
+	CodeTicketNotBumped        Code = "TICKET_NOT_BUMPED"
+	CodeItemNotFound           Code = "TICKET_ITEM_NOT_FOUND"
+	CodeItemStarted            Code = "ITEM_ALREADY_STARTED"
+	CodeItemNotStarted         Code = "ITEM_NOT_STARTED"
+	CodeItemDone               Code = "ITEM_ALREADY_DONE"
+	CodeRestaurantNotFound     Code = "RESTAURANT_NOT_FOUND"
+	CodeRestaurantRequired     Code = "RESTAURANT_REQUIRED"
+	CodeRestaurantClosed       Code = "RESTAURANT_CLOSED"
+	CodeLastOrdersPassed       Code = "LAST_ORDERS_PASSED"
+	CodeOrderingPaused         Code = "ORDERING_PAUSED"
+	CodeHoursExceptionNotFound Code = "HOURS_EXCEPTION_NOT_FOUND"
+	CodeVariantRequired        Code = "VARIANT_REQUIRED"
+	CodeVariantNotFound        Code = "VARIANT_NOT_FOUND"
+	CodeModifierNotFound       Code = "MODIFIER_NOT_FOUND"
+	CodeModifierSelection      Code = "INVALID_MODIFIER_SELECTION"
+	CodeBundleNotFound         Code = "BUNDLE_NOT_FOUND"
+	CodeBundleChoiceRequired   Code = "BUNDLE_CHOICE_REQUIRED"
+	CodeBundleOptionNotFound   Code = "BUNDLE_OPTION_NOT_FOUND"
+	CodeIngredientNotFound     Code = "INGREDIENT_NOT_FOUND"
+	CodeIngredientExists       Code = "INGREDIENT_EXISTS"
+	CodeAlertNotFound          Code = "ALERT_NOT_FOUND"
+)
+
+// Codes specific to the feedback service
+const (
+	CodeOrderNotCompleted Code = "ORDER_NOT_COMPLETED"
+	CodeFeedbackNotFound  Code = "FEEDBACK_NOT_FOUND"
+	CodeFeedbackDuplicate Code = "FEEDBACK_DUPLICATE"
+)
+
+// Error is an error that can be rendered to a client
+type Error struct {
+	Kind    Kind
+	Code    Code
+	Message string
+	// Fields lists the invalid request fields of a validation error
+	Fields []response.FieldError
+	// Details carries machine-readable context such as the ID of the food
+	// item that ran out
+	Details map[string]any
+	// Err is the underlying cause. It is logged but never sent to clients.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return string(e.Code) + ": " + e.Message + ": " + e.Err.Error()
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the error
+func (e *Error) Status() int {
+	return statuses[e.Kind]
+}
+
+// WithDetail adds a machine-readable detail to the error
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// New creates an error of the given kind
+func New(kind Kind, code Code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Invalid reports a request that cannot be processed as sent
+func Invalid(code Code, message string) *Error {
+	return New(KindInvalid, code, message)
+}
+
+// Unauthorized reports missing or invalid credentials
+func Unauthorized(code Code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+// Forbidden reports an authenticated user acting on something they do not own
+func Forbidden(code Code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+// NotFound reports a missing resource
+func NotFound(code Code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict reports a request that clashes with the current state
+func Conflict(code Code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// TooManyRequests reports a client that is over a limit
+func TooManyRequests(code Code, message string) *Error {
+	return New(KindTooManyRequests, code, message)
+}
+
+// Internal wraps an unexpected error. Clients only see a generic message.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
+
+// Validation reports the request fields that failed validation
+func Validation(fields ...response.FieldError) *Error {
+	return &Error{Kind: KindInvalid, Code: CodeValidationFailed, Message: "Request validation failed", Fields: fields}
+}
+
+// HasCode reports whether err is an *Error with the given code
+func HasCode(err error, code Code) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Code == code
+}
+
+// From converts any error into an *Error. Timeouts and cancelled requests
+// are recognized even when wrapped; anything else unknown is internal.
+func From(err error) *Error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: KindUnavailable, Code: CodeTimeout, Message: "The request timed out, please try again", Err: err}
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: KindCanceled, Code: CodeClientClosed, Message: "The client closed the request", Err: err}
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
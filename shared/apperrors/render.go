@@ -0,0 +1,83 @@
+package apperrors
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shared/logging"
+	"github.com/shared/response"
+)
+
+// problemContentType is the RFC 7807 media type. Clients that accept it get
+// problem details instead of the regular response envelope.
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document, extended with the error
+// code, invalid fields and request ID
+type Problem struct {
+	Type      string                `json:"type"`
+	Title     string                `json:"title"`
+	Status    int                   `json:"status"`
+	Detail    string                `json:"detail,omitempty"`
+	Instance  string                `json:"instance,omitempty"`
+	Code      Code                  `json:"code"`
+	Errors    []response.FieldError `json:"errors,omitempty"`
+	Details   map[string]any        `json:"details,omitempty"`
+	RequestID string                `json:"request_id,omitempty"`
+}
+
+// Middleware renders the last error a handler attached with c.Error, unless
+// the handler already wrote a response. It must run inside the access log
+// and metrics middleware so that they see the final status.
+func Middleware() gin.HandlerFunc {
+	registerFieldNames.Do(useJSONFieldNames)
+
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Render(c, c.Errors.Last().Err)
+	}
+}
+
+// Render writes err to the client, as problem details if the client asked
+// for them
+func Render(c *gin.Context, err error) {
+	appErr := From(err)
+	status := appErr.Status()
+
+	// The client disconnected, so there is nobody to respond to
+	if appErr.Kind == KindCanceled {
+		c.AbortWithStatus(status)
+		return
+	}
+
+	if strings.Contains(c.GetHeader("Accept"), problemContentType) {
+		c.Header("Content-Type", problemContentType)
+		c.AbortWithStatusJSON(status, Problem{
+			Type:      "urn:problem-type:" + strings.ToLower(strings.ReplaceAll(string(appErr.Code), "_", "-")),
+			Title:     http.StatusText(status),
+			Status:    status,
+			Detail:    appErr.Message,
+			Instance:  c.Request.URL.Path,
+			Code:      appErr.Code,
+			Errors:    appErr.Fields,
+			Details:   appErr.Details,
+			RequestID: logging.RequestID(c.Request.Context()),
+		})
+		return
+	}
+
+	c.AbortWithStatusJSON(status, response.APIResponse{
+		Success: false,
+		Message: appErr.Message,
+		Error: &response.ErrorDetail{
+			Code:    string(appErr.Code),
+			Fields:  appErr.Fields,
+			Details: appErr.Details,
+		},
+	})
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// DatabaseCheck verifies that Postgres accepts connections and reports the
+// connection pool usage
+func DatabaseCheck(db *sql.DB) Check {
+	return Check{
+		Name:     "database",
+		Critical: true,
+		Run: func(ctx context.Context) ComponentStatus {
+			if db == nil {
+				return Down(fmt.Errorf("database is not initialized"))
+			}
+			if err := db.PingContext(ctx); err != nil {
+				return Down(err)
+			}
+			stats := db.Stats()
+			return Up(map[string]interface{}{
+				"open_connections": stats.OpenConnections,
+				"in_use":           stats.InUse,
+				"idle":             stats.Idle,
+			})
+		},
+	}
+}
+
+// KafkaBrokerCheck verifies that a Kafka broker is reachable. Both services
+// keep serving requests while Kafka is unavailable, so the check is not
+// critical.
+func KafkaBrokerCheck(ping func(ctx context.Context) error) Check {
+	return Check{
+		Name: "kafka",
+		Run: func(ctx context.Context) ComponentStatus {
+			if err := ping(ctx); err != nil {
+				return Down(err)
+			}
+			return Up(nil)
+		},
+	}
+}
+
+// PublisherBacklogCheck reports the number of order events still waiting to
+// be published and degrades the service once it exceeds threshold
+func PublisherBacklogCheck(pending func() int64, threshold int64) Check {
+	return Check{
+		Name: "publisher",
+		Run: func(ctx context.Context) ComponentStatus {
+			backlog := pending()
+			status := Up(map[string]interface{}{
+				"pending_events": backlog,
+				"threshold":      threshold,
+			})
+			if backlog > threshold {
+				status.Status = StatusDegraded
+			}
+			return status
+		},
+	}
+}
+
+// ConsumerGroupCheck reports the state of the order event consumer group.
+// A rebalancing group degrades the service; a group without members, or one
+// that cannot be described, is reported as down.
+func ConsumerGroupCheck(describe func(ctx context.Context) (state string, members int, lag int64, err error)) Check {
+	return Check{
+		Name: "consumer_group",
+		Run: func(ctx context.Context) ComponentStatus {
+			state, members, lag, err := describe(ctx)
+			if err != nil {
+				return Down(err)
+			}
+
+			status := Up(map[string]interface{}{
+				"state":   state,
+				"members": members,
+				"lag":     lag,
+			})
+			switch {
+			case members == 0:
+				status.Status = StatusDown
+				status.Error = "consumer group has no members"
+			case state != "Stable":
+				status.Status = StatusDegraded
+			}
+			return status
+		},
+	}
+}
@@ -0,0 +1,119 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Status is the state of a single component or of the whole service
+type Status string
+
+const (
+	StatusUp       Status = "up"
+	StatusDegraded Status = "degraded"
+	StatusDown     Status = "down"
+)
+
+// checkTimeout bounds how long a readiness probe waits for its checks
+const checkTimeout = 3 * time.Second
+
+// ComponentStatus is the result of checking a single dependency
+type ComponentStatus struct {
+	Status  Status                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is the body returned by the readiness endpoint
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// Check describes a dependency to verify. A failing critical check marks the
+// service as down; a failing non-critical check only degrades it.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) ComponentStatus
+}
+
+// Up returns a healthy component status with optional details
+func Up(details map[string]interface{}) ComponentStatus {
+	return ComponentStatus{Status: StatusUp, Details: details}
+}
+
+// Down returns a failed component status for err
+func Down(err error) ComponentStatus {
+	return ComponentStatus{Status: StatusDown, Error: err.Error()}
+}
+
+// LivenessHandler reports that the process is alive. It deliberately does not
+// check any dependency so that an outage elsewhere does not restart the pod.
+func LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusUp})
+}
+
+// ReadinessHandler runs all checks concurrently and reports whether the
+// service can serve traffic. It responds with 503 when a critical check fails.
+func ReadinessHandler(checks ...Check) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), checkTimeout)
+		defer cancel()
+
+		report := Run(ctx, checks)
+
+		code := http.StatusOK
+		if report.Status == StatusDown {
+			code = http.StatusServiceUnavailable
+		}
+		c.JSON(code, report)
+	}
+}
+
+// Run executes the checks and aggregates their results into a report
+func Run(ctx context.Context, checks []Check) Report {
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]ComponentStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := check.Run(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Components[check.Name] = result
+			report.Status = worst(report.Status, effectiveStatus(check, result))
+		}(check)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// effectiveStatus maps a component result onto the overall service status
+func effectiveStatus(check Check, result ComponentStatus) Status {
+	if result.Status == StatusDown && !check.Critical {
+		return StatusDegraded
+	}
+	return result.Status
+}
+
+// worst returns the more severe of two statuses
+func worst(a, b Status) Status {
+	rank := map[Status]int{StatusUp: 0, StatusDegraded: 1, StatusDown: 2}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
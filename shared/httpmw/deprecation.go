@@ -0,0 +1,43 @@
+// Package httpmw holds the Gin middleware shared by the services
+package httpmw
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VersionPolicy describes when an API version was deprecated and when it
+// will be removed. Zero times mean not deprecated and no removal scheduled.
+type VersionPolicy struct {
+	Deprecated time.Time `yaml:"deprecated"`
+	Sunset     time.Time `yaml:"sunset"`
+	Link       string    `yaml:"link"` // Migration guide for clients
+}
+
+// Deprecation announces a deprecated API version to clients with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers, and links the
+// migration guide. Versions that are not deprecated pass through untouched.
+func Deprecation(policy VersionPolicy) gin.HandlerFunc {
+	if policy.Deprecated.IsZero() {
+		return func(c *gin.Context) {}
+	}
+
+	deprecation := "@" + strconv.FormatInt(policy.Deprecated.Unix(), 10)
+	sunset := ""
+	if !policy.Sunset.IsZero() {
+		sunset = policy.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if policy.Link != "" {
+			c.Header("Link", "<"+policy.Link+`>; rel="deprecation"; type="text/html"`)
+		}
+	}
+}
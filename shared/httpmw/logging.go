@@ -0,0 +1,72 @@
+package httpmw
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shared/logging"
+)
+
+// RequestIDHeader carries the request ID between services and back to clients
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds client supplied request IDs
+const maxRequestIDLength = 128
+
+// RequestID reuses the X-Request-ID header when present, otherwise generates
+// a new ID. The ID is echoed in the response and stored in the request
+// context so that every log line for the request includes it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, requestID)
+		c.Set("request_id", requestID)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// AccessLog logs one structured line per request
+func AccessLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logger := logging.For(logging.ComponentHTTP)
+		args := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			args = append(args, "errors", c.Errors.String())
+		}
+
+		switch {
+		case c.Writer.Status() >= 500:
+			logger.ErrorContext(c.Request.Context(), "Request completed", args...)
+		case c.Writer.Status() >= 400:
+			logger.WarnContext(c.Request.Context(), "Request completed", args...)
+		default:
+			logger.InfoContext(c.Request.Context(), "Request completed", args...)
+		}
+	}
+}
+
+// newRequestID returns a random 16 byte hex encoded ID
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,144 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Components that can be given their own log level with LOG_LEVEL_<COMPONENT>
+const (
+	ComponentApp   = "app"
+	ComponentHTTP  = "http"
+	ComponentDB    = "db"
+	ComponentKafka = "kafka"
+	ComponentGRPC  = "grpc"
+)
+
+// redacted replaces the value of any sensitive attribute
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged
+var sensitiveKeys = map[string]bool{
+	"password":      true,
+	"token":         true,
+	"secret":        true,
+	"jwt_secret":    true,
+	"authorization": true,
+	"email":         true,
+	"db_password":   true,
+
+	"service_token_secret": true,
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+type requestIDKey struct{}
+
+var (
+	mu      sync.Mutex
+	output  io.Writer = os.Stdout
+	loggers           = map[string]*slog.Logger{}
+)
+
+// Setup installs the JSON logger as the default for both slog and the
+// standard log package. The default level comes from LOG_LEVEL.
+func Setup() {
+	slog.SetDefault(For(ComponentApp))
+}
+
+// For returns the logger for a component. Its level is read from
+// LOG_LEVEL_<COMPONENT>, falling back to LOG_LEVEL and then to info.
+func For(component string) *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if logger, ok := loggers[component]; ok {
+		return logger
+	}
+
+	level := levelFromEnv("LOG_LEVEL_"+strings.ToUpper(component), levelFromEnv("LOG_LEVEL", slog.LevelInfo))
+	handler := slog.NewJSONHandler(output, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+	logger := slog.New(contextHandler{handler}).With("component", component)
+	loggers[component] = logger
+	return logger
+}
+
+// Fatal logs msg at error level and exits the process
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// levelFromEnv parses a level name from the environment variable key
+func levelFromEnv(key string, fallback slog.Level) slog.Level {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return fallback
+	}
+	return level
+}
+
+// redact masks sensitive attributes by key and email addresses anywhere in
+// string values
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, redacted)
+	}
+	if attr.Value.Kind() == slog.KindString {
+		value := attr.Value.String()
+		if emailPattern.MatchString(value) {
+			return slog.String(attr.Key, emailPattern.ReplaceAllString(value, redacted))
+		}
+	}
+	return attr
+}
+
+// contextHandler adds the request ID and trace ID from the context to every
+// record logged with a *Context method
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		record.AddAttrs(slog.String("trace_id", spanContext.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want slog.Value
+	}{
+		{"password", slog.String("password", "hunter2"), slog.StringValue(redacted)},
+		{"key in another case", slog.String("Authorization", "Bearer abc"), slog.StringValue(redacted)},
+		{"secret that is not a string", slog.Int("db_password", 1234), slog.StringValue(redacted)},
+		{"email key", slog.String("email", "not an address"), slog.StringValue(redacted)},
+		{"email in a message", slog.String("error", "user alice@example.com not found"), slog.StringValue("user " + redacted + " not found")},
+		{"several emails", slog.String("to", "a.b+c@mail.example.org, d@example.in"), slog.StringValue(redacted + ", " + redacted)},
+		{"not an email", slog.String("handle", "@alice at example.com"), slog.StringValue("@alice at example.com")},
+		{"ordinary attribute", slog.String("username", "alice"), slog.StringValue("alice")},
+		{"number", slog.Int("order_id", 42), slog.IntValue(42)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redact(nil, tt.attr)
+			if got.Key != tt.attr.Key || !got.Value.Equal(tt.want) {
+				t.Errorf("redact() = %v, want %s=%v", got, tt.attr.Key, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactInGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact}))
+
+	logger.Info("Login", slog.Group("request", "token", "abc", "user", "alice@example.com", "path", "/login"))
+
+	var record struct {
+		Request map[string]string `json:"request"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"token": redacted, "user": redacted, "path": "/login"}
+	for key, value := range want {
+		if record.Request[key] != value {
+			t.Errorf("request.%s = %q, want %q in %s", key, record.Request[key], value, buf.String())
+		}
+	}
+}
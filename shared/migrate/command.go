@@ -0,0 +1,68 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Commands describes the commands Run understands, for the usage of the
+// migrate subcommand of a service
+const Commands = `  up          apply all pending migrations
+  down        roll back the most recent migration
+  to VERSION  migrate up or down to VERSION (0 rolls back everything)
+  status      list migrations and when they were applied`
+
+// ErrUsage is returned by Run for a command it does not understand
+var ErrUsage = errors.New("unknown migrate command")
+
+// Run runs a command of the migrate subcommand, given as its name followed
+// by its arguments. The status table, and the SQL of a dry run, are written
+// to w.
+func Run(ctx context.Context, m *Migrator, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return ErrUsage
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return m.To(ctx, version)
+	case "status":
+		return printStatus(ctx, m, w)
+	default:
+		return ErrUsage
+	}
+}
+
+// printStatus writes a table of migrations to w
+func printStatus(ctx context.Context, m *Migrator, w io.Writer) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,334 @@
+// Package migrate applies versioned SQL migrations to a Postgres database
+// and runs the migrate subcommand of the services
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/shared/logging"
+)
+
+var filePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrator applies the migrations of a service to its database
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+
+	// lockID identifies the Postgres advisory lock held while migrating, so
+	// that replicas starting at the same time do not race each other. Each
+	// service has its own.
+	lockID int64
+
+	// dryRun, when set, receives the SQL that would run instead of it being
+	// executed
+	dryRun io.Writer
+}
+
+// New loads the migrations in the top directory of files, named
+// VERSION_NAME.up.sql and VERSION_NAME.down.sql, to be applied to db while
+// holding the advisory lock lockID
+func New(db *sql.DB, files fs.FS, lockID int64) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations, lockID: lockID}, nil
+}
+
+// DryRun makes the migrator print the SQL it would execute to w instead of
+// running it. The database is only read for the current version, without
+// taking the migration lock or creating the bookkeeping table; a database
+// without the table is at version 0.
+func (m *Migrator) DryRun(w io.Writer) {
+	m.dryRun = w
+}
+
+// Latest returns the highest known migration version
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			return nil
+		}
+
+		target := 0
+		for _, migration := range m.migrations {
+			if migration.Version < current {
+				target = migration.Version
+			}
+		}
+		return m.migrate(ctx, conn, current, target)
+	})
+}
+
+// To migrates up or down until the schema is at version. Version 0 rolls
+// back every migration.
+func (m *Migrator) To(ctx context.Context, version int) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := currentVersion(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return m.migrate(ctx, conn, current, version)
+	})
+}
+
+// Status lists every known migration and when it was applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]time.Time)
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// migrate applies or rolls back migrations between current and target, each
+// in its own transaction together with its schema_migrations bookkeeping
+func (m *Migrator) migrate(ctx context.Context, conn *sql.Conn, current, target int) error {
+	if target >= current {
+		for _, migration := range m.migrations {
+			if migration.Version <= current || migration.Version > target {
+				continue
+			}
+			if m.dryRun != nil {
+				fmt.Fprintf(m.dryRun, "-- %d_%s (up)\n%s\n", migration.Version, migration.Name, migration.Up)
+				continue
+			}
+			logging.For(logging.ComponentDB).Info("Applying migration", "version", migration.Version, "name", migration.Name)
+			err := inTx(ctx, conn, migration.Up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("applying migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+		}
+		return nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version > current || migration.Version <= target {
+			continue
+		}
+		if m.dryRun != nil {
+			fmt.Fprintf(m.dryRun, "-- %d_%s (down)\n%s\n", migration.Version, migration.Name, migration.Down)
+			continue
+		}
+		logging.For(logging.ComponentDB).Info("Rolling back migration", "version", migration.Version, "name", migration.Name)
+		err := inTx(ctx, conn, migration.Down,
+			"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+		if err != nil {
+			return fmt.Errorf("rolling back migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock. Session level advisory locks belong to a connection, so every
+// statement must use the same one. A dry run only reads, so it runs fn
+// without the lock.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.dryRun != nil {
+		return fn(conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockID); err != nil {
+		return fmt.Errorf("acquiring migration lock: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so the lock is released even if ctx was cancelled
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", m.lockID); err != nil {
+			logging.For(logging.ComponentDB).Error("Error releasing migration lock", "error", err)
+		}
+	}()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// ensureTable creates the schema_migrations bookkeeping table
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+// currentVersion returns the highest applied migration version, or 0 if the
+// schema_migrations table does not exist yet
+func currentVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	var version int
+	err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// inTx runs a migration script and its bookkeeping statement atomically
+func inTx(ctx context.Context, conn *sql.Conn, script, bookkeeping string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// load parses the migration files into a sorted list
+func load(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
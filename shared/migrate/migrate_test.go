@@ -0,0 +1,134 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// recordingDriver is a database/sql driver that records every statement and
+// answers as a database that was never migrated
+type recordingDriver struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d: d}, nil }
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.statements = append(d.statements, query)
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *recordingConn) Close() error { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.record(query)
+	return &boolRows{}, nil
+}
+
+// boolRows is a single row holding false, the answer to whether the
+// schema_migrations table exists
+type boolRows struct{ done bool }
+
+func (r *boolRows) Columns() []string { return []string{"exists"} }
+func (r *boolRows) Close() error      { return nil }
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = false
+	return nil
+}
+
+// testMigrations are two migrations, listed out of order
+var testMigrations = fstest.MapFS{
+	"0002_add_index.up.sql":      {Data: []byte("CREATE INDEX idx_items_name ON items (name);")},
+	"0002_add_index.down.sql":    {Data: []byte("DROP INDEX idx_items_name;")},
+	"0001_create_items.up.sql":   {Data: []byte("CREATE TABLE items (id SERIAL PRIMARY KEY, name TEXT);")},
+	"0001_create_items.down.sql": {Data: []byte("DROP TABLE items;")},
+}
+
+// TestDryRunOnlyReads checks that a dry run prints every migration of a new
+// database without locking or creating anything
+func TestDryRunOnlyReads(t *testing.T) {
+	recorder := &recordingDriver{}
+	sql.Register("recording", recorder)
+	db, err := sql.Open("recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := New(db, testMigrations, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	migrator.DryRun(&out)
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, migration := range migrator.migrations {
+		if !strings.Contains(out.String(), migration.Name+" (up)") {
+			t.Errorf("dry run did not print migration %d_%s", migration.Version, migration.Name)
+		}
+	}
+	for _, statement := range recorder.statements {
+		if !strings.HasPrefix(strings.TrimSpace(statement), "SELECT") || strings.Contains(statement, "pg_advisory") {
+			t.Errorf("dry run executed %q", statement)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Name != "create_items" || migrations[1].Name != "add_index" {
+		t.Errorf("load() = %+v, want create_items and then add_index", migrations)
+	}
+
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"invalid file name", fstest.MapFS{"create_items.sql": {}}},
+		{"missing down file", fstest.MapFS{"0001_create_items.up.sql": {Data: []byte("CREATE TABLE items ();")}}},
+		{"conflicting names", fstest.MapFS{
+			"0001_create_items.up.sql":    {Data: []byte("CREATE TABLE items ();")},
+			"0001_create_things.down.sql": {Data: []byte("DROP TABLE items;")},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := load(tt.files); err == nil {
+				t.Error("load() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// initialisms are written in upper case in Go identifiers
+var initialisms = map[string]bool{"api": true, "http": true, "id": true, "json": true, "jwt": true, "url": true}
+
+// clientRuntime is the source of the Client type and of the request and
+// error handling every generated client shares
+//
+//go:embed client_runtime.txt
+var clientRuntime string
+
+// clientRuntimeImports are the packages clientRuntime uses
+var clientRuntimeImports = []string{"bytes", "context", "encoding/json", "fmt", "io", "net/http", "strings"}
+
+// clientWriter accumulates the source of a generated client
+type clientWriter struct {
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+// GenerateClient returns the formatted Go source of package pkg with the
+// Client type, a type for each component schema and a method on Client for
+// each operation of the API version that responds with the API envelope
+func (d *Document) GenerateClient(pkg, version string) ([]byte, error) {
+	w := &clientWriter{imports: make(map[string]bool)}
+	for _, path := range clientRuntimeImports {
+		w.imports[path] = true
+	}
+	w.body.WriteString(clientRuntime)
+	w.body.WriteString("\n")
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := w.writeType(name, d.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, op := range d.operations {
+		if !op.envelope || op.version != version {
+			continue
+		}
+		if err := w.writeMethod(op); err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by openapi-gen from %s %s, API %s. DO NOT EDIT.\n\n", d.Info.Title, d.Info.Version, version)
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	if len(w.imports) > 0 {
+		imports := make([]string, 0, len(w.imports))
+		for path := range w.imports {
+			imports = append(imports, path)
+		}
+		sort.Strings(imports)
+		src.WriteString("import (\n")
+		for _, path := range imports {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		}
+		src.WriteString(")\n\n")
+	}
+	src.Write(w.body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// writeType writes the struct for a component schema, with the same JSON
+// tags as the type it was built from
+func (w *clientWriter) writeType(name string, schema *Schema) error {
+	fmt.Fprintf(&w.body, "// %s mirrors the %s schema of the API\n", name, name)
+	fmt.Fprintf(&w.body, "type %s struct {\n", name)
+	for _, property := range schema.order {
+		fieldType, err := w.goType(schema.Properties[property])
+		if err != nil {
+			return fmt.Errorf("schema %s, property %s: %w", name, property, err)
+		}
+		tag := property
+		if schema.omitEmpty[property] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(&w.body, "\t%s %s `json:%q`\n", goName(property), fieldType, tag)
+	}
+	w.body.WriteString("}\n\n")
+	return nil
+}
+
+// writeMethod writes the Client method calling an operation
+func (w *clientWriter) writeMethod(op *Operation) error {
+	w.imports["context"] = true
+	w.imports["net/http"] = true
+
+	args := []string{"ctx context.Context"}
+	var pathExpr []string
+	literal := ""
+	for _, segment := range strings.Split(op.path, "/") {
+		if !strings.HasPrefix(segment, "{") {
+			literal += segment + "/"
+			continue
+		}
+
+		name := segment[1 : len(segment)-1]
+		arg := lowerFirst(goName(name))
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", literal))
+		literal = "/"
+		if parameterType(op, name) == "integer" {
+			w.imports["strconv"] = true
+			args = append(args, arg+" int64")
+			pathExpr = append(pathExpr, "strconv.FormatInt("+arg+", 10)")
+		} else {
+			w.imports["net/url"] = true
+			args = append(args, arg+" string")
+			pathExpr = append(pathExpr, "url.PathEscape("+arg+")")
+		}
+	}
+	if literal = strings.TrimSuffix(literal, "/"); literal != "" {
+		pathExpr = append(pathExpr, fmt.Sprintf("%q", literal))
+	}
+
+	body := "nil"
+	if op.request != nil {
+		requestType, err := w.goType(op.request)
+		if err != nil {
+			return fmt.Errorf("operation %s request: %w", op.OperationID, err)
+		}
+		args = append(args, "body "+requestType)
+		body = "body"
+	}
+
+	name := upperFirst(op.name)
+	method := "http.Method" + upperFirst(strings.ToLower(op.method))
+	fmt.Fprintf(&w.body, "// %s calls %s %s", name, op.method, op.path)
+	if op.Summary != "" {
+		fmt.Fprintf(&w.body, " to %s", lowerFirst(op.Summary))
+	}
+	w.body.WriteString("\n")
+
+	if op.data == nil {
+		fmt.Fprintf(&w.body, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+		fmt.Fprintf(&w.body, "\treturn c.do(ctx, %s, %s, %s, nil)\n}\n\n", method, strings.Join(pathExpr, " + "), body)
+		return nil
+	}
+
+	dataType, err := w.goType(op.data)
+	if err != nil {
+		return fmt.Errorf("operation %s response: %w", op.OperationID, err)
+	}
+	fmt.Fprintf(&w.body, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), dataType)
+	fmt.Fprintf(&w.body, "\tvar data %s\n", dataType)
+	fmt.Fprintf(&w.body, "\terr := c.do(ctx, %s, %s, %s, &data)\n", method, strings.Join(pathExpr, " + "), body)
+	w.body.WriteString("\treturn data, err\n}\n\n")
+	return nil
+}
+
+// goType returns the Go type for values of a schema
+func (w *clientWriter) goType(schema *Schema) (string, error) {
+	if schema.Ref != "" {
+		return strings.TrimPrefix(schema.Ref, refPrefix), nil
+	}
+
+	var goType string
+	switch schema.Type {
+	case "boolean":
+		goType = "bool"
+	case "integer":
+		goType = "int"
+		if schema.Format == "int64" {
+			goType = "int64"
+		}
+	case "number":
+		goType = "float64"
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			w.imports["time"] = true
+			goType = "time.Time"
+		case "byte":
+			goType = "[]byte"
+		default:
+			goType = "string"
+		}
+	case "array":
+		items, err := w.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + items, nil
+	case "object":
+		if len(schema.Properties) > 0 {
+			return "", fmt.Errorf("inline object schemas are not supported")
+		}
+		if schema.AdditionalProperties == nil {
+			return "map[string]any", nil
+		}
+		values, err := w.goType(schema.AdditionalProperties)
+		if err != nil {
+			return "", err
+		}
+		return "map[string]" + values, nil
+	case "":
+		return "any", nil
+	default:
+		return "", fmt.Errorf("unsupported schema type %q", schema.Type)
+	}
+
+	if schema.Nullable {
+		goType = "*" + goType
+	}
+	return goType, nil
+}
+
+// parameterType returns the schema type of the path parameter called name
+func parameterType(op *Operation, name string) string {
+	for _, parameter := range op.Parameters {
+		if parameter.Name == name {
+			return parameter.Schema.Type
+		}
+	}
+	return "string"
+}
+
+// goName converts a JSON name such as food_item_id to a Go name such as
+// FoodItemID
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		if initialisms[strings.ToLower(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		b.WriteString(upperFirst(word))
+	}
+	return b.String()
+}
+
+// upperFirst upper-cases the first letter of s
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return string(unicode.ToUpper(rune(s[0]))) + s[1:]
+}
+
+// lowerFirst lower-cases the leading initialism or letter of s
+func lowerFirst(s string) string {
+	for word := range initialisms {
+		if strings.HasPrefix(s, strings.ToUpper(word)) && (len(s) == len(word) || unicode.IsUpper(rune(s[len(word)]))) {
+			return word + s[len(word):]
+		}
+	}
+	if s == "" {
+		return s
+	}
+	return string(unicode.ToLower(rune(s[0]))) + s[1:]
+}
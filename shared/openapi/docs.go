@@ -0,0 +1,50 @@
+package openapi
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// swaggerUIVersion is the Swagger UI release loaded by the docs page
+const swaggerUIVersion = "5.17.14"
+
+// swaggerUI renders the docs page. The spec URL is relative so that the page
+// also works behind the gateway's path prefix.
+var swaggerUI = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}}</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@{{.Version}}/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+
+// SpecHandler serves the OpenAPI document as JSON
+func (r *Registry) SpecHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, r.doc)
+	}
+}
+
+// DocsHandler serves Swagger UI for the document served at specURL
+func (r *Registry) DocsHandler(specURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		swaggerUI.Execute(c.Writer, map[string]string{
+			"Title":   r.doc.Info.Title,
+			"Version": swaggerUIVersion,
+			"SpecURL": specURL,
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// Files returns the OpenAPI document as indented JSON and the source of the
+// client of the given API version in package pkg, as openapi-gen writes them
+func (d *Document) Files(pkg, version string) (spec, client []byte, err error) {
+	spec, err = json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding OpenAPI document: %w", err)
+	}
+	spec = append(spec, '\n')
+
+	client, err = d.GenerateClient(pkg, version)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generating client: %w", err)
+	}
+	return spec, client, nil
+}
+
+// Main runs the openapi-gen command of a service, which writes the OpenAPI
+// document doc and the Go client generated from it. With -check it fails
+// instead if either file is out of date. The client calls latestVersion
+// unless -version says otherwise.
+func Main(doc *Document, latestVersion string) {
+	specPath := flag.String("spec", "api/openapi.json", "where to write the OpenAPI document")
+	clientPath := flag.String("client", "pkg/client/client_gen.go", "where to write the generated client")
+	clientPackage := flag.String("package", "client", "package name of the generated client")
+	version := flag.String("version", latestVersion, "API version the client calls")
+	check := flag.Bool("check", false, "verify the files are up to date instead of writing them")
+	flag.Parse()
+
+	spec, client, err := doc.Files(*clientPackage, *version)
+	if err != nil {
+		fail("%v", err)
+	}
+
+	for path, content := range map[string][]byte{*specPath: spec, *clientPath: client} {
+		if *check {
+			current, err := os.ReadFile(path)
+			if err != nil || !bytes.Equal(current, content) {
+				fail("%s is out of date, run go generate ./...", path)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			fail("writing %s: %v", path, err)
+		}
+	}
+}
+
+// fail prints an error and exits
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "openapi-gen: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shared/response"
+)
+
+// bearerAuth is the name of the JWT security scheme
+const bearerAuth = "bearerAuth"
+
+// errorResponse is the component schema of failed requests
+const errorResponse = "ErrorResponse"
+
+// Registry registers routes with Gin and describes each of them in an OpenAPI
+// document, so that the document cannot miss a route
+type Registry struct {
+	doc   *Document
+	types map[string]reflect.Type // Go types behind the component schemas
+	ids   map[string]bool         // Operation IDs in use
+}
+
+// Route describes a route and the types it reads and writes
+type Route struct {
+	Method      string
+	Path        string // Relative to the group, with Gin parameters such as :id
+	OperationID string
+	Summary     string
+	Tag         string
+	Params      []Param      // Path parameters; undocumented ones are strings
+	Request     any          // Value of the request body type, nil when there is no body
+	Response    any          // Value of the type in the data field of the envelope, nil when there is none
+	Status      int          // Success status, 200 when zero
+	Errors      []int        // Error statuses besides 500 and, on secured groups, 401
+	Raw         *RawResponse // Set for responses that are not wrapped in the envelope
+}
+
+// Param describes a path parameter
+type Param struct {
+	Name        string
+	Type        string // "integer" or "string"
+	Description string
+}
+
+// RawResponse describes a response body that is not wrapped in the API
+// envelope, such as health reports and metrics
+type RawResponse struct {
+	ContentType string
+	Body        any // Value of the body type, nil for an unspecified body
+}
+
+// NewRegistry creates a registry for an API described by info
+func NewRegistry(info Info) *Registry {
+	r := &Registry{
+		doc: &Document{
+			OpenAPI: Version,
+			Info:    info,
+			// Relative to the document, so that it also works behind the gateway
+			Servers: []Server{{URL: "."}},
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: make(map[string]*Schema),
+				SecuritySchemes: map[string]SecurityScheme{
+					bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				},
+			},
+		},
+		types: make(map[string]reflect.Type),
+		ids:   make(map[string]bool),
+	}
+
+	// Every failed request shares the same body
+	r.doc.Components.Schemas[errorResponse] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+			"error":   r.schemaOf(reflect.TypeOf(response.ErrorDetail{})),
+		},
+		Required: []string{"success", "message", "error"},
+		order:    []string{"success", "message", "error"},
+	}
+	return r
+}
+
+// Document returns the OpenAPI document of the routes registered so far
+func (r *Registry) Document() *Document {
+	return r.doc
+}
+
+// Group is a Gin router group whose routes are documented in a registry
+type Group struct {
+	registry   *Registry
+	router     *gin.RouterGroup
+	secured    bool
+	version    string
+	deprecated bool
+}
+
+// Group documents the routes of a public router group
+func (r *Registry) Group(router *gin.RouterGroup) *Group {
+	return &Group{registry: r, router: router}
+}
+
+// SecuredGroup documents the routes of a router group that requires a JWT
+func (r *Registry) SecuredGroup(router *gin.RouterGroup) *Group {
+	return &Group{registry: r, router: router, secured: true}
+}
+
+// Version returns a copy of the group whose routes belong to an API
+// version. Their operation IDs get the version as a suffix, so that the same
+// route can be documented in several versions.
+func (g *Group) Version(version string, deprecated bool) *Group {
+	versioned := *g
+	versioned.version = version
+	versioned.deprecated = deprecated
+	return &versioned
+}
+
+// Handle registers a route with Gin and adds it to the document
+func (g *Group) Handle(route Route, handlers ...gin.HandlerFunc) {
+	g.router.Handle(route.Method, route.Path, handlers...)
+	g.registry.add(path.Join(g.router.BasePath(), route.Path), g, route)
+}
+
+// add describes a route of group at the absolute Gin path ginPath
+func (r *Registry) add(ginPath string, group *Group, route Route) {
+	id := route.OperationID + upperFirst(group.version)
+	if r.ids[id] {
+		panic(fmt.Sprintf("openapi: duplicate operation ID %s", id))
+	}
+	r.ids[id] = true
+
+	op := &Operation{
+		OperationID: id,
+		Summary:     route.Summary,
+		Responses:   make(map[string]Response),
+		Deprecated:  group.deprecated,
+		method:      route.Method,
+		name:        route.OperationID,
+		version:     group.version,
+		envelope:    route.Raw == nil,
+	}
+	if route.Tag != "" {
+		op.Tags = []string{route.Tag}
+	}
+
+	// Convert Gin parameters such as :id to OpenAPI ones such as {id}
+	segments := strings.Split(ginPath, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") && !strings.HasPrefix(segment, "*") {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		op.Parameters = append(op.Parameters, pathParameter(name, route.Params))
+	}
+	op.path = strings.Join(segments, "/")
+
+	// Request body
+	if route.Request != nil {
+		op.request = r.schemaOf(reflect.TypeOf(route.Request))
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: op.request}},
+		}
+	}
+
+	// Success response
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if route.Raw != nil {
+		op.Responses[strconv.Itoa(status)] = r.rawResponse(status, route.Raw)
+	} else {
+		if route.Response != nil {
+			op.data = r.schemaOf(reflect.TypeOf(route.Response))
+		}
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"application/json": {Schema: envelope(op.data)}},
+		}
+	}
+
+	// Error responses
+	statuses := append([]int{}, route.Errors...)
+	if group.secured {
+		op.Security = []map[string][]string{{bearerAuth: {}}}
+		statuses = append(statuses, http.StatusUnauthorized)
+	}
+	statuses = append(statuses, http.StatusInternalServerError)
+	for _, status := range statuses {
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: http.StatusText(status),
+			Content:     map[string]MediaType{"application/json": {Schema: ref(errorResponse)}},
+		}
+	}
+
+	item, ok := r.doc.Paths[op.path]
+	if !ok {
+		item = make(PathItem)
+		r.doc.Paths[op.path] = item
+	}
+	item[strings.ToLower(route.Method)] = op
+	r.doc.operations = append(r.doc.operations, op)
+}
+
+// pathParameter describes the path parameter called name
+func pathParameter(name string, params []Param) Parameter {
+	parameter := Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}}
+	for _, param := range params {
+		if param.Name == name {
+			parameter.Description = param.Description
+			if param.Type != "" {
+				parameter.Schema.Type = param.Type
+			}
+		}
+	}
+	return parameter
+}
+
+// rawResponse describes a response that is not wrapped in the envelope
+func (r *Registry) rawResponse(status int, raw *RawResponse) Response {
+	schema := &Schema{Type: "string"}
+	switch {
+	case raw.Body != nil:
+		schema = r.schemaOf(reflect.TypeOf(raw.Body))
+	case raw.ContentType == "application/json":
+		schema = &Schema{Type: "object"}
+	}
+	return Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{raw.ContentType: {Schema: schema}},
+	}
+}
+
+// envelope returns the schema of a successful response carrying data
+func envelope(data *Schema) *Schema {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			"message": {Type: "string"},
+		},
+		Required: []string{"success", "message"},
+		order:    []string{"success", "message"},
+	}
+	if data != nil {
+		schema.Properties["data"] = data
+		schema.order = append(schema.order, "data")
+	}
+	return schema
+}
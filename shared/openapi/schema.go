@@ -0,0 +1,168 @@
+package openapi
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timeType is encoded by encoding/json as an RFC 3339 string
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the schema of values of type t, adding the schemas of named
+// structs to the components and referencing them
+func (r *Registry) schemaOf(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := r.schemaOf(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int:
+		return &Schema{Type: "integer"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		return r.component(t)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+// component adds the schema of the named struct t to the components once and
+// returns a reference to it
+func (r *Registry) component(t reflect.Type) *Schema {
+	name := t.Name()
+	if existing, ok := r.types[name]; ok {
+		if existing != t {
+			panic(fmt.Sprintf("openapi: schema name %s is used by both %s and %s", name, existing, t))
+		}
+		return ref(name)
+	}
+
+	// Register the type before building its schema so that recursive types
+	// reference themselves
+	r.types[name] = t
+	r.doc.Components.Schemas[name] = r.structSchema(t)
+	return ref(name)
+}
+
+// structSchema builds the object schema of a struct from its JSON and binding
+// tags
+func (r *Registry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema), omitEmpty: make(map[string]bool)}
+	r.addFields(schema, t)
+	return schema
+}
+
+// addFields adds the JSON properties of the fields of t to schema, flattening
+// embedded structs the way encoding/json does
+func (r *Registry) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			r.addFields(schema, field.Type)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := r.schemaOf(field.Type)
+		if applyBinding(property, field.Type, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+		if strings.Contains(options, "omitempty") {
+			schema.omitEmpty[name] = true
+		}
+		schema.Properties[name] = property
+		schema.order = append(schema.order, name)
+	}
+}
+
+// applyBinding adds the constraints of the binding tag of a field to its
+// schema and reports whether the field is required
+func applyBinding(schema *Schema, t reflect.Type, tag string) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setBound(schema, t, key == "min", n)
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "dive":
+			// Later rules apply to the elements, which have their own schema
+			return required
+		}
+	}
+	return required
+}
+
+// setBound sets the minimum or maximum of a number, string length or array
+// length
+func setBound(schema *Schema, t reflect.Type, lower bool, n int) {
+	if schema.Ref != "" {
+		return
+	}
+	switch {
+	case t.Kind() == reflect.String && lower:
+		schema.MinLength = &n
+	case t.Kind() == reflect.String:
+		schema.MaxLength = &n
+	case schema.Type == "array" && lower:
+		schema.MinItems = &n
+	case schema.Type == "array":
+		schema.MaxItems = &n
+	case lower:
+		schema.Minimum = float(n)
+	default:
+		schema.Maximum = float(n)
+	}
+}
+
+// float returns a pointer to n as a float64
+func float(n int) *float64 {
+	f := float64(n)
+	return &f
+}
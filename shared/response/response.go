@@ -0,0 +1,24 @@
+// Package response defines the envelope every API response of the services
+// is wrapped in
+package response
+
+// APIResponse represents a generic API response
+type APIResponse struct {
+	Success bool         `json:"success"`
+	Message string       `json:"message"`
+	Data    interface{}  `json:"data,omitempty"`
+	Error   *ErrorDetail `json:"error,omitempty"`
+}
+
+// ErrorDetail describes a failed request in a machine-readable way
+type ErrorDetail struct {
+	Code    string                 `json:"code"` // Stable error code such as OUT_OF_STOCK
+	Fields  []FieldError           `json:"fields,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// FieldError describes why a request field is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,39 @@
+package tracing
+
+import "github.com/segmentio/kafka-go"
+
+// MessageCarrier adapts Kafka message headers to the OpenTelemetry
+// TextMapCarrier interface so trace context can travel with a message
+type MessageCarrier struct {
+	Message *kafka.Message
+}
+
+// Get returns the value of a header, or "" if the message has none
+func (c MessageCarrier) Get(key string) string {
+	for _, header := range c.Message.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Set replaces the value of a header, adding it if missing
+func (c MessageCarrier) Set(key, value string) {
+	for i, header := range c.Message.Headers {
+		if header.Key == key {
+			c.Message.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.Message.Headers = append(c.Message.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys returns the header keys of the message
+func (c MessageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Message.Headers))
+	for _, header := range c.Message.Headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
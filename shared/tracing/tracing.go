@@ -0,0 +1,64 @@
+// Package tracing sets up OpenTelemetry tracing and carries the trace
+// context between the services
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/shared/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Init configures the global tracer provider to export the spans of the
+// named service over OTLP/HTTP. The exporter honours the standard OTEL_EXPORTER_OTLP_* environment
+// variables; tracing is disabled when OTEL_SDK_DISABLED is "true". The
+// returned function flushes buffered spans and must be called on shutdown.
+func Init(ctx context.Context, serviceName string) (func(context.Context) error, error) {
+	// Always propagate W3C trace context, even when spans are not exported,
+	// so that traces started upstream are not broken here
+	setPropagator()
+
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		logging.For(logging.ComponentApp).Info("Tracing disabled")
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return InitWithExporter(exporter, serviceName), nil
+}
+
+// InitWithExporter configures the global tracer provider with the given
+// exporter, for example an in-memory exporter in tests, and the W3C trace
+// context propagator. The returned function flushes buffered spans and shuts
+// the provider down.
+func InitWithExporter(exporter sdktrace.SpanExporter, serviceName string) func(context.Context) error {
+	setPropagator()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(serviceName),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	logging.For(logging.ComponentApp).Info("Tracing initialized successfully")
+	return provider.Shutdown
+}
+
+// setPropagator installs the W3C trace context and baggage propagator
+func setPropagator() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// keptSpans is an in-memory exporter whose spans survive the shutdown of the
+// provider, which flushes them
+type keptSpans struct {
+	*tracetest.InMemoryExporter
+}
+
+func (keptSpans) Shutdown(context.Context) error { return nil }
+
+// TestTraceContextTravelsWithKafkaMessage follows an HTTP request that
+// publishes an event into the consumer of the event, as the two services do
+func TestTraceContextTravelsWithKafkaMessage(t *testing.T) {
+	exporter := keptSpans{tracetest.NewInMemoryExporter()}
+	shutdown := InitWithExporter(exporter, "test-service")
+
+	// The producer publishes while handling a traced request
+	var message kafka.Message
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(otelgin.Middleware("test-service"))
+	router.POST("/orders", func(c *gin.Context) {
+		ctx, span := otel.Tracer("producer").Start(c.Request.Context(), "orders publish", trace.WithSpanKind(trace.SpanKindProducer))
+		defer span.End()
+		otel.GetTextMapPropagator().Inject(ctx, MessageCarrier{Message: &message})
+		c.Status(http.StatusCreated)
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders", nil))
+
+	// The consumer continues the trace from the message headers
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), MessageCarrier{Message: &message})
+	_, span := otel.Tracer("consumer").Start(ctx, "orders process", trace.WithSpanKind(trace.SpanKindConsumer))
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want the request, publish and process spans", len(spans))
+	}
+
+	byName := make(map[string]tracetest.SpanStub)
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	request, publish, process := byName["/orders"], byName["orders publish"], byName["orders process"]
+	traceID := request.SpanContext.TraceID()
+	if !traceID.IsValid() {
+		t.Fatal("the request span was not recorded")
+	}
+	for _, span := range []tracetest.SpanStub{publish, process} {
+		if span.SpanContext.TraceID() != traceID {
+			t.Errorf("span %q has trace %s, want the trace of the request %s", span.Name, span.SpanContext.TraceID(), traceID)
+		}
+	}
+	if process.Parent.SpanID() != publish.SpanContext.SpanID() {
+		t.Errorf("the process span is a child of %s, want the publish span %s", process.Parent.SpanID(), publish.SpanContext.SpanID())
+	}
+	if !process.Parent.IsRemote() {
+		t.Error("the process span should continue a remote parent")
+	}
+}
+
+// TestMessageCarrierSetReplacesHeader checks that injecting twice, as a
+// retried publish does, leaves a single traceparent header
+func TestMessageCarrierSetReplacesHeader(t *testing.T) {
+	message := kafka.Message{Headers: []kafka.Header{{Key: "traceparent", Value: []byte("old")}}}
+	carrier := MessageCarrier{Message: &message}
+	carrier.Set("traceparent", "new")
+	carrier.Set("X-Request-ID", "abc")
+
+	if len(message.Headers) != 2 {
+		t.Fatalf("got headers %v, want traceparent and X-Request-ID", carrier.Keys())
+	}
+	if got := carrier.Get("traceparent"); got != "new" {
+		t.Errorf("traceparent = %q, want new", got)
+	}
+	if got := carrier.Get("missing"); got != "" {
+		t.Errorf("missing header = %q, want empty", got)
+	}
+}
@@ -0,0 +1,181 @@
+#!/bin/bash
+# =============================================================================
+# Concurrent Payment Test Script
+# =============================================================================
+# This script hammers the restaurant service with parallel requests to check
+# that payments are safe under concurrency. It tests:
+# 1. Many simultaneous payments for the same order complete exactly once
+# 2. Stock is decremented exactly once for that order
+# 3. Orders sharing food items in opposite order can be paid in parallel
+#    without deadlocks or server errors
+#
+# The services must already be running (e.g. with docker-compose up -d).
+# Usage: ./test_concurrency.sh [parallel_requests]
+# =============================================================================
+
+# Set text colors for better readability
+GREEN='\033[0;32m'  # Success messages
+RED='\033[0;31m'    # Error messages
+YELLOW='\033[0;33m' # Warning/info messages
+BLUE='\033[0;34m'   # Section headers
+NC='\033[0m'        # No Color (reset)
+
+# Service endpoint and number of parallel requests per scenario
+RESTAURANT_SERVICE=${RESTAURANT_SERVICE:-"http://localhost:8080"}
+PARALLEL=${1:-20}
+
+# Scratch directory for the responses of parallel requests
+WORK_DIR=$(mktemp -d)
+trap 'rm -rf "$WORK_DIR"' EXIT
+
+FAILURES=0
+
+# === Utility Functions ===
+
+# Log function - prints a message with timestamp and color
+log() {
+  local message=$1
+  local color=${2:-$BLUE}
+  echo -e "${color}[$(date +"%T")] $message${NC}"
+}
+
+# Pass function - prints a passed check
+pass() {
+  log "✅ $1" $GREEN
+}
+
+# Fail function - prints a failed check and records the failure
+fail() {
+  log "❌ $1" $RED
+  FAILURES=$((FAILURES + 1))
+}
+
+# json extracts a field from a JSON document using a Python expression on
+# the parsed value "d"
+json() {
+  python3 -c "import sys, json; d = json.load(sys.stdin); print($1)" 2>/dev/null
+}
+
+# api sends an authenticated JSON request and prints the response body
+api() {
+  local method=$1
+  local path=$2
+  local data=$3
+  curl -s -X "$method" -H "Content-Type: application/json" \
+    -H "Authorization: Bearer $TOKEN" ${data:+-d "$data"} \
+    "$RESTAURANT_SERVICE$path"
+}
+
+# stock prints the remaining quantity of a food item
+stock() {
+  api GET /food-items | json "[i['quantity'] for i in d['data'] if i['id'] == $1][0]"
+}
+
+# place_order places an order for the given items JSON and prints its ID
+place_order() {
+  api POST /orders "{\"items\": $1}" | json "d['data']['order_id']"
+}
+
+# pay_in_parallel pays every order ID passed as an argument at the same time,
+# writing one HTTP status code per request to $WORK_DIR/statuses
+pay_in_parallel() {
+  rm -f "$WORK_DIR"/status.*
+  local i=0
+  for order_id in "$@"; do
+    i=$((i + 1))
+    curl -s -o /dev/null -w "%{http_code}\n" -X POST \
+      -H "Content-Type: application/json" -H "Authorization: Bearer $TOKEN" \
+      -d "{\"order_id\": $order_id}" \
+      "$RESTAURANT_SERVICE/transactions" > "$WORK_DIR/status.$i" &
+  done
+  wait
+  cat "$WORK_DIR"/status.* > "$WORK_DIR/statuses"
+}
+
+# count prints how many requests in the last run returned the status code
+count() {
+  grep -c "^$1$" "$WORK_DIR/statuses"
+}
+
+# === Setup ===
+
+log "Starting concurrency tests with $PARALLEL parallel requests" $GREEN
+echo
+
+TOKEN=$(curl -s -X POST -H "Content-Type: application/json" \
+  -d '{"username": "testuser", "password": "password123"}' \
+  "$RESTAURANT_SERVICE/auth" | json "d['data']['token']")
+if [ -z "$TOKEN" ]; then
+  log "❌ Could not authenticate against $RESTAURANT_SERVICE" $RED
+  exit 1
+fi
+
+# === Scenario 1: Parallel payments for the same order ===
+log "Scenario 1: $PARALLEL simultaneous payments for one order" $BLUE
+
+stock_before=$(stock 1)
+order_id=$(place_order '[{"food_item_id": 1, "quantity": 1}]')
+if [ -z "$order_id" ]; then
+  log "❌ Could not place order" $RED
+  exit 1
+fi
+
+order_ids=()
+for _ in $(seq "$PARALLEL"); do
+  order_ids+=("$order_id")
+done
+pay_in_parallel "${order_ids[@]}"
+
+succeeded=$(count 200)
+rejected=$(count 400)
+if [ "$succeeded" -eq 1 ] && [ "$rejected" -eq $((PARALLEL - 1)) ]; then
+  pass "Exactly one payment succeeded, $rejected were rejected"
+else
+  fail "Expected 1 success and $((PARALLEL - 1)) rejections, got: $(sort "$WORK_DIR/statuses" | uniq -c | tr '\n' ' ')"
+fi
+
+stock_after=$(stock 1)
+if [ $((stock_before - stock_after)) -eq 1 ]; then
+  pass "Stock decremented exactly once ($stock_before -> $stock_after)"
+else
+  fail "Stock changed by $((stock_before - stock_after)) instead of 1 ($stock_before -> $stock_after)"
+fi
+echo
+
+# === Scenario 2: Parallel payments of orders with overlapping items ===
+log "Scenario 2: $PARALLEL orders sharing food items in opposite order" $BLUE
+
+stock1_before=$(stock 1)
+stock2_before=$(stock 2)
+order_ids=()
+for i in $(seq "$PARALLEL"); do
+  if [ $((i % 2)) -eq 0 ]; then
+    items='[{"food_item_id": 1, "quantity": 1}, {"food_item_id": 2, "quantity": 1}]'
+  else
+    items='[{"food_item_id": 2, "quantity": 1}, {"food_item_id": 1, "quantity": 1}]'
+  fi
+  order_ids+=("$(place_order "$items")")
+done
+pay_in_parallel "${order_ids[@]}"
+
+succeeded=$(count 200)
+if [ "$succeeded" -eq "$PARALLEL" ]; then
+  pass "All $PARALLEL payments succeeded without deadlocks"
+else
+  fail "Expected $PARALLEL successes, got: $(sort "$WORK_DIR/statuses" | uniq -c | tr '\n' ' ')"
+fi
+
+if [ $((stock1_before - $(stock 1))) -eq "$PARALLEL" ] && [ $((stock2_before - $(stock 2))) -eq "$PARALLEL" ]; then
+  pass "Stock of both food items decremented by $PARALLEL"
+else
+  fail "Stock of food items 1 and 2 did not decrease by $PARALLEL each"
+fi
+echo
+
+# === Summary ===
+if [ "$FAILURES" -eq 0 ]; then
+  log "All concurrency tests passed" $GREEN
+else
+  log "$FAILURES concurrency check(s) failed" $RED
+  exit 1
+fi
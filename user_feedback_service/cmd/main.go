@@ -1,66 +1,148 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"github.com/shared/apperrors"
+	"github.com/shared/health"
+	"github.com/shared/httpmw"
+	"github.com/shared/logging"
+	"github.com/shared/tracing"
 	"github.com/user_feedback_service/internal/api"
+	"github.com/user_feedback_service/internal/config"
 	"github.com/user_feedback_service/internal/db"
 	"github.com/user_feedback_service/internal/kafka"
+	"github.com/user_feedback_service/internal/metrics"
 	"github.com/user_feedback_service/internal/middleware"
+	"github.com/user_feedback_service/internal/repository/postgres"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
+// serviceName identifies this service in exported spans
+const serviceName = "feedback-service"
+
 func main() {
 	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+	envErr := godotenv.Load()
+
+	// Set up structured logging once the log levels are known
+	logging.Setup()
+	logger := logging.For(logging.ComponentApp)
+	if envErr != nil {
+		logger.Info("No .env file found, using environment variables")
 	}
 
-	// Set default values for environment variables if not set
-	if os.Getenv("PORT") == "" {
-		os.Setenv("PORT", "8081")
+	// Load and validate the configuration before touching any dependency
+	cfg, err := config.Load()
+	if err != nil {
+		logging.Fatal(logger, "Invalid configuration", "error", err)
 	}
-	if os.Getenv("JWT_SECRET") == "" {
-		os.Setenv("JWT_SECRET", "your-secret-key")
+	logger.Info("Effective configuration", cfg.Summary()...)
+
+	// "migrate" runs schema migrations without starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			logging.Fatal(logger, "Migration failed", "error", err)
+		}
+		return
+	}
+
+	// Initialize tracing before anything that creates spans
+	shutdownTracing, err := tracing.Init(context.Background(), serviceName)
+	if err != nil {
+		logging.Fatal(logger, "Failed to initialize tracing", "error", err)
 	}
 
 	// Initialize database connection
-	db.InitDB()
+	db.InitDB(cfg.Database)
 
-	// Create tables and seed data
-	db.MigrateSchema()
-	db.SeedData()
+	// Bring the schema up to date unless migrations are run separately
+	if cfg.MigrateOnStart {
+		migrateOnStart(cfg)
+	}
 
 	// Initialize Kafka (pass the database connection for consumer use)
-	kafka.InitKafkaConsumer(db.DB)
-	defer kafka.CloseKafkaConsumer()
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	defer stopConsumer()
+	kafka.InitKafkaConsumer(consumerCtx, db.DB, cfg.Kafka)
 
 	// Set up Gin router
-	router := gin.Default()
-
-	// Define API routes
-	router.POST("/auth", api.AuthHandler)
-
-	// Protected routes
-	authorized := router.Group("/")
-	authorized.Use(middleware.AuthMiddleware())
-	{
-		// Feedback endpoints
-		authorized.GET("/feedback", api.GetUserFeedbackHandler)
-		authorized.POST("/feedback", api.CreateFeedbackHandler)
-		authorized.PUT("/feedback/:id", api.UpdateFeedbackHandler)
-		authorized.DELETE("/feedback/:id", api.DeleteFeedbackHandler)
-
-		// Get feedback stats
-		authorized.GET("/feedback/stats", api.GetFeedbackStatsHandler)
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.Use(httpmw.RequestID())
+	router.Use(otelgin.Middleware(serviceName))
+	router.Use(metrics.Middleware())
+	router.Use(httpmw.AccessLog())
+	router.Use(apperrors.Middleware())
+
+	// Prometheus metrics
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		logging.Fatal(logger, "Failed to get database connection", "error", err)
 	}
+	metrics.RegisterDB(sqlDB)
+	metrics.RegisterKafka()
+
+	// Build the handlers on top of the Postgres repositories and register
+	// them together with their OpenAPI description
+	repos := postgres.NewRepos(db.DB, cfg.Database.QueryTimeout)
+	api.RegisterRoutes(router, api.Handlers{
+		Auth:     api.NewAuthHandler(repos.Users, cfg.JWTSecret.Reveal()),
+		Feedback: api.NewFeedbackHandler(repos.Feedback, postgres.NewUnitOfWork(db.DB, cfg.Database.QueryTimeout, cfg.Database.TxTimeout)),
+		Menu:     api.NewMenuHandler(repos.Menu),
+		Readiness: health.ReadinessHandler(
+			health.DatabaseCheck(sqlDB),
+			health.KafkaBrokerCheck(kafka.Ping),
+			health.ConsumerGroupCheck(kafka.DescribeGroup),
+		),
+		Metrics:     metrics.Handler(),
+		RequireAuth: middleware.AuthMiddleware(cfg.JWTSecret.Reveal()),
+	}, cfg.API.Versions)
 
 	// Start the server
-	port := os.Getenv("PORT")
-	log.Printf("Feedback Service starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    ":" + strconv.Itoa(cfg.Port),
+		Handler: router,
 	}
+
+	go func() {
+		logger.Info("Feedback Service starting", "port", cfg.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal(logger, "Failed to start server", "error", err)
+		}
+	}()
+
+	// Wait for a termination signal
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	logger.Info("Shutting down, waiting for in-flight work", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// Stop accepting requests and drain the in-flight ones
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Error shutting down server", "error", err)
+	}
+
+	// Stop the consumer after its current message, then release the database pool
+	stopConsumer()
+	kafka.CloseKafkaConsumer(shutdownCtx)
+	db.CloseDB()
+
+	// Export the remaining spans
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Error shutting down tracing", "error", err)
+	}
+
+	logger.Info("Feedback Service stopped")
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/user_feedback_service/internal/api"
+)
+
+// TestGeneratedFilesUpToDate fails when the routes no longer match the
+// committed OpenAPI document or client, like openapi-gen -check
+func TestGeneratedFilesUpToDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	doc := api.RegisterRoutes(gin.New(), api.Handlers{}, nil).Document()
+	spec, client, err := doc.Files("client", api.LatestVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string][]byte{"../../api/openapi.json": spec, "../../pkg/client/client_gen.go": client} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date, run go generate ./...", path)
+		}
+	}
+}
@@ -1,36 +1,46 @@
 package api
 
 import (
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/user_feedback_service/internal/db"
+	"github.com/shared/apperrors"
+	"github.com/user_feedback_service/internal/metrics"
 	"github.com/user_feedback_service/internal/models"
+	"github.com/user_feedback_service/internal/repository"
 )
 
-// AuthHandler handles user authentication
-func AuthHandler(c *gin.Context) {
+// AuthHandler serves authentication
+type AuthHandler struct {
+	users     repository.UserRepo
+	jwtSecret string
+}
+
+// NewAuthHandler creates an AuthHandler issuing tokens signed with jwtSecret
+func NewAuthHandler(users repository.UserRepo, jwtSecret string) *AuthHandler {
+	return &AuthHandler{users: users, jwtSecret: jwtSecret}
+}
+
+// Login handles user authentication
+func (h *AuthHandler) Login(c *gin.Context) {
 	var loginRequest models.LoginRequest
 	if err := c.ShouldBindJSON(&loginRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format",
-		})
+		c.Error(apperrors.FromBinding(err))
 		return
 	}
 
 	// Find the user in the database
-	var user models.User
-	result := db.DB.Where("username = ?", loginRequest.Username).First(&user)
-	if result.Error != nil {
-		c.JSON(http.StatusUnauthorized, models.APIResponse{
-			Success: false,
-			Message: "Invalid username or password",
-		})
+	user, err := h.users.GetByUsername(c.Request.Context(), loginRequest.Username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.Error(apperrors.Unauthorized(apperrors.CodeInvalidCredentials, "Invalid username or password"))
+			return
+		}
+		c.Error(err)
 		return
 	}
 
@@ -45,12 +55,9 @@ func AuthHandler(c *gin.Context) {
 	})
 
 	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	tokenString, err := token.SignedString([]byte(h.jwtSecret))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Could not generate token",
-		})
+		c.Error(apperrors.Internal(err))
 		return
 	}
 
@@ -64,17 +71,25 @@ func AuthHandler(c *gin.Context) {
 	})
 }
 
-// GetUserFeedbackHandler returns all feedback from the authenticated user
-func GetUserFeedbackHandler(c *gin.Context) {
+// FeedbackHandler serves the feedback endpoints
+type FeedbackHandler struct {
+	feedback repository.FeedbackRepo
+	uow      repository.UnitOfWork
+}
+
+// NewFeedbackHandler creates a FeedbackHandler. Feedback is created in a
+// unit of work so that the order checks and the insert see the same data.
+func NewFeedbackHandler(feedback repository.FeedbackRepo, uow repository.UnitOfWork) *FeedbackHandler {
+	return &FeedbackHandler{feedback: feedback, uow: uow}
+}
+
+// List returns all feedback from the authenticated user
+func (h *FeedbackHandler) List(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 
-	var feedbacks []models.Feedback
-	result := db.DB.Where("user_id = ?", userID).Find(&feedbacks)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error fetching feedback",
-		})
+	feedbacks, err := h.feedback.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -85,31 +100,16 @@ func GetUserFeedbackHandler(c *gin.Context) {
 	})
 }
 
-// CreateFeedbackHandler creates new feedback for an order
-func CreateFeedbackHandler(c *gin.Context) {
+// Create creates new feedback for an order
+func (h *FeedbackHandler) Create(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 
 	var feedbackRequest models.FeedbackRequest
 	if err := c.ShouldBindJSON(&feedbackRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format: " + err.Error(),
-		})
-		return
-	}
-
-	// Check if the user has already provided feedback for this order
-	var existingFeedback models.Feedback
-	result := db.DB.Where("order_id = ? AND user_id = ?", feedbackRequest.OrderID, userID).First(&existingFeedback)
-	if result.Error == nil {
-		c.JSON(http.StatusConflict, models.APIResponse{
-			Success: false,
-			Message: "You have already provided feedback for this order",
-		})
+		c.Error(apperrors.FromBinding(err))
 		return
 	}
 
-	// Create the feedback
 	feedback := models.Feedback{
 		OrderID: feedbackRequest.OrderID,
 		UserID:  userID,
@@ -117,15 +117,50 @@ func CreateFeedbackHandler(c *gin.Context) {
 		Comment: feedbackRequest.Comment,
 	}
 
-	result = db.DB.Create(&feedback)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error creating feedback: " + result.Error.Error(),
-		})
+	err := h.uow.Do(c.Request.Context(), func(repos repository.Repos) error {
+		// Make sure the order exists, belongs to the user and has been completed
+		order, err := repos.Orders.Get(c.Request.Context(), feedbackRequest.OrderID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return apperrors.NotFound(apperrors.CodeOrderNotFound, "Order not found").
+					WithDetail("order_id", feedbackRequest.OrderID)
+			}
+			return err
+		}
+		if order.UserID != userID {
+			return apperrors.Forbidden(apperrors.CodeOrderNotOwned, "You can only provide feedback for your own orders")
+		}
+		if !order.IsFeedbackAllowed() {
+			return apperrors.Invalid(apperrors.CodeOrderNotCompleted, "Feedback can only be provided once the order has been completed").
+				WithDetail("order_id", order.ID).
+				WithDetail("status", order.Status)
+		}
+
+		// Check if the user has already provided feedback for this order
+		exists, err := repos.Feedback.ExistsForOrder(c.Request.Context(), feedbackRequest.OrderID, userID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return errFeedbackDuplicate(feedbackRequest.OrderID)
+		}
+
+		// Create the feedback, recording the restaurant of the order. A
+		// concurrent request may have rated the order since the check.
+		feedback.RestaurantID = order.RestaurantID
+		err = repos.Feedback.Create(c.Request.Context(), &feedback)
+		if errors.Is(err, repository.ErrDuplicate) {
+			return errFeedbackDuplicate(feedbackRequest.OrderID)
+		}
+		return err
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	metrics.FeedbackCreated.WithLabelValues(strconv.Itoa(int(feedback.Rating))).Inc()
+
 	c.JSON(http.StatusCreated, models.APIResponse{
 		Success: true,
 		Message: "Feedback submitted successfully",
@@ -133,59 +168,41 @@ func CreateFeedbackHandler(c *gin.Context) {
 	})
 }
 
-// UpdateFeedbackHandler updates existing feedback
-func UpdateFeedbackHandler(c *gin.Context) {
+// Update updates existing feedback
+func (h *FeedbackHandler) Update(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 	feedbackID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid feedback ID",
-		})
+		c.Error(errInvalidFeedbackID())
 		return
 	}
 
 	var updateRequest models.FeedbackUpdateRequest
 	if err := c.ShouldBindJSON(&updateRequest); err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid request format: " + err.Error(),
-		})
+		c.Error(apperrors.FromBinding(err))
 		return
 	}
 
 	// Find the feedback to ensure it belongs to the user
-	var feedback models.Feedback
-	result := db.DB.Where("id = ? AND user_id = ?", feedbackID, userID).First(&feedback)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Success: false,
-			Message: "Feedback not found or not authorized to update",
-		})
+	feedback, err := h.feedback.GetForUser(c.Request.Context(), uint(feedbackID), userID)
+	if err != nil {
+		c.Error(errFeedbackNotFound(err, "Feedback not found or not authorized to update"))
 		return
 	}
 
 	// Update the feedback
-	updates := map[string]interface{}{}
 	if updateRequest.Rating > 0 {
-		updates["rating"] = updateRequest.Rating
+		feedback.Rating = updateRequest.Rating
 	}
 	if updateRequest.Comment != "" {
-		updates["comment"] = updateRequest.Comment
+		feedback.Comment = updateRequest.Comment
 	}
 
-	result = db.DB.Model(&feedback).Updates(updates)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error updating feedback: " + result.Error.Error(),
-		})
+	if err := h.feedback.Update(c.Request.Context(), &feedback); err != nil {
+		c.Error(err)
 		return
 	}
 
-	// Fetch the updated feedback
-	db.DB.First(&feedback, feedbackID)
-
 	c.JSON(http.StatusOK, models.APIResponse{
 		Success: true,
 		Message: "Feedback updated successfully",
@@ -193,36 +210,25 @@ func UpdateFeedbackHandler(c *gin.Context) {
 	})
 }
 
-// DeleteFeedbackHandler deletes feedback
-func DeleteFeedbackHandler(c *gin.Context) {
+// Delete deletes feedback
+func (h *FeedbackHandler) Delete(c *gin.Context) {
 	userID := c.MustGet("user_id").(uint)
 	feedbackID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, models.APIResponse{
-			Success: false,
-			Message: "Invalid feedback ID",
-		})
+		c.Error(errInvalidFeedbackID())
 		return
 	}
 
 	// Find the feedback to ensure it belongs to the user
-	var feedback models.Feedback
-	result := db.DB.Where("id = ? AND user_id = ?", feedbackID, userID).First(&feedback)
-	if result.Error != nil {
-		c.JSON(http.StatusNotFound, models.APIResponse{
-			Success: false,
-			Message: "Feedback not found or not authorized to delete",
-		})
+	feedback, err := h.feedback.GetForUser(c.Request.Context(), uint(feedbackID), userID)
+	if err != nil {
+		c.Error(errFeedbackNotFound(err, "Feedback not found or not authorized to delete"))
 		return
 	}
 
 	// Delete the feedback
-	result = db.DB.Delete(&feedback)
-	if result.Error != nil {
-		c.JSON(http.StatusInternalServerError, models.APIResponse{
-			Success: false,
-			Message: "Error deleting feedback: " + result.Error.Error(),
-		})
+	if err := h.feedback.Delete(c.Request.Context(), feedback.ID); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -232,39 +238,23 @@ func DeleteFeedbackHandler(c *gin.Context) {
 	})
 }
 
-// GetFeedbackStatsHandler returns feedback statistics
-func GetFeedbackStatsHandler(c *gin.Context) {
-	var stats struct {
-		TotalFeedback int64   `json:"total_feedback"`
-		AverageRating float64 `json:"average_rating"`
-		RatingCounts  []struct {
-			Rating int   `json:"rating"`
-			Count  int64 `json:"count"`
-		} `json:"rating_counts"`
+// Stats returns feedback statistics, of the restaurant in the restaurant_id
+// query parameter if given
+func (h *FeedbackHandler) Stats(c *gin.Context) {
+	var restaurantID uint64
+	if param := c.Query("restaurant_id"); param != "" {
+		var err error
+		restaurantID, err = strconv.ParseUint(param, 10, 32)
+		if err != nil || restaurantID == 0 {
+			c.Error(apperrors.Validation(models.FieldError{Field: "restaurant_id", Message: "must be a positive integer"}))
+			return
+		}
 	}
 
-	// Get total feedback count
-	db.DB.Model(&models.Feedback{}).Count(&stats.TotalFeedback)
-
-	// Get average rating
-	db.DB.Model(&models.Feedback{}).Select("COALESCE(AVG(rating), 0) as average_rating").Row().Scan(&stats.AverageRating)
-
-	// Get count by rating
-	stats.RatingCounts = make([]struct {
-		Rating int   `json:"rating"`
-		Count  int64 `json:"count"`
-	}, 5)
-
-	for i := 1; i <= 5; i++ {
-		var count int64
-		db.DB.Model(&models.Feedback{}).Where("rating = ?", i).Count(&count)
-		stats.RatingCounts[i-1] = struct {
-			Rating int   `json:"rating"`
-			Count  int64 `json:"count"`
-		}{
-			Rating: i,
-			Count:  count,
-		}
+	stats, err := h.feedback.Stats(c.Request.Context(), uint(restaurantID))
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	c.JSON(http.StatusOK, models.APIResponse{
@@ -273,3 +263,74 @@ func GetFeedbackStatsHandler(c *gin.Context) {
 		Data:    stats,
 	})
 }
+
+// StatsByRestaurant returns the feedback count and average rating of each
+// restaurant
+func (h *FeedbackHandler) StatsByRestaurant(c *gin.Context) {
+	stats, err := h.feedback.StatsByRestaurant(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Feedback statistics by restaurant retrieved successfully",
+		Data:    stats,
+	})
+}
+
+// MenuHandler serves the stock status of menu items, as last reported by the
+// restaurant service
+type MenuHandler struct {
+	menu repository.MenuRepo
+}
+
+// NewMenuHandler creates a MenuHandler
+func NewMenuHandler(menu repository.MenuRepo) *MenuHandler {
+	return &MenuHandler{menu: menu}
+}
+
+// Availability returns whether the food items of a restaurant, and their
+// variants, are available, low on stock or sold out. Items never reported
+// low or sold out are left out.
+func (h *MenuHandler) Availability(c *gin.Context) {
+	restaurantID, err := strconv.ParseUint(c.Query("restaurant_id"), 10, 32)
+	if err != nil || restaurantID == 0 {
+		c.Error(apperrors.Validation(models.FieldError{Field: "restaurant_id", Message: "must be a positive integer"}))
+		return
+	}
+
+	statuses, err := h.menu.Availability(c.Request.Context(), uint(restaurantID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.APIResponse{
+		Success: true,
+		Message: "Menu availability retrieved successfully",
+		Data:    statuses,
+	})
+}
+
+// errInvalidFeedbackID reports a feedback ID path parameter that is not a
+// number
+func errInvalidFeedbackID() error {
+	return apperrors.Validation(models.FieldError{Field: "id", Message: "must be a positive integer"})
+}
+
+// errFeedbackDuplicate reports feedback for an order the user already rated
+func errFeedbackDuplicate(orderID uint) error {
+	return apperrors.Conflict(apperrors.CodeFeedbackDuplicate, "You have already provided feedback for this order").
+		WithDetail("order_id", orderID)
+}
+
+// errFeedbackNotFound reports feedback that does not exist or belongs to
+// someone else, passing other repository errors through
+func errFeedbackNotFound(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperrors.NotFound(apperrors.CodeFeedbackNotFound, message)
+	}
+	return err
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shared/apperrors"
+	"github.com/shared/response"
+	"github.com/user_feedback_service/internal/middleware"
+	"github.com/user_feedback_service/internal/models"
+	"github.com/user_feedback_service/internal/repository"
+	"github.com/user_feedback_service/internal/repository/memory"
+)
+
+const testSecret = "test-secret"
+
+// testServer serves the routes over the in-memory repositories
+type testServer struct {
+	router *gin.Engine
+	store  *memory.Store
+	alice  models.User
+	bob    models.User
+}
+
+// newTestServer creates a server with two users and no orders
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	s := &testServer{store: memory.NewStore()}
+	s.alice = s.store.AddUser(models.User{Username: "alice", Email: "alice@example.com"})
+	s.bob = s.store.AddUser(models.User{Username: "bob", Email: "bob@example.com"})
+
+	repos := s.store.Repos()
+	s.router = gin.New()
+	s.router.Use(apperrors.Middleware())
+	RegisterRoutes(s.router, Handlers{
+		Auth:        NewAuthHandler(repos.Users, testSecret),
+		Feedback:    NewFeedbackHandler(repos.Feedback, s.store),
+		Menu:        NewMenuHandler(repos.Menu),
+		RequireAuth: middleware.AuthMiddleware(testSecret),
+	}, nil)
+	return s
+}
+
+// do sends a request with body encoded as JSON, authenticated with token
+// unless it is empty, and decodes the response envelope
+func (s *testServer) do(t *testing.T, method, path, token string, body any) (int, response.APIResponse) {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+
+	var resp response.APIResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decoding %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+// login returns a token for the user
+func (s *testServer) login(t *testing.T, user models.User) string {
+	t.Helper()
+
+	status, resp := s.do(t, http.MethodPost, "/v1/auth", "", models.LoginRequest{Username: user.Username, Password: "password"})
+	if status != http.StatusOK {
+		t.Fatalf("login as %s: status %d, %+v", user.Username, status, resp.Error)
+	}
+	return resp.Data.(map[string]any)["token"].(string)
+}
+
+// errorCode returns the error code of a response, if it failed
+func errorCode(resp response.APIResponse) string {
+	if resp.Error == nil {
+		return ""
+	}
+	return resp.Error.Code
+}
+
+func TestCreateFeedback(t *testing.T) {
+	s := newTestServer(t)
+	s.store.AddOrder(models.Order{ID: 1, UserID: s.alice.ID, RestaurantID: 7, Status: models.OrderStatusCompleted})
+	s.store.AddOrder(models.Order{ID: 2, UserID: s.alice.ID, RestaurantID: 7, Status: "pending"})
+	alice, bob := s.login(t, s.alice), s.login(t, s.bob)
+
+	tests := []struct {
+		name    string
+		token   string
+		request models.FeedbackRequest
+		status  int
+		code    apperrors.Code
+	}{
+		{"completed order", alice, models.FeedbackRequest{OrderID: 1, Rating: 4}, http.StatusCreated, ""},
+		{"rated again", alice, models.FeedbackRequest{OrderID: 1, Rating: 5}, http.StatusConflict, apperrors.CodeFeedbackDuplicate},
+		{"order not completed", alice, models.FeedbackRequest{OrderID: 2, Rating: 4}, http.StatusBadRequest, apperrors.CodeOrderNotCompleted},
+		{"order of another user", bob, models.FeedbackRequest{OrderID: 1, Rating: 4}, http.StatusForbidden, apperrors.CodeOrderNotOwned},
+		{"unknown order", alice, models.FeedbackRequest{OrderID: 99, Rating: 4}, http.StatusNotFound, apperrors.CodeOrderNotFound},
+		{"rating out of range", alice, models.FeedbackRequest{OrderID: 1, Rating: 6}, http.StatusBadRequest, apperrors.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := s.do(t, http.MethodPost, "/v1/feedback", tt.token, tt.request)
+			if status != tt.status || errorCode(resp) != string(tt.code) {
+				t.Errorf("status %d, code %q, want %d %q", status, errorCode(resp), tt.status, tt.code)
+			}
+		})
+	}
+
+	// Only the feedback that was accepted is kept, recorded against the
+	// restaurant of the order
+	status, resp := s.do(t, http.MethodGet, "/v1/feedback", alice, nil)
+	feedbacks, _ := resp.Data.([]any)
+	if status != http.StatusOK || len(feedbacks) != 1 {
+		t.Fatalf("list: status %d, data %v, want one feedback", status, resp.Data)
+	}
+	if got := feedbacks[0].(map[string]any)["restaurant_id"]; got != float64(7) {
+		t.Errorf("restaurant_id = %v, want 7", got)
+	}
+}
+
+// racingUnitOfWork runs units of work on the store as if another request
+// rated every order right after the duplicate check
+type racingUnitOfWork struct {
+	*memory.Store
+}
+
+func (u racingUnitOfWork) Do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	return u.Store.Do(ctx, func(repos repository.Repos) error {
+		repos.Feedback = uncheckedFeedbackRepo{repos.Feedback}
+		return fn(repos)
+	})
+}
+
+// uncheckedFeedbackRepo reports that no order has been rated yet
+type uncheckedFeedbackRepo struct {
+	repository.FeedbackRepo
+}
+
+func (uncheckedFeedbackRepo) ExistsForOrder(context.Context, uint, uint) (bool, error) {
+	return false, nil
+}
+
+func TestCreateFeedbackRacingDuplicate(t *testing.T) {
+	s := newTestServer(t)
+	s.store.AddOrder(models.Order{ID: 1, UserID: s.alice.ID, RestaurantID: 7, Status: models.OrderStatusCompleted})
+	s.store.Repos().Feedback.Create(context.Background(), &models.Feedback{OrderID: 1, UserID: s.alice.ID, RestaurantID: 7, Rating: 3})
+	alice := s.login(t, s.alice)
+
+	// The insert, rather than the check, finds the rating of the other
+	// request
+	repos := s.store.Repos()
+	s.router = gin.New()
+	s.router.Use(apperrors.Middleware())
+	RegisterRoutes(s.router, Handlers{
+		Feedback:    NewFeedbackHandler(repos.Feedback, racingUnitOfWork{s.store}),
+		RequireAuth: middleware.AuthMiddleware(testSecret),
+	}, nil)
+
+	status, resp := s.do(t, http.MethodPost, "/v1/feedback", alice, models.FeedbackRequest{OrderID: 1, Rating: 5})
+	if status != http.StatusConflict || errorCode(resp) != string(apperrors.CodeFeedbackDuplicate) {
+		t.Errorf("status %d, code %q, want 409 %s", status, errorCode(resp), apperrors.CodeFeedbackDuplicate)
+	}
+}
+
+func TestUpdateAndDeleteOwnFeedbackOnly(t *testing.T) {
+	s := newTestServer(t)
+	s.store.AddOrder(models.Order{ID: 1, UserID: s.alice.ID, RestaurantID: 7, Status: models.OrderStatusCompleted})
+	alice, bob := s.login(t, s.alice), s.login(t, s.bob)
+
+	status, resp := s.do(t, http.MethodPost, "/v1/feedback", alice, models.FeedbackRequest{OrderID: 1, Rating: 2})
+	if status != http.StatusCreated {
+		t.Fatalf("create: status %d, %+v", status, resp.Error)
+	}
+	path := "/v1/feedback/" + strconv.Itoa(int(resp.Data.(map[string]any)["id"].(float64)))
+
+	status, resp = s.do(t, http.MethodPut, path, bob, models.FeedbackUpdateRequest{Rating: 1})
+	if status != http.StatusNotFound || errorCode(resp) != string(apperrors.CodeFeedbackNotFound) {
+		t.Errorf("update as another user: status %d, code %q, want 404 %s", status, errorCode(resp), apperrors.CodeFeedbackNotFound)
+	}
+	status, resp = s.do(t, http.MethodPut, path, alice, models.FeedbackUpdateRequest{Rating: 5, Comment: "Better than I thought"})
+	if status != http.StatusOK || resp.Data.(map[string]any)["rating"] != float64(5) {
+		t.Errorf("update: status %d, data %v, want rating 5", status, resp.Data)
+	}
+
+	status, resp = s.do(t, http.MethodDelete, path, bob, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("delete as another user: status %d, want 404", status)
+	}
+	status, resp = s.do(t, http.MethodDelete, path, alice, nil)
+	if status != http.StatusOK {
+		t.Errorf("delete: status %d, %+v", status, resp.Error)
+	}
+	status, _ = s.do(t, http.MethodDelete, path, alice, nil)
+	if status != http.StatusNotFound {
+		t.Errorf("delete again: status %d, want 404", status)
+	}
+}
+
+func TestFeedbackStats(t *testing.T) {
+	s := newTestServer(t)
+	for id, restaurantID := range map[uint]uint{1: 7, 2: 7, 3: 8} {
+		s.store.AddOrder(models.Order{ID: id, UserID: s.alice.ID, RestaurantID: restaurantID, Status: models.OrderStatusCompleted})
+	}
+	alice := s.login(t, s.alice)
+	for id, rating := range map[uint]uint8{1: 5, 2: 2, 3: 4} {
+		if status, resp := s.do(t, http.MethodPost, "/v1/feedback", alice, models.FeedbackRequest{OrderID: id, Rating: rating}); status != http.StatusCreated {
+			t.Fatalf("create: status %d, %+v", status, resp.Error)
+		}
+	}
+
+	tests := []struct {
+		path    string
+		total   float64
+		average float64
+	}{
+		{"/v1/feedback/stats", 3, 11.0 / 3},
+		{"/v1/feedback/stats?restaurant_id=7", 2, 3.5},
+		{"/v1/feedback/stats?restaurant_id=9", 0, 0},
+	}
+	for _, tt := range tests {
+		status, resp := s.do(t, http.MethodGet, tt.path, alice, nil)
+		if status != http.StatusOK {
+			t.Errorf("%s: status %d, %+v", tt.path, status, resp.Error)
+			continue
+		}
+		stats := resp.Data.(map[string]any)
+		if stats["total_feedback"] != tt.total || stats["average_rating"] != tt.average {
+			t.Errorf("%s: %v, want %v feedback averaging %v", tt.path, stats, tt.total, tt.average)
+		}
+	}
+
+	status, resp := s.do(t, http.MethodGet, "/v1/feedback/stats?restaurant_id=x", alice, nil)
+	if status != http.StatusBadRequest || errorCode(resp) != string(apperrors.CodeValidationFailed) {
+		t.Errorf("invalid restaurant_id: status %d, code %q, want 400 %s", status, errorCode(resp), apperrors.CodeValidationFailed)
+	}
+}
+
+func TestMenuAvailability(t *testing.T) {
+	s := newTestServer(t)
+	s.store.AddMenuItemStatus(models.MenuItemStatus{RestaurantID: 7, FoodItemID: 2, Name: "Vada", Status: models.MenuItemSoldOut})
+	s.store.AddMenuItemStatus(models.MenuItemStatus{RestaurantID: 7, FoodItemID: 1, Name: "Dosa", Status: models.MenuItemLowStock, Quantity: 3})
+	s.store.AddMenuItemStatus(models.MenuItemStatus{RestaurantID: 8, FoodItemID: 5, Name: "Idli", Status: models.MenuItemSoldOut})
+	alice := s.login(t, s.alice)
+
+	status, resp := s.do(t, http.MethodGet, "/v1/menu/availability?restaurant_id=7", alice, nil)
+	items, _ := resp.Data.([]any)
+	if status != http.StatusOK || len(items) != 2 {
+		t.Fatalf("status %d, data %v, want the two items of restaurant 7", status, resp.Data)
+	}
+	for i, want := range []string{"Dosa", "Vada"} {
+		if got := items[i].(map[string]any)["name"]; got != want {
+			t.Errorf("item %d = %v, want %s", i, got, want)
+		}
+	}
+
+	status, resp = s.do(t, http.MethodGet, "/v1/menu/availability", alice, nil)
+	if status != http.StatusBadRequest || errorCode(resp) != string(apperrors.CodeValidationFailed) {
+		t.Errorf("without restaurant_id: status %d, code %q, want 400 %s", status, errorCode(resp), apperrors.CodeValidationFailed)
+	}
+}
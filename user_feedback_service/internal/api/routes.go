@@ -0,0 +1,185 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shared/health"
+	"github.com/shared/httpmw"
+	"github.com/shared/openapi"
+	"github.com/user_feedback_service/internal/config"
+	"github.com/user_feedback_service/internal/metrics"
+	"github.com/user_feedback_service/internal/models"
+)
+
+// Info describes the API in the OpenAPI document
+var Info = openapi.Info{
+	Title:       "User Feedback Service",
+	Version:     "1.0.0",
+	Description: "Rate completed orders, read feedback statistics and check which menu items are sold out.",
+}
+
+// Handlers holds everything the routes are served by
+type Handlers struct {
+	Auth        *AuthHandler
+	Feedback    *FeedbackHandler
+	Menu        *MenuHandler
+	Readiness   gin.HandlerFunc
+	Metrics     gin.HandlerFunc
+	RequireAuth gin.HandlerFunc // Verifies the JWT of protected routes
+}
+
+// feedbackID documents the feedback ID path parameter
+var feedbackID = []openapi.Param{{Name: "id", Type: "integer", Description: "Feedback ID"}}
+
+// Unversioned names the routes without a version prefix. They serve version
+// 1 for clients that predate versioning.
+const Unversioned = "unversioned"
+
+// LatestVersion is the API version new clients should use
+const LatestVersion = "v1"
+
+// endpoint is an API route together with its handler
+type endpoint struct {
+	secured bool // Requires a JWT
+	route   openapi.Route
+	handler gin.HandlerFunc
+}
+
+// apiVersion is an API version and the endpoints it serves
+type apiVersion struct {
+	name      string
+	endpoints func(h Handlers) []endpoint
+}
+
+// versions lists the served API versions. A new version starts from the
+// endpoints of the previous one and replaces those whose contract changed,
+// so that both run side by side.
+var versions = []apiVersion{
+	{name: Unversioned, endpoints: v1},
+	{name: "v1", endpoints: v1},
+}
+
+// RegisterRoutes registers every route of the service on router and
+// describes it in the returned OpenAPI registry. policies holds the
+// deprecation schedule of each API version.
+func RegisterRoutes(router *gin.Engine, h Handlers, policies map[string]config.VersionPolicy) *openapi.Registry {
+	registry := openapi.NewRegistry(Info)
+	ops := registry.Group(&router.RouterGroup)
+
+	// Health endpoints for Kubernetes probes
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/healthz", OperationID: "liveness", Tag: "Operations",
+		Summary: "Report whether the process is running",
+		Raw:     &openapi.RawResponse{ContentType: "application/json"},
+	}, health.LivenessHandler)
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/readyz", OperationID: "readiness", Tag: "Operations",
+		Summary: "Check the dependencies of the service",
+		Raw:     &openapi.RawResponse{ContentType: "application/json", Body: health.Report{}},
+		Errors:  []int{http.StatusServiceUnavailable},
+	}, h.Readiness)
+
+	// Prometheus metrics
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/metrics", OperationID: "metrics", Tag: "Operations",
+		Summary: "Export Prometheus metrics",
+		Raw:     &openapi.RawResponse{ContentType: "text/plain"},
+	}, h.Metrics)
+
+	// API documentation
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/openapi.json", OperationID: "openAPI", Tag: "Documentation",
+		Summary: "Get this OpenAPI document",
+		Raw:     &openapi.RawResponse{ContentType: "application/json"},
+	}, registry.SpecHandler())
+	ops.Handle(openapi.Route{
+		Method: http.MethodGet, Path: "/docs", OperationID: "docs", Tag: "Documentation",
+		Summary: "Browse this document in Swagger UI",
+		Raw:     &openapi.RawResponse{ContentType: "text/html"},
+	}, registry.DocsHandler("openapi.json"))
+
+	// The API in every version, plus the unversioned routes that old clients
+	// still call, each announcing its deprecation as configured
+	for _, version := range versions {
+		prefix, docVersion := "/"+version.name, version.name
+		if version.name == Unversioned {
+			prefix, docVersion = "/", ""
+		}
+		policy := policies[version.name]
+
+		group := router.Group(prefix)
+		group.Use(metrics.APIVersion(version.name), httpmw.Deprecation(policy))
+		authorized := group.Group("/")
+		authorized.Use(h.RequireAuth)
+
+		public := registry.Group(group).Version(docVersion, !policy.Deprecated.IsZero())
+		secured := registry.SecuredGroup(authorized).Version(docVersion, !policy.Deprecated.IsZero())
+		for _, e := range version.endpoints(h) {
+			if e.secured {
+				secured.Handle(e.route, e.handler)
+			} else {
+				public.Handle(e.route, e.handler)
+			}
+		}
+	}
+
+	return registry
+}
+
+// v1 returns the endpoints of version 1 of the API
+func v1(h Handlers) []endpoint {
+	return []endpoint{
+		{route: openapi.Route{
+			Method: http.MethodPost, Path: "/auth", OperationID: "login", Tag: "Auth",
+			Summary:  "Exchange a username and password for a JWT",
+			Request:  models.LoginRequest{},
+			Response: models.LoginResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized},
+		}, handler: h.Auth.Login},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/feedback", OperationID: "listFeedback", Tag: "Feedback",
+			Summary:  "List the feedback of the authenticated user",
+			Response: []models.Feedback{},
+		}, handler: h.Feedback.List},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodPost, Path: "/feedback", OperationID: "createFeedback", Tag: "Feedback",
+			Summary:  "Rate a completed order",
+			Request:  models.FeedbackRequest{},
+			Response: models.Feedback{},
+			Status:   http.StatusCreated,
+			Errors:   []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict},
+		}, handler: h.Feedback.Create},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodPut, Path: "/feedback/:id", OperationID: "updateFeedback", Tag: "Feedback",
+			Summary:  "Change the rating or comment of feedback",
+			Params:   feedbackID,
+			Request:  models.FeedbackUpdateRequest{},
+			Response: models.Feedback{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		}, handler: h.Feedback.Update},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodDelete, Path: "/feedback/:id", OperationID: "deleteFeedback", Tag: "Feedback",
+			Summary: "Delete feedback",
+			Params:  feedbackID,
+			Errors:  []int{http.StatusBadRequest, http.StatusNotFound},
+		}, handler: h.Feedback.Delete},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/feedback/stats", OperationID: "getFeedbackStats", Tag: "Feedback",
+			Summary:  "Get the number of ratings and the average rating, of one restaurant with ?restaurant_id=",
+			Response: models.FeedbackStats{},
+			Errors:   []int{http.StatusBadRequest},
+		}, handler: h.Feedback.Stats},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/feedback/stats/restaurants", OperationID: "getFeedbackStatsByRestaurant", Tag: "Feedback",
+			Summary:  "Get the number of ratings and the average rating of each restaurant",
+			Response: []models.RestaurantFeedbackStats{},
+		}, handler: h.Feedback.StatsByRestaurant},
+		{secured: true, route: openapi.Route{
+			Method: http.MethodGet, Path: "/menu/availability", OperationID: "getMenuAvailability", Tag: "Menu",
+			Summary:  "Get the stock status of the food items of a restaurant with ?restaurant_id=",
+			Response: []models.MenuItemStatus{},
+			Errors:   []int{http.StatusBadRequest},
+		}, handler: h.Menu.Availability},
+	}
+}
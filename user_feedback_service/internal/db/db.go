@@ -1,68 +1,72 @@
 package db
 
 import (
-	"fmt"
-	"log"
-	"os"
+	"context"
 
+	"github.com/shared/logging"
+	"github.com/user_feedback_service/internal/config"
 	"github.com/user_feedback_service/internal/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/plugin/opentelemetry/tracing"
 )
 
 var DB *gorm.DB
 
 // InitDB initializes the database connection using GORM
-func InitDB() {
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
+func InitDB(cfg config.DatabaseConfig) {
+	dsn := cfg.DSN()
 
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: gormLogger{},
 	})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to connect to database", "error", err)
+	}
+
+	// Record every query as a span
+	if err := DB.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to enable database tracing", "error", err)
 	}
 
 	// Get generic database object sql.DB to use its functions
 	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatalf("Failed to get database connection: %v", err)
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to get database connection", "error", err)
 	}
 
-	// Configure connection pool
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
+	// Bound the pool so a burst of requests queues for a connection instead
+	// of exhausting the database
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 
 	// Make sure connection is alive
-	if err := sqlDB.Ping(); err != nil {
-		log.Fatalf("Failed to ping database: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.QueryTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		logging.Fatal(logging.For(logging.ComponentDB), "Failed to ping database", "error", err)
 	}
 
-	log.Println("Successfully connected to database")
+	logging.For(logging.ComponentDB).Info("Successfully connected to database")
 }
 
-// MigrateSchema creates or updates the database schema
-func MigrateSchema() {
-	log.Println("Migrating database schema...")
-
-	// Auto migrate the schema
-	err := DB.AutoMigrate(&models.User{}, &models.Feedback{})
+// CloseDB closes the underlying database connection pool
+func CloseDB() {
+	sqlDB, err := DB.DB()
 	if err != nil {
-		log.Fatalf("Failed to migrate database schema: %v", err)
+		logging.For(logging.ComponentDB).Error("Error getting database connection", "error", err)
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		logging.For(logging.ComponentDB).Error("Error closing database", "error", err)
 	}
-
-	log.Println("Database schema migration completed")
 }
 
-// SeedData adds some initial data to the database
+// SeedData adds some initial data to the database. It is only used in the
+// dev profile.
 func SeedData() {
 	// Check if users already exist
 	var userCount int64
@@ -78,9 +82,9 @@ func SeedData() {
 
 		result := DB.Create(&defaultUser)
 		if result.Error != nil {
-			log.Fatalf("Failed to insert default user: %v", result.Error)
+			logging.Fatal(logging.For(logging.ComponentDB), "Failed to insert default user", "error", result.Error)
 		}
 
-		log.Println("Successfully seeded default user")
+		logging.For(logging.ComponentDB).Info("Successfully seeded default user")
 	}
 }
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shared/logging"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration above which queries are logged as warnings
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger sends GORM logs to the structured db logger. SQL statements are
+// only logged at debug level; slow queries and errors are always logged.
+type gormLogger struct{}
+
+func (gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	// The level is controlled by LOG_LEVEL_DB instead
+	return gormLogger{}
+}
+
+func (gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	logging.For(logging.ComponentDB).InfoContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	logging.For(logging.ComponentDB).WarnContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	logging.For(logging.ComponentDB).ErrorContext(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	args := []any{"sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds()}
+
+	logger := logging.For(logging.ComponentDB)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+		logger.ErrorContext(ctx, "Query failed", append(args, "error", err)...)
+	case elapsed > slowQueryThreshold:
+		logger.WarnContext(ctx, "Slow query", args...)
+	default:
+		logger.DebugContext(ctx, "Query executed", args...)
+	}
+}
@@ -3,88 +3,478 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
-	"os"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	"github.com/shared/logging"
+	"github.com/shared/tracing"
+	"github.com/user_feedback_service/internal/config"
 	"github.com/user_feedback_service/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
-	OrderTopic = "orders"
-	GroupID    = "feedback-service-group"
+	OrderTopic     = "orders"
+	InventoryTopic = "inventory"
+	GroupID        = "feedback-service-group"
+
+	// RequestIDHeader carries the ID of the request that caused an event
+	RequestIDHeader = "X-Request-ID"
+
+	cleanupInterval = time.Hour
+
+	// retryBackoff and maxRetryBackoff bound the wait between attempts to
+	// process an event that failed
+	retryBackoff    = time.Second
+	maxRetryBackoff = time.Minute
+
+	// processTimeout bounds the database work for a single event, so a stuck
+	// query cannot stall the partition indefinitely
+	processTimeout = 30 * time.Second
+
+	// defaultRestaurantID is the restaurant that orders from before the
+	// restaurant service had restaurants belong to
+	defaultRestaurantID = 1
+
+	tracerName = "github.com/user_feedback_service/internal/kafka"
 )
 
 var Reader *kafka.Reader
 var DB *gorm.DB
 
-// InitKafkaConsumer initializes the Kafka consumer
-func InitKafkaConsumer(db *gorm.DB) {
-	DB = db
-	kafkaBrokers := os.Getenv("KAFKA_BROKERS")
-	if kafkaBrokers == "" {
-		kafkaBrokers = "localhost:9092"
-	}
+// stopped is closed once the consumer loop has exited
+var stopped chan struct{}
+
+// brokers holds the configured broker addresses for health checks
+var brokers []string
 
+// InitKafkaConsumer initializes the Kafka consumer. Consumption stops when
+// ctx is cancelled.
+func InitKafkaConsumer(ctx context.Context, db *gorm.DB, cfg config.KafkaConfig) {
+	DB = db
+	brokers = cfg.Brokers
 	Reader = kafka.NewReader(kafka.ReaderConfig{
-		Brokers:        []string{kafkaBrokers},
-		Topic:          OrderTopic,
+		Brokers:        brokers,
+		GroupTopics:    []string{OrderTopic, InventoryTopic},
 		GroupID:        GroupID,
 		MinBytes:       10e3,
 		MaxBytes:       10e6,
 		CommitInterval: time.Second,
 	})
 
-	log.Println("Kafka consumer initialized successfully")
+	logging.For(logging.ComponentKafka).Info("Kafka consumer initialized successfully")
 
 	// Start consuming messages in a goroutine
-	go consumeMessages()
+	stopped = make(chan struct{})
+	go func() {
+		defer close(stopped)
+		consumeMessages(ctx)
+	}()
+	go cleanupProcessedEvents(ctx, cfg.ProcessedEventsRetention)
 }
 
-// CloseKafkaConsumer closes the Kafka consumer connection
-func CloseKafkaConsumer() {
+// CloseKafkaConsumer waits for the consumer loop to finish the message it is
+// processing and then closes the Kafka consumer connection. The context
+// passed to InitKafkaConsumer must be cancelled first; CloseKafkaConsumer
+// gives up waiting once ctx is done.
+func CloseKafkaConsumer(ctx context.Context) {
+	if stopped != nil {
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			logging.For(logging.ComponentKafka).Error("Timed out waiting for Kafka consumer to stop", "error", ctx.Err())
+		}
+	}
+
 	if Reader != nil {
 		if err := Reader.Close(); err != nil {
-			log.Printf("Error closing Kafka reader: %v", err)
+			logging.For(logging.ComponentKafka).Error("Error closing Kafka reader", "error", err)
+		}
+	}
+}
+
+// Ping checks that at least one of the configured brokers is reachable
+func Ping(ctx context.Context) error {
+	var err error
+	for _, broker := range brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
 		}
 	}
+	return fmt.Errorf("no reachable Kafka broker: %w", err)
+}
+
+// DescribeGroup returns the state and member count of the consumer group as
+// seen by the group coordinator, along with this reader's current lag
+func DescribeGroup(ctx context.Context) (string, int, int64, error) {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...)}
+	resp, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{
+		GroupIDs: []string{GroupID},
+	})
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if len(resp.Groups) == 0 {
+		return "", 0, 0, fmt.Errorf("consumer group %s not found", GroupID)
+	}
+
+	group := resp.Groups[0]
+	if group.Error != nil {
+		return "", 0, 0, group.Error
+	}
+	return group.GroupState, len(group.Members), Snapshot().Lag, nil
 }
 
-// consumeMessages consumes messages from Kafka
-func consumeMessages() {
-	ctx := context.Background()
+// consumeMessages consumes messages from Kafka until ctx is cancelled
+func consumeMessages(ctx context.Context) {
 	for {
-		message, err := Reader.ReadMessage(ctx)
+		message, err := Reader.FetchMessage(ctx)
 		if err != nil {
-			log.Printf("Error reading message: %v", err)
+			if ctx.Err() != nil {
+				logging.For(logging.ComponentKafka).Info("Kafka consumer stopped")
+				return
+			}
+			logging.For(logging.ComponentKafka).Error("Error reading message", "error", err)
 			continue
 		}
 
-		// Process the message
-		var orderEvent models.OrderEvent
-		if err := json.Unmarshal(message.Value, &orderEvent); err != nil {
-			log.Printf("Error unmarshaling order event: %v", err)
-			continue
+		// A later commit would move the offset past a failed event, so it is
+		// retried until it succeeds rather than skipped
+		if !processWithRetry(ctx, message) {
+			logging.For(logging.ComponentKafka).Info("Kafka consumer stopped")
+			return
+		}
+
+		// Commit the message offset. The commit must not be abandoned just
+		// because shutdown has started, otherwise the event is redelivered.
+		if err := Reader.CommitMessages(context.WithoutCancel(ctx), message); err != nil {
+			logging.For(logging.ComponentKafka).Error("Error committing message", "error", err)
 		}
+	}
+}
+
+// processWithRetry processes a message, retrying with exponential backoff
+// until it succeeds. It returns false if ctx is cancelled first, leaving the
+// offset uncommitted so the event is redelivered after a restart.
+func processWithRetry(ctx context.Context, message kafka.Message) bool {
+	backoff := retryBackoff
+	for {
+		err := processMessage(ctx, message)
+		if err == nil {
+			return true
+		}
+		logging.For(logging.ComponentKafka).Error("Error processing message, retrying", "topic", message.Topic, "partition", message.Partition, "offset", message.Offset, "backoff", backoff.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxRetryBackoff)
+	}
+}
+
+// processMessage applies a single order or inventory event. The
+// processed-events ledger is written in the same transaction as the side
+// effects, so an event is applied at most once even if Kafka redelivers it.
+func processMessage(ctx context.Context, message kafka.Message) (err error) {
+	// Continue the trace started by the producer. Processing must not be
+	// interrupted by shutdown, only the wait for the next message is.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), processTimeout)
+	defer cancel()
+	ctx = otel.GetTextMapPropagator().Extract(ctx, tracing.MessageCarrier{Message: &message})
+	if requestID := (tracing.MessageCarrier{Message: &message}).Get(RequestIDHeader); requestID != "" {
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(message.Topic),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(message.Partition)),
+			semconv.MessagingKafkaMessageOffset(int(message.Offset)),
+			semconv.MessagingKafkaConsumerGroup(GroupID),
+		),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	if message.Topic == InventoryTopic {
+		return processInventoryEvent(ctx, message)
+	}
+	return processOrderEvent(ctx, message)
+}
+
+// processOrderEvent applies an order event to the orders read model
+func processOrderEvent(ctx context.Context, message kafka.Message) error {
+	var orderEvent models.OrderEvent
+	if err := json.Unmarshal(message.Value, &orderEvent); err != nil {
+		// A malformed message will never succeed, so skip it
+		logging.For(logging.ComponentKafka).Error("Error unmarshaling order event", "error", err)
+		trace.SpanFromContext(ctx).RecordError(err)
+		return nil
+	}
 
-		// Process the order event, for example store in database for future feedback collection
-		log.Printf("Received order event: OrderID=%d, Status=%s", orderEvent.OrderID, orderEvent.Status)
+	logging.For(logging.ComponentKafka).InfoContext(ctx, "Received order event", "order_id", orderEvent.OrderID, "status", orderEvent.Status)
 
-		// Here you could store the order information or perform other processing
-		if orderEvent.Status == "completed" {
-			// Check if user exists, create if not
-			var user models.User
-			if result := DB.FirstOrCreate(&user, models.User{ID: uint(orderEvent.UserID)}); result.Error != nil {
-				log.Printf("Error creating user: %v", result.Error)
-				continue
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claim the event; if the ledger already has it, there is nothing to do
+		claimed, err := claimEvent(tx, message, orderEvent.EventID)
+		if err != nil || !claimed {
+			return err
+		}
+
+		if orderEvent.Status == models.OrderStatusCompleted {
+			// Create a placeholder for a user this service has not seen, so
+			// that they can leave feedback. Existing and deleted users are
+			// left alone.
+			user := placeholderUser(uint(orderEvent.UserID))
+			if result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&user); result.Error != nil {
+				return fmt.Errorf("creating user: %w", result.Error)
 			}
 		}
 
-		// Commit the message offset
-		if err := Reader.CommitMessages(ctx, message); err != nil {
-			log.Printf("Error committing message: %v", err)
+		return applyOrderEvent(tx, orderEvent)
+	})
+}
+
+// processInventoryEvent applies an inventory event about a food item to the
+// menu read model. Events about ingredients are of no interest here.
+func processInventoryEvent(ctx context.Context, message kafka.Message) error {
+	var inventoryEvent models.InventoryEvent
+	if err := json.Unmarshal(message.Value, &inventoryEvent); err != nil {
+		// A malformed message will never succeed, so skip it
+		logging.For(logging.ComponentKafka).Error("Error unmarshaling inventory event", "error", err)
+		trace.SpanFromContext(ctx).RecordError(err)
+		return nil
+	}
+	status, ok := menuItemStatus(inventoryEvent.Type)
+	if !ok || inventoryEvent.FoodItemID == 0 {
+		return nil
+	}
+
+	logging.For(logging.ComponentKafka).InfoContext(ctx, "Received inventory event", "food_item_id", inventoryEvent.FoodItemID, "variant_id", inventoryEvent.VariantID, "type", inventoryEvent.Type)
+
+	return DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claim the event; if the ledger already has it, there is nothing to do
+		claimed, err := claimEvent(tx, message, inventoryEvent.EventID)
+		if err != nil || !claimed {
+			return err
 		}
+		return applyInventoryEvent(tx, inventoryEvent, status)
+	})
+}
+
+// claimEvent records an event in the processed-events ledger. It returns
+// false if the ledger already has it.
+func claimEvent(tx *gorm.DB, message kafka.Message, eventID string) (bool, error) {
+	ledgerEntry := models.ProcessedEvent{
+		EventID:     eventKey(message, eventID),
+		Topic:       message.Topic,
+		Partition:   message.Partition,
+		Offset:      message.Offset,
+		ProcessedAt: time.Now(),
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&ledgerEntry)
+	if result.Error != nil {
+		return false, fmt.Errorf("recording processed event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		logging.For(logging.ComponentKafka).InfoContext(tx.Statement.Context, "Skipping duplicate event", "topic", message.Topic, "event_id", ledgerEntry.EventID)
+		return false, nil
+	}
+	return true, nil
+}
+
+// placeholderUser returns a user known only by ID. Usernames and emails are
+// unique, so they are derived from the ID.
+func placeholderUser(id uint) models.User {
+	name := "user-" + strconv.FormatUint(uint64(id), 10)
+	return models.User{ID: id, Username: name, Email: name + "@placeholder.invalid"}
+}
+
+// applyOrderEvent updates the local orders read model from an order event.
+// Events can arrive out of order, so stale events never move an order back
+// to an earlier state.
+func applyOrderEvent(tx *gorm.DB, orderEvent models.OrderEvent) error {
+	items := make([]models.OrderItem, 0, len(orderEvent.Items))
+	for _, item := range orderEvent.Items {
+		items = append(items, models.OrderItem{
+			OrderID:    uint(orderEvent.OrderID),
+			FoodItemID: uint(item.FoodItemID),
+			Name:       item.Name,
+			Quantity:   item.Quantity,
+		})
+	}
+
+	var order models.Order
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", orderEvent.OrderID).Limit(1).Find(&order)
+	if result.Error != nil {
+		return fmt.Errorf("loading order: %w", result.Error)
+	}
+
+	// First event seen for this order
+	if result.RowsAffected == 0 {
+		order = models.Order{
+			ID:           uint(orderEvent.OrderID),
+			UserID:       uint(orderEvent.UserID),
+			RestaurantID: restaurantID(orderEvent),
+			TotalPrice:   orderEvent.TotalPrice,
+			Status:       orderEvent.Status,
+			Items:        items,
+			LastEventAt:  orderEvent.Timestamp,
+		}
+		if err := tx.Create(&order).Error; err != nil {
+			return fmt.Errorf("creating order: %w", err)
+		}
+		return nil
+	}
+
+	if isStaleOrderEvent(order, orderEvent) {
+		logging.For(logging.ComponentKafka).InfoContext(tx.Statement.Context, "Ignoring stale order event", "order_id", orderEvent.OrderID, "status", orderEvent.Status)
+		return nil
+	}
+
+	updates := map[string]interface{}{
+		"user_id":       orderEvent.UserID,
+		"restaurant_id": restaurantID(orderEvent),
+		"total_price":   orderEvent.TotalPrice,
+		"status":        orderEvent.Status,
+		"last_event_at": orderEvent.Timestamp,
+	}
+	if err := tx.Model(&order).Updates(updates).Error; err != nil {
+		return fmt.Errorf("updating order: %w", err)
+	}
+
+	// Replace the items with the latest snapshot
+	if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderItem{}).Error; err != nil {
+		return fmt.Errorf("deleting order items: %w", err)
+	}
+	if len(items) > 0 {
+		if err := tx.Create(&items).Error; err != nil {
+			return fmt.Errorf("creating order items: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// applyInventoryEvent updates the status of a food item, or of one of its
+// variants, in the menu read model. Stale events are ignored so that a
+// delayed alert does not mark a restocked item sold out again.
+func applyInventoryEvent(tx *gorm.DB, inventoryEvent models.InventoryEvent, status string) error {
+	var menuItem models.MenuItemStatus
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("restaurant_id = ? AND food_item_id = ? AND variant_id = ?", inventoryEvent.RestaurantID, inventoryEvent.FoodItemID, inventoryEvent.VariantID).
+		Limit(1).Find(&menuItem)
+	if result.Error != nil {
+		return fmt.Errorf("loading menu item status: %w", result.Error)
+	}
+	if result.RowsAffected > 0 && isStaleInventoryEvent(menuItem, inventoryEvent) {
+		logging.For(logging.ComponentKafka).InfoContext(tx.Statement.Context, "Ignoring stale inventory event", "food_item_id", inventoryEvent.FoodItemID, "alert_id", inventoryEvent.AlertID, "type", inventoryEvent.Type)
+		return nil
+	}
+
+	menuItem = models.MenuItemStatus{
+		RestaurantID: uint(inventoryEvent.RestaurantID),
+		FoodItemID:   uint(inventoryEvent.FoodItemID),
+		VariantID:    uint(inventoryEvent.VariantID),
+		Name:         inventoryEvent.Name,
+		Variant:      inventoryEvent.Variant,
+		Status:       status,
+		Quantity:     int(inventoryEvent.Quantity),
+		LastAlertID:  inventoryEvent.AlertID,
+	}
+	if err := tx.Save(&menuItem).Error; err != nil {
+		return fmt.Errorf("saving menu item status: %w", err)
+	}
+	return nil
+}
+
+// isStaleOrderEvent reports whether an order event is older than the last
+// one applied to the order, or would move it back to pending
+func isStaleOrderEvent(order models.Order, orderEvent models.OrderEvent) bool {
+	return orderEvent.Timestamp < order.LastEventAt ||
+		(orderEvent.Status == models.OrderStatusPending && order.Status != models.OrderStatusPending)
+}
+
+// isStaleInventoryEvent reports whether an inventory event is not newer than
+// the last one applied to the menu item. Alerts raised together share a
+// timestamp, so events are ordered by their alert ID instead.
+func isStaleInventoryEvent(menuItem models.MenuItemStatus, inventoryEvent models.InventoryEvent) bool {
+	return inventoryEvent.AlertID <= menuItem.LastAlertID
+}
+
+// menuItemStatus returns the status a menu item is left in by an inventory
+// event, or false if the event is not about a food item
+func menuItemStatus(eventType string) (string, bool) {
+	switch eventType {
+	case models.EventMenuItemLowStock:
+		return models.MenuItemLowStock, true
+	case models.EventMenuItemSoldOut:
+		return models.MenuItemSoldOut, true
+	case models.EventMenuItemRestored:
+		return models.MenuItemAvailable, true
+	}
+	return "", false
+}
+
+// eventKey returns the deduplication key for an event. Events published
+// without an event ID fall back to their position in the topic.
+func eventKey(message kafka.Message, eventID string) string {
+	if eventID != "" {
+		return eventID
+	}
+	return fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset)
+}
+
+// cleanupProcessedEvents periodically deletes ledger entries older than the
+// retention period
+func cleanupProcessedEvents(ctx context.Context, retention time.Duration) {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		cutoff := time.Now().Add(-retention)
+		cleanupCtx, cancel := context.WithTimeout(ctx, processTimeout)
+		result := DB.WithContext(cleanupCtx).Where("processed_at < ?", cutoff).Delete(&models.ProcessedEvent{})
+		cancel()
+		if result.Error != nil {
+			logging.For(logging.ComponentKafka).Error("Error cleaning up processed events", "error", result.Error)
+			continue
+		}
+		if result.RowsAffected > 0 {
+			logging.For(logging.ComponentKafka).Info("Removed old processed events", "count", result.RowsAffected, "retention", retention.String())
+		}
+	}
+}
+
+// restaurantID returns the restaurant of an order event. Events published
+// before the restaurant service had restaurants are for the default one.
+func restaurantID(orderEvent models.OrderEvent) uint {
+	if orderEvent.RestaurantID == 0 {
+		return defaultRestaurantID
 	}
+	return uint(orderEvent.RestaurantID)
 }
@@ -0,0 +1,277 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/user_feedback_service/internal/migrations"
+	"github.com/user_feedback_service/internal/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// testDatabaseURL names the environment variable with the connection string
+// of a Postgres database the tests may create schemas in. The tests that
+// need Postgres are skipped without it.
+const testDatabaseURL = "TEST_DATABASE_URL"
+
+func TestIsStaleOrderEvent(t *testing.T) {
+	tests := []struct {
+		name  string
+		order models.Order
+		event models.OrderEvent
+		stale bool
+	}{
+		{
+			name:  "newer event",
+			order: models.Order{Status: models.OrderStatusPending, LastEventAt: 100},
+			event: models.OrderEvent{Status: models.OrderStatusCompleted, Timestamp: 200},
+		},
+		{
+			name:  "event of the same second",
+			order: models.Order{Status: models.OrderStatusPending, LastEventAt: 100},
+			event: models.OrderEvent{Status: models.OrderStatusCompleted, Timestamp: 100},
+		},
+		{
+			name:  "older event",
+			order: models.Order{Status: models.OrderStatusCompleted, LastEventAt: 200},
+			event: models.OrderEvent{Status: models.OrderStatusDelivered, Timestamp: 100},
+			stale: true,
+		},
+		{
+			name:  "pending after completed in the same second",
+			order: models.Order{Status: models.OrderStatusCompleted, LastEventAt: 100},
+			event: models.OrderEvent{Status: models.OrderStatusPending, Timestamp: 100},
+			stale: true,
+		},
+		{
+			name:  "pending after delivered with a later timestamp",
+			order: models.Order{Status: models.OrderStatusDelivered, LastEventAt: 100},
+			event: models.OrderEvent{Status: models.OrderStatusPending, Timestamp: 200},
+			stale: true,
+		},
+		{
+			name:  "pending again",
+			order: models.Order{Status: models.OrderStatusPending, LastEventAt: 100},
+			event: models.OrderEvent{Status: models.OrderStatusPending, Timestamp: 200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStaleOrderEvent(tt.order, tt.event); got != tt.stale {
+				t.Errorf("isStaleOrderEvent() = %v, want %v", got, tt.stale)
+			}
+		})
+	}
+}
+
+func TestIsStaleInventoryEvent(t *testing.T) {
+	menuItem := models.MenuItemStatus{Status: models.MenuItemSoldOut, LastAlertID: 7}
+
+	tests := []struct {
+		name    string
+		alertID int64
+		stale   bool
+	}{
+		{"later alert", 8, false},
+		{"same alert", 7, true},
+		{"earlier alert raised in the same second", 6, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := models.InventoryEvent{Type: models.EventMenuItemRestored, AlertID: tt.alertID}
+			if got := isStaleInventoryEvent(menuItem, event); got != tt.stale {
+				t.Errorf("isStaleInventoryEvent() = %v, want %v", got, tt.stale)
+			}
+		})
+	}
+}
+
+// openTestDB points the consumer at a new, migrated schema of the test
+// database. The schema is dropped when the test ends.
+func openTestDB(t *testing.T) {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseURL)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseURL)
+	}
+	config := &gorm.Config{Logger: gormlogger.Discard}
+
+	admin, err := gorm.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// pgx passes unknown settings on to the server as run-time parameters
+	separator := " "
+	if strings.Contains(dsn, "://") {
+		separator = "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+	}
+	db, err := gorm.Open(postgres.Open(dsn+separator+"search_path="+schema), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	migrator, err := migrations.New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+}
+
+// message returns a Kafka message at offset carrying event as JSON
+func message(t *testing.T, topic string, offset int64, event any) kafka.Message {
+	t.Helper()
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kafka.Message{Topic: topic, Partition: 0, Offset: offset, Value: value}
+}
+
+func TestProcessMessageRedelivered(t *testing.T) {
+	openTestDB(t)
+	ctx := context.Background()
+
+	t.Run("order event", func(t *testing.T) {
+		completed := message(t, OrderTopic, 1, models.OrderEvent{
+			EventID:      "order-1-completed",
+			OrderID:      1,
+			RestaurantID: 1,
+			UserID:       42,
+			TotalPrice:   12.5,
+			Status:       models.OrderStatusCompleted,
+			Items:        []models.Item{{FoodItemID: 3, Name: "Dosa", Quantity: 2}},
+			Timestamp:    100,
+		})
+		if err := processMessage(ctx, completed); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+
+		// Change the read model behind the consumer's back. Applying the
+		// event again would undo it.
+		if err := DB.Model(&models.Order{}).Where("id = ?", 1).Update("total_price", 99).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := processMessage(ctx, completed); err != nil {
+			t.Fatalf("processMessage() redelivered error = %v", err)
+		}
+
+		var order models.Order
+		if err := DB.Preload("Items").First(&order, 1).Error; err != nil {
+			t.Fatal(err)
+		}
+		if order.TotalPrice != 99 || order.Status != models.OrderStatusCompleted || len(order.Items) != 1 {
+			t.Errorf("order = %+v, want the redelivered event ignored", order)
+		}
+		checkLedger(t, "order-1-completed", 1)
+
+		// The customer is known, so they can leave feedback
+		var users int64
+		if err := DB.Model(&models.User{}).Where("id = ?", 42).Count(&users).Error; err != nil {
+			t.Fatal(err)
+		}
+		if users != 1 {
+			t.Errorf("users with ID 42 = %d, want a placeholder", users)
+		}
+	})
+
+	t.Run("event without an ID", func(t *testing.T) {
+		// Deduplicated by its offset instead
+		pending := message(t, OrderTopic, 2, models.OrderEvent{OrderID: 2, UserID: 42, TotalPrice: 8, Status: models.OrderStatusPending, Timestamp: 100})
+		if err := processMessage(ctx, pending); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+		if err := DB.Model(&models.Order{}).Where("id = ?", 2).Update("total_price", 99).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := processMessage(ctx, pending); err != nil {
+			t.Fatalf("processMessage() redelivered error = %v", err)
+		}
+
+		var order models.Order
+		if err := DB.First(&order, 2).Error; err != nil {
+			t.Fatal(err)
+		}
+		if order.TotalPrice != 99 {
+			t.Errorf("TotalPrice = %v, want the redelivered event ignored", order.TotalPrice)
+		}
+		checkLedger(t, "orders/0/2", 1)
+	})
+
+	t.Run("inventory event", func(t *testing.T) {
+		soldOut := message(t, InventoryTopic, 1, models.InventoryEvent{
+			EventID:      "alert-5",
+			Type:         models.EventMenuItemSoldOut,
+			AlertID:      5,
+			RestaurantID: 1,
+			FoodItemID:   3,
+			Name:         "Dosa",
+		})
+		if err := processMessage(ctx, soldOut); err != nil {
+			t.Fatalf("processMessage() error = %v", err)
+		}
+		if err := DB.Model(&models.MenuItemStatus{}).Where("food_item_id = ?", 3).Update("status", models.MenuItemAvailable).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := processMessage(ctx, soldOut); err != nil {
+			t.Fatalf("processMessage() redelivered error = %v", err)
+		}
+
+		var menuItem models.MenuItemStatus
+		if err := DB.Where("food_item_id = ?", 3).First(&menuItem).Error; err != nil {
+			t.Fatal(err)
+		}
+		if menuItem.Status != models.MenuItemAvailable {
+			t.Errorf("Status = %q, want the redelivered event ignored", menuItem.Status)
+		}
+		checkLedger(t, "alert-5", 1)
+	})
+}
+
+// checkLedger reports an event recorded in the processed-events ledger other
+// than want times
+func checkLedger(t *testing.T, eventID string, want int64) {
+	t.Helper()
+
+	var count int64
+	if err := DB.Model(&models.ProcessedEvent{}).Where("event_id = ?", eventID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != want {
+		t.Errorf("ledger entries of %s = %d, want %d", eventID, count, want)
+	}
+}
@@ -0,0 +1,39 @@
+package kafka
+
+import "sync"
+
+// ReaderSnapshot holds cumulative consumer counters since startup and the
+// current lag
+type ReaderSnapshot struct {
+	Messages   int64
+	Bytes      int64
+	Fetches    int64
+	Errors     int64
+	Rebalances int64
+	Lag        int64
+}
+
+var (
+	statsMu     sync.Mutex
+	readerTotal ReaderSnapshot
+)
+
+// Snapshot returns the cumulative consumer statistics. kafka-go resets its
+// counters on every Stats call, so all callers must go through Snapshot.
+func Snapshot() ReaderSnapshot {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+
+	if Reader == nil {
+		return readerTotal
+	}
+
+	stats := Reader.Stats()
+	readerTotal.Messages += stats.Messages
+	readerTotal.Bytes += stats.Bytes
+	readerTotal.Fetches += stats.Fetches
+	readerTotal.Errors += stats.Errors
+	readerTotal.Rebalances += stats.Rebalances
+	readerTotal.Lag = stats.Lag
+	return readerTotal
+}
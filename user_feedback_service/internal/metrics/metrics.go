@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/user_feedback_service/internal/kafka"
+)
+
+const namespace = "feedback"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	apiVersionRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "api_version_requests_total",
+		Help:      "API requests by API version and route, to track clients of old versions.",
+	}, []string{"version", "route"})
+
+	// FeedbackCreated counts submitted feedback by rating
+	FeedbackCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "feedback_created_total",
+		Help:      "Feedback submitted, by rating.",
+	}, []string{"rating"})
+)
+
+// Middleware records the count and latency of every request. Requests that
+// do not match a route are grouped under "unmatched" to bound cardinality.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// APIVersion counts the requests served by an API version
+func APIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		apiVersionRequests.WithLabelValues(version, c.FullPath()).Inc()
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// RegisterDB exposes the connection pool statistics of db
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "feedback_db"))
+}
+
+// RegisterKafka exposes the Kafka consumer statistics
+func RegisterKafka() {
+	prometheus.MustRegister(&kafkaCollector{})
+}
+
+var (
+	kafkaMessagesDesc = prometheus.NewDesc(namespace+"_kafka_reader_messages_total",
+		"Messages read by the Kafka consumer.", nil, nil)
+	kafkaBytesDesc = prometheus.NewDesc(namespace+"_kafka_reader_bytes_total",
+		"Bytes read by the Kafka consumer.", nil, nil)
+	kafkaFetchesDesc = prometheus.NewDesc(namespace+"_kafka_reader_fetches_total",
+		"Fetch requests made by the Kafka consumer.", nil, nil)
+	kafkaErrorsDesc = prometheus.NewDesc(namespace+"_kafka_reader_errors_total",
+		"Errors returned by the Kafka consumer.", nil, nil)
+	kafkaRebalancesDesc = prometheus.NewDesc(namespace+"_kafka_reader_rebalances_total",
+		"Consumer group rebalances seen by the Kafka consumer.", nil, nil)
+	kafkaLagDesc = prometheus.NewDesc(namespace+"_kafka_reader_lag",
+		"Messages between the consumer position and the end of the partition.", nil, nil)
+)
+
+// kafkaCollector reads the consumer statistics on every scrape
+type kafkaCollector struct{}
+
+func (kafkaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- kafkaMessagesDesc
+	ch <- kafkaBytesDesc
+	ch <- kafkaFetchesDesc
+	ch <- kafkaErrorsDesc
+	ch <- kafkaRebalancesDesc
+	ch <- kafkaLagDesc
+}
+
+func (kafkaCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := kafka.Snapshot()
+	ch <- prometheus.MustNewConstMetric(kafkaMessagesDesc, prometheus.CounterValue, float64(stats.Messages))
+	ch <- prometheus.MustNewConstMetric(kafkaBytesDesc, prometheus.CounterValue, float64(stats.Bytes))
+	ch <- prometheus.MustNewConstMetric(kafkaFetchesDesc, prometheus.CounterValue, float64(stats.Fetches))
+	ch <- prometheus.MustNewConstMetric(kafkaErrorsDesc, prometheus.CounterValue, float64(stats.Errors))
+	ch <- prometheus.MustNewConstMetric(kafkaRebalancesDesc, prometheus.CounterValue, float64(stats.Rebalances))
+	ch <- prometheus.MustNewConstMetric(kafkaLagDesc, prometheus.GaugeValue, float64(stats.Lag))
+}
@@ -2,25 +2,21 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
-	"github.com/user_feedback_service/internal/models"
+	"github.com/shared/apperrors"
 )
 
-// AuthMiddleware verifies the JWT token in the request header
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware verifies the JWT token in the request header against the
+// given signing secret
+func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the JWT token from the Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Authorization header is required",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Authorization header is required"))
 			c.Abort()
 			return
 		}
@@ -28,10 +24,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Check if the header has the correct format
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Authorization header must be in the format 'Bearer {token}'",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Authorization header must be in the format 'Bearer {token}'"))
 			c.Abort()
 			return
 		}
@@ -45,14 +38,11 @@ func AuthMiddleware() gin.HandlerFunc {
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
 			// Return the secret key
-			return []byte(os.Getenv("JWT_SECRET")), nil
+			return []byte(jwtSecret), nil
 		})
 
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Invalid or expired token",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Invalid or expired token"))
 			c.Abort()
 			return
 		}
@@ -67,10 +57,7 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Set("username", username)
 			c.Next()
 		} else {
-			c.JSON(http.StatusUnauthorized, models.APIResponse{
-				Success: false,
-				Message: "Invalid token claims",
-			})
+			c.Error(apperrors.Unauthorized(apperrors.CodeUnauthorized, "Invalid token claims"))
 			c.Abort()
 			return
 		}
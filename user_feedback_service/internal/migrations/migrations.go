@@ -0,0 +1,27 @@
+// Package migrations holds the schema migrations of the service, applied by
+// the shared migrator
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	"github.com/shared/migrate"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID identifies the Postgres advisory lock held while migrating this
+// service's schema
+const lockID = 7_263_514_002
+
+// New returns a migrator of the embedded migrations
+func New(db *sql.DB) (*migrate.Migrator, error) {
+	sqlFiles, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, sqlFiles, lockID)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS feedbacks;
+DROP TABLE IF EXISTS users;
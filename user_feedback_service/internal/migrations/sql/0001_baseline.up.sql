@@ -0,0 +1,28 @@
+-- Baseline schema matching the tables previously created by GORM
+-- AutoMigrate. IF NOT EXISTS lets existing databases adopt this version
+-- without changes.
+CREATE TABLE IF NOT EXISTS users (
+	id BIGSERIAL PRIMARY KEY,
+	username TEXT NOT NULL,
+	email TEXT NOT NULL,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ,
+	deleted_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS feedbacks (
+	id BIGSERIAL PRIMARY KEY,
+	order_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	rating SMALLINT NOT NULL,
+	comment TEXT,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ,
+	deleted_at TIMESTAMPTZ,
+	CONSTRAINT chk_feedbacks_rating CHECK (rating <= 5),
+	CONSTRAINT fk_feedbacks_user FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX IF NOT EXISTS idx_feedbacks_deleted_at ON feedbacks (deleted_at);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS processed_events (
+	event_id VARCHAR(255) PRIMARY KEY,
+	topic VARCHAR(255) NOT NULL,
+	partition BIGINT NOT NULL,
+	"offset" BIGINT NOT NULL,
+	processed_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON processed_events (processed_at);
@@ -0,0 +1,21 @@
+-- Read model of orders maintained from restaurant service events
+CREATE TABLE IF NOT EXISTS orders (
+	id BIGINT PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	total_price NUMERIC(10,2) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	last_event_at BIGINT NOT NULL,
+	created_at TIMESTAMPTZ,
+	updated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders (user_id);
+
+CREATE TABLE IF NOT EXISTS order_items (
+	id BIGSERIAL PRIMARY KEY,
+	order_id BIGINT NOT NULL,
+	food_item_id BIGINT NOT NULL,
+	name TEXT,
+	quantity BIGINT NOT NULL,
+	CONSTRAINT fk_orders_items FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
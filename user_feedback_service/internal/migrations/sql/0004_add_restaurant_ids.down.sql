@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_feedbacks_restaurant_id;
+ALTER TABLE feedbacks DROP COLUMN IF EXISTS restaurant_id;
+
+DROP INDEX IF EXISTS idx_orders_restaurant_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS restaurant_id;
@@ -0,0 +1,7 @@
+-- Orders and feedback belong to a restaurant of the restaurant service.
+-- Existing rows predate restaurants and belong to the default one, ID 1.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS restaurant_id BIGINT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_orders_restaurant_id ON orders (restaurant_id);
+
+ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS restaurant_id BIGINT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_feedbacks_restaurant_id ON feedbacks (restaurant_id);
@@ -0,0 +1,15 @@
+-- Read model of the stock status of food items and their variants,
+-- maintained from restaurant service inventory events. variant_id is 0 for a
+-- food item without variants.
+CREATE TABLE IF NOT EXISTS menu_item_statuses (
+	restaurant_id BIGINT NOT NULL,
+	food_item_id BIGINT NOT NULL,
+	variant_id BIGINT NOT NULL DEFAULT 0,
+	name TEXT NOT NULL,
+	variant TEXT NOT NULL DEFAULT '',
+	status VARCHAR(20) NOT NULL,
+	quantity BIGINT NOT NULL,
+	last_event_at BIGINT NOT NULL,
+	updated_at TIMESTAMPTZ,
+	PRIMARY KEY (restaurant_id, food_item_id, variant_id)
+);
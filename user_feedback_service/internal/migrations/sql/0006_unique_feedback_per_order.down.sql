@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_feedbacks_order_id_user_id;
@@ -0,0 +1,14 @@
+-- A user rates an order at most once. Duplicates left by concurrent
+-- requests are soft deleted, keeping the first, so that the index can be
+-- built.
+UPDATE feedbacks SET deleted_at = NOW()
+WHERE deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1 FROM feedbacks earlier
+		WHERE earlier.order_id = feedbacks.order_id
+			AND earlier.user_id = feedbacks.user_id
+			AND earlier.deleted_at IS NULL
+			AND earlier.id < feedbacks.id
+	);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feedbacks_order_id_user_id ON feedbacks (order_id, user_id) WHERE deleted_at IS NULL;
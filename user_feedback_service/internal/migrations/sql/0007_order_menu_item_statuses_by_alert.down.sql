@@ -0,0 +1,2 @@
+ALTER TABLE menu_item_statuses ADD COLUMN IF NOT EXISTS last_event_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE menu_item_statuses DROP COLUMN IF EXISTS last_alert_id;
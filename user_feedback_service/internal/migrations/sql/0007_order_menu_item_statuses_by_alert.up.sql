@@ -0,0 +1,5 @@
+-- Stock alerts raised together share a timestamp, so the menu read model
+-- orders inventory events by their alert ID instead. Existing rows take any
+-- later event.
+ALTER TABLE menu_item_statuses ADD COLUMN IF NOT EXISTS last_alert_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE menu_item_statuses DROP COLUMN IF EXISTS last_event_at;
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/shared/response"
 	"gorm.io/gorm"
 )
 
@@ -18,23 +19,84 @@ type User struct {
 
 // Feedback represents user feedback for orders
 type Feedback struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	OrderID   uint           `json:"order_id" gorm:"not null"`
-	UserID    uint           `json:"user_id" gorm:"not null"`
-	Rating    uint8          `json:"rating" gorm:"type:smallint;not null;check:rating <= 5"` // Rating from 1 to 5
-	Comment   string         `json:"comment" gorm:"type:text"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
-	User      User           `json:"-" gorm:"foreignKey:UserID"`
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	OrderID      uint           `json:"order_id" gorm:"not null;uniqueIndex:idx_feedbacks_order_id_user_id,where:deleted_at IS NULL"`
+	UserID       uint           `json:"user_id" gorm:"not null;uniqueIndex:idx_feedbacks_order_id_user_id,where:deleted_at IS NULL"`
+	RestaurantID uint           `json:"restaurant_id" gorm:"not null;index"`                    // Restaurant of the order
+	Rating       uint8          `json:"rating" gorm:"type:smallint;not null;check:rating <= 5"` // Rating from 1 to 5
+	Comment      string         `json:"comment" gorm:"type:text"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	User         User           `json:"-" gorm:"foreignKey:UserID"`
 }
 
-// Order represents an order from the restaurant service (for reference only)
+// Order is a read model of an order from the restaurant service, maintained
+// from the order events it publishes
 type Order struct {
-	ID         uint    `json:"id"`
-	UserID     uint    `json:"user_id"`
-	TotalPrice float64 `json:"total_price"`
-	Status     string  `json:"status"`
+	ID           uint        `json:"id" gorm:"primaryKey;autoIncrement:false"`
+	UserID       uint        `json:"user_id" gorm:"not null;index"`
+	RestaurantID uint        `json:"restaurant_id" gorm:"not null;index"`
+	TotalPrice   float64     `json:"total_price" gorm:"type:numeric(10,2);not null"`
+	Status       string      `json:"status" gorm:"size:20;not null"`
+	Items        []OrderItem `json:"items" gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE"`
+	LastEventAt  int64       `json:"-" gorm:"not null"` // Timestamp of the latest event applied
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// OrderItem represents an item in an order read model
+type OrderItem struct {
+	ID         uint   `json:"-" gorm:"primaryKey"`
+	OrderID    uint   `json:"-" gorm:"not null;index"`
+	FoodItemID uint   `json:"food_item_id" gorm:"not null"`
+	Name       string `json:"name"`
+	Quantity   int    `json:"quantity" gorm:"not null"`
+}
+
+// Order statuses as published by the restaurant service
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusCompleted = "completed"
+	OrderStatusDelivered = "delivered"
+)
+
+// IsFeedbackAllowed reports whether an order has reached a state in which
+// the customer can rate it
+func (o Order) IsFeedbackAllowed() bool {
+	return o.Status == OrderStatusCompleted || o.Status == OrderStatusDelivered
+}
+
+// MenuItemStatus is a read model of the stock status of a food item, or of
+// one of its variants, maintained from the inventory events of the
+// restaurant service
+type MenuItemStatus struct {
+	RestaurantID uint      `json:"restaurant_id" gorm:"primaryKey;autoIncrement:false"`
+	FoodItemID   uint      `json:"food_item_id" gorm:"primaryKey;autoIncrement:false"`
+	VariantID    uint      `json:"variant_id,omitempty" gorm:"primaryKey;autoIncrement:false"` // Zero for a food item without variants
+	Name         string    `json:"name" gorm:"not null"`
+	Variant      string    `json:"variant,omitempty" gorm:"not null"`
+	Status       string    `json:"status" gorm:"size:20;not null"`
+	Quantity     int       `json:"quantity" gorm:"not null"` // Portions left when the status last changed
+	LastAlertID  int64     `json:"-" gorm:"not null"`        // Stock alert of the latest event applied
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Stock statuses of menu items
+const (
+	MenuItemAvailable = "available"
+	MenuItemLowStock  = "low_stock"
+	MenuItemSoldOut   = "sold_out"
+)
+
+// ProcessedEvent records a Kafka event that has already been applied, so that
+// redelivered or republished events are not processed twice
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey;size:255"`
+	Topic       string    `gorm:"size:255;not null"`
+	Partition   int       `gorm:"not null"`
+	Offset      int64     `gorm:"not null"`
+	ProcessedAt time.Time `gorm:"not null;index"`
 }
 
 // FeedbackRequest represents a request to add feedback for an order
@@ -50,13 +112,34 @@ type FeedbackUpdateRequest struct {
 	Comment string `json:"comment"`
 }
 
-// APIResponse represents a generic API response
-type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+// FeedbackStats summarizes all feedback, or that of a restaurant
+type FeedbackStats struct {
+	TotalFeedback int64         `json:"total_feedback"`
+	AverageRating float64       `json:"average_rating"`
+	RatingCounts  []RatingCount `json:"rating_counts"`
+}
+
+// RestaurantFeedbackStats summarizes the feedback on the orders of a
+// restaurant
+type RestaurantFeedbackStats struct {
+	RestaurantID  uint    `json:"restaurant_id"`
+	TotalFeedback int64   `json:"total_feedback"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+// RatingCount is the number of feedback entries with a given rating
+type RatingCount struct {
+	Rating int   `json:"rating"`
+	Count  int64 `json:"count"`
 }
 
+// The response envelope is shared by the services
+type (
+	APIResponse = response.APIResponse
+	ErrorDetail = response.ErrorDetail
+	FieldError  = response.FieldError
+)
+
 // LoginRequest represents login credentials
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -70,12 +153,14 @@ type LoginResponse struct {
 
 // OrderEvent represents an order event received from Kafka
 type OrderEvent struct {
-	OrderID    int     `json:"order_id"`
-	UserID     int     `json:"user_id"`
-	TotalPrice float64 `json:"total_price"`
-	Status     string  `json:"status"`
-	Items      []Item  `json:"items"`
-	Timestamp  int64   `json:"timestamp"`
+	EventID      string  `json:"event_id"`
+	OrderID      int     `json:"order_id"`
+	RestaurantID int     `json:"restaurant_id"` // Zero in events that predate restaurants
+	UserID       int     `json:"user_id"`
+	TotalPrice   float64 `json:"total_price"`
+	Status       string  `json:"status"`
+	Items        []Item  `json:"items"`
+	Timestamp    int64   `json:"timestamp"`
 }
 
 // Item represents an item in an order event
@@ -84,3 +169,25 @@ type Item struct {
 	Name       string `json:"name"`
 	Quantity   int    `json:"quantity"`
 }
+
+// Types of the inventory events about food items. Events about ingredients
+// are of other types.
+const (
+	EventMenuItemLowStock = "menu.item_low_stock"
+	EventMenuItemSoldOut  = "menu.item_sold_out"
+	EventMenuItemRestored = "menu.item_restored"
+)
+
+// InventoryEvent represents an inventory event received from Kafka
+type InventoryEvent struct {
+	EventID      string  `json:"event_id"`
+	Type         string  `json:"type"`
+	AlertID      int64   `json:"alert_id"` // Grows with each alert raised, so it orders the events
+	RestaurantID int     `json:"restaurant_id"`
+	FoodItemID   int     `json:"food_item_id"` // Zero in events about ingredients
+	VariantID    int     `json:"variant_id"`
+	Name         string  `json:"name"`
+	Variant      string  `json:"variant"`
+	Quantity     float64 `json:"quantity"`
+	Timestamp    int64   `json:"timestamp"`
+}
@@ -0,0 +1,334 @@
+// Package memory implements the repositories in memory, so that handlers can
+// be exercised without a database
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/user_feedback_service/internal/models"
+	"github.com/user_feedback_service/internal/repository"
+)
+
+// Store holds the data shared by the in-memory repositories
+type Store struct {
+	mu        sync.Mutex
+	txMu      sync.Mutex
+	users     map[uint]models.User
+	orders    map[uint]models.Order
+	feedbacks map[uint]models.Feedback
+	menu      map[menuKey]models.MenuItemStatus
+	nextID    uint
+}
+
+// menuKey identifies a food item, or one of its variants, in the menu read
+// model
+type menuKey struct{ restaurantID, foodItemID, variantID uint }
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		users:     make(map[uint]models.User),
+		orders:    make(map[uint]models.Order),
+		feedbacks: make(map[uint]models.Feedback),
+		menu:      make(map[menuKey]models.MenuItemStatus),
+	}
+}
+
+// AddUser inserts a user, assigning an ID if it has none
+func (s *Store) AddUser(user models.User) models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user.ID == 0 {
+		user.ID = s.newID()
+	}
+	s.users[user.ID] = user
+	return user
+}
+
+// AddOrder inserts an order into the read model, as the Kafka consumer
+// would
+func (s *Store) AddOrder(order models.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.orders[order.ID] = order
+}
+
+// AddMenuItemStatus inserts or replaces the status of a menu item in the
+// read model, as the Kafka consumer would
+func (s *Store) AddMenuItemStatus(status models.MenuItemStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.menu[menuKey{status.RestaurantID, status.FoodItemID, status.VariantID}] = status
+}
+
+// Repos returns repositories backed by the store
+func (s *Store) Repos() repository.Repos {
+	return repository.Repos{
+		Users:    &UserRepo{s: s},
+		Orders:   &OrderRepo{s: s},
+		Menu:     &MenuRepo{s: s},
+		Feedback: &FeedbackRepo{s: s},
+	}
+}
+
+// Do runs fn against the store, restoring the previous contents if it
+// returns an error. Units of work are serialized with each other, but
+// statements outside a unit of work may observe uncommitted changes.
+func (s *Store) Do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	s.txMu.Lock()
+	defer s.txMu.Unlock()
+
+	s.mu.Lock()
+	snapshot := s.clone()
+	s.mu.Unlock()
+
+	if err := fn(s.Repos()); err != nil {
+		s.mu.Lock()
+		s.users, s.orders, s.feedbacks, s.menu, s.nextID = snapshot.users, snapshot.orders, snapshot.feedbacks, snapshot.menu, snapshot.nextID
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// newID returns the next ID. The caller must hold mu.
+func (s *Store) newID() uint {
+	s.nextID++
+	return s.nextID
+}
+
+// clone copies the store contents. The caller must hold mu.
+func (s *Store) clone() *Store {
+	c := NewStore()
+	for id, user := range s.users {
+		c.users[id] = user
+	}
+	for id, order := range s.orders {
+		order.Items = append([]models.OrderItem(nil), order.Items...)
+		c.orders[id] = order
+	}
+	for id, feedback := range s.feedbacks {
+		c.feedbacks[id] = feedback
+	}
+	for key, status := range s.menu {
+		c.menu[key] = status
+	}
+	c.nextID = s.nextID
+	return c
+}
+
+// UserRepo is an in-memory repository.UserRepo
+type UserRepo struct {
+	s *Store
+}
+
+// GetByUsername returns the user with the given username
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, user := range r.s.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+// OrderRepo is an in-memory repository.OrderRepo
+type OrderRepo struct {
+	s *Store
+}
+
+// Get returns the order with the given ID
+func (r *OrderRepo) Get(ctx context.Context, id uint) (models.Order, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	order, ok := r.s.orders[id]
+	if !ok {
+		return models.Order{}, repository.ErrNotFound
+	}
+	return order, nil
+}
+
+// MenuRepo is an in-memory repository.MenuRepo
+type MenuRepo struct {
+	s *Store
+}
+
+// Availability returns the statuses of the menu items of a restaurant
+func (r *MenuRepo) Availability(ctx context.Context, restaurantID uint) ([]models.MenuItemStatus, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	statuses := make([]models.MenuItemStatus, 0)
+	for key, status := range r.s.menu {
+		if key.restaurantID == restaurantID {
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].FoodItemID != statuses[j].FoodItemID {
+			return statuses[i].FoodItemID < statuses[j].FoodItemID
+		}
+		return statuses[i].VariantID < statuses[j].VariantID
+	})
+	return statuses, nil
+}
+
+// FeedbackRepo is an in-memory repository.FeedbackRepo. Deleted feedback is
+// removed rather than soft deleted.
+type FeedbackRepo struct {
+	s *Store
+}
+
+// ListByUser returns all feedback given by a user ordered by ID
+func (r *FeedbackRepo) ListByUser(ctx context.Context, userID uint) ([]models.Feedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	var feedbacks []models.Feedback
+	for _, feedback := range r.s.feedbacks {
+		if feedback.UserID == userID {
+			feedbacks = append(feedbacks, feedback)
+		}
+	}
+	sort.Slice(feedbacks, func(i, j int) bool {
+		return feedbacks[i].ID < feedbacks[j].ID
+	})
+	return feedbacks, nil
+}
+
+// GetForUser returns the feedback only if it belongs to userID
+func (r *FeedbackRepo) GetForUser(ctx context.Context, id, userID uint) (models.Feedback, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	feedback, ok := r.s.feedbacks[id]
+	if !ok || feedback.UserID != userID {
+		return models.Feedback{}, repository.ErrNotFound
+	}
+	return feedback, nil
+}
+
+// ExistsForOrder reports whether the user already rated the order
+func (r *FeedbackRepo) ExistsForOrder(ctx context.Context, orderID, userID uint) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, feedback := range r.s.feedbacks {
+		if feedback.OrderID == orderID && feedback.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Create inserts the feedback, setting its ID and timestamps
+func (r *FeedbackRepo) Create(ctx context.Context, feedback *models.Feedback) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, stored := range r.s.feedbacks {
+		if stored.OrderID == feedback.OrderID && stored.UserID == feedback.UserID {
+			return repository.ErrDuplicate
+		}
+	}
+
+	now := time.Now()
+	feedback.ID = r.s.newID()
+	feedback.CreatedAt = now
+	feedback.UpdatedAt = now
+	r.s.feedbacks[feedback.ID] = *feedback
+	return nil
+}
+
+// Update saves the rating and comment of the feedback
+func (r *FeedbackRepo) Update(ctx context.Context, feedback *models.Feedback) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stored, ok := r.s.feedbacks[feedback.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Rating = feedback.Rating
+	stored.Comment = feedback.Comment
+	stored.UpdatedAt = time.Now()
+	r.s.feedbacks[feedback.ID] = stored
+	*feedback = stored
+	return nil
+}
+
+// Delete removes the feedback
+func (r *FeedbackRepo) Delete(ctx context.Context, id uint) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.feedbacks[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(r.s.feedbacks, id)
+	return nil
+}
+
+// Stats summarizes the stored feedback of a restaurant, or all of it when
+// restaurantID is 0
+func (r *FeedbackRepo) Stats(ctx context.Context, restaurantID uint) (models.FeedbackStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	stats := models.FeedbackStats{RatingCounts: make([]models.RatingCount, 5)}
+	for i := range stats.RatingCounts {
+		stats.RatingCounts[i].Rating = i + 1
+	}
+
+	var total int
+	for _, feedback := range r.s.feedbacks {
+		if restaurantID != 0 && feedback.RestaurantID != restaurantID {
+			continue
+		}
+		stats.TotalFeedback++
+		total += int(feedback.Rating)
+		if feedback.Rating >= 1 && feedback.Rating <= 5 {
+			stats.RatingCounts[feedback.Rating-1].Count++
+		}
+	}
+	if stats.TotalFeedback > 0 {
+		stats.AverageRating = float64(total) / float64(stats.TotalFeedback)
+	}
+	return stats, nil
+}
+
+// StatsByRestaurant summarizes the stored feedback of each restaurant
+func (r *FeedbackRepo) StatsByRestaurant(ctx context.Context) ([]models.RestaurantFeedbackStats, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	totals := make(map[uint]int)
+	byRestaurant := make(map[uint]*models.RestaurantFeedbackStats)
+	for _, feedback := range r.s.feedbacks {
+		stats, ok := byRestaurant[feedback.RestaurantID]
+		if !ok {
+			stats = &models.RestaurantFeedbackStats{RestaurantID: feedback.RestaurantID}
+			byRestaurant[feedback.RestaurantID] = stats
+		}
+		stats.TotalFeedback++
+		totals[feedback.RestaurantID] += int(feedback.Rating)
+	}
+
+	result := make([]models.RestaurantFeedbackStats, 0, len(byRestaurant))
+	for restaurantID, stats := range byRestaurant {
+		stats.AverageRating = float64(totals[restaurantID]) / float64(stats.TotalFeedback)
+		result = append(result, *stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RestaurantID < result[j].RestaurantID })
+	return result, nil
+}
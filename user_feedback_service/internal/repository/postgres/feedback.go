@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/user_feedback_service/internal/models"
+	"github.com/user_feedback_service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// FeedbackRepo stores feedback in the feedbacks table
+type FeedbackRepo struct {
+	conn
+}
+
+// ListByUser returns all feedback given by a user
+func (r *FeedbackRepo) ListByUser(ctx context.Context, userID uint) ([]models.Feedback, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var feedbacks []models.Feedback
+	err := db.Where("user_id = ?", userID).Find(&feedbacks).Error
+	return feedbacks, err
+}
+
+// GetForUser returns the feedback only if it belongs to userID
+func (r *FeedbackRepo) GetForUser(ctx context.Context, id, userID uint) (models.Feedback, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var feedback models.Feedback
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(&feedback).Error
+	return feedback, notFound(err)
+}
+
+// ExistsForOrder reports whether the user already rated the order
+func (r *FeedbackRepo) ExistsForOrder(ctx context.Context, orderID, userID uint) (bool, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := db.Model(&models.Feedback{}).
+		Where("order_id = ? AND user_id = ?", orderID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create inserts the feedback, setting its ID and timestamps. A concurrent
+// request rating the same order is caught by the unique index on the order
+// and user.
+func (r *FeedbackRepo) Create(ctx context.Context, feedback *models.Feedback) error {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	err := db.Create(feedback).Error
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return repository.ErrDuplicate
+	}
+	return err
+}
+
+// Update saves the rating and comment of the feedback
+func (r *FeedbackRepo) Update(ctx context.Context, feedback *models.Feedback) error {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result := db.Model(feedback).Select("rating", "comment").Updates(feedback)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Delete soft deletes the feedback
+func (r *FeedbackRepo) Delete(ctx context.Context, id uint) error {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result := db.Delete(&models.Feedback{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+// Stats returns the number of feedback entries, the average rating and the
+// count for each rating from 1 to 5, of a restaurant or of all restaurants
+// when restaurantID is 0
+func (r *FeedbackRepo) Stats(ctx context.Context, restaurantID uint) (models.FeedbackStats, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var stats models.FeedbackStats
+	feedbacks := func() *gorm.DB {
+		query := db.Model(&models.Feedback{})
+		if restaurantID != 0 {
+			query = query.Where("restaurant_id = ?", restaurantID)
+		}
+		return query
+	}
+
+	// Get total feedback count and average rating
+	err := feedbacks().
+		Select("COUNT(*), COALESCE(AVG(rating), 0)").
+		Row().Scan(&stats.TotalFeedback, &stats.AverageRating)
+	if err != nil {
+		return stats, err
+	}
+
+	// Get count by rating
+	var counts []models.RatingCount
+	err = feedbacks().
+		Select("rating, COUNT(*) AS count").
+		Group("rating").
+		Scan(&counts).Error
+	if err != nil {
+		return stats, err
+	}
+
+	stats.RatingCounts = make([]models.RatingCount, 5)
+	for i := range stats.RatingCounts {
+		stats.RatingCounts[i].Rating = i + 1
+	}
+	for _, count := range counts {
+		if count.Rating >= 1 && count.Rating <= 5 {
+			stats.RatingCounts[count.Rating-1].Count = count.Count
+		}
+	}
+	return stats, nil
+}
+
+// StatsByRestaurant returns the number of feedback entries and the average
+// rating of each restaurant that has feedback
+func (r *FeedbackRepo) StatsByRestaurant(ctx context.Context) ([]models.RestaurantFeedbackStats, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	stats := []models.RestaurantFeedbackStats{}
+	err := db.Model(&models.Feedback{}).
+		Select("restaurant_id, COUNT(*) AS total_feedback, AVG(rating) AS average_rating").
+		Group("restaurant_id").
+		Order("restaurant_id").
+		Scan(&stats).Error
+	return stats, err
+}
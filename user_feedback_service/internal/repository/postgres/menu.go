@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/user_feedback_service/internal/models"
+)
+
+// MenuRepo reads the menu item status read model
+type MenuRepo struct {
+	conn
+}
+
+// Availability returns the statuses of the menu items of a restaurant
+func (r *MenuRepo) Availability(ctx context.Context, restaurantID uint) ([]models.MenuItemStatus, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	statuses := make([]models.MenuItemStatus, 0)
+	err := db.Where("restaurant_id = ?", restaurantID).Order("food_item_id, variant_id").Find(&statuses).Error
+	return statuses, err
+}
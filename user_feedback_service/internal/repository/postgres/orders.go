@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/user_feedback_service/internal/models"
+	"github.com/user_feedback_service/internal/repository"
+)
+
+// OrderRepo reads the order read model
+type OrderRepo struct {
+	conn
+}
+
+// Get returns the order with the given ID
+func (r *OrderRepo) Get(ctx context.Context, id uint) (models.Order, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var order models.Order
+	result := db.Where("id = ?", id).Limit(1).Find(&order)
+	if result.Error != nil {
+		return order, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return order, repository.ErrNotFound
+	}
+	return order, nil
+}
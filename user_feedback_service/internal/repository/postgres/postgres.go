@@ -0,0 +1,75 @@
+// Package postgres implements the repositories on top of GORM
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/user_feedback_service/internal/repository"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the Postgres error code of a duplicate key
+const uniqueViolation = "23505"
+
+// conn is the connection or transaction a repository runs on, together with
+// the timeout applied to each of its calls
+type conn struct {
+	db      *gorm.DB
+	timeout time.Duration
+}
+
+// withTimeout bounds a single repository call
+func (c conn) withTimeout(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	return c.db.WithContext(ctx), cancel
+}
+
+// NewRepos returns repositories that run each statement on its own, giving
+// up on any call that takes longer than queryTimeout
+func NewRepos(db *gorm.DB, queryTimeout time.Duration) repository.Repos {
+	return reposFor(conn{db: db, timeout: queryTimeout})
+}
+
+func reposFor(c conn) repository.Repos {
+	return repository.Repos{
+		Users:    &UserRepo{c},
+		Orders:   &OrderRepo{c},
+		Menu:     &MenuRepo{c},
+		Feedback: &FeedbackRepo{c},
+	}
+}
+
+// UnitOfWork runs functions inside a database transaction
+type UnitOfWork struct {
+	db           *gorm.DB
+	queryTimeout time.Duration
+	txTimeout    time.Duration
+}
+
+// NewUnitOfWork creates a unit of work backed by db. Each repository call is
+// bounded by queryTimeout and the whole transaction by txTimeout.
+func NewUnitOfWork(db *gorm.DB, queryTimeout, txTimeout time.Duration) *UnitOfWork {
+	return &UnitOfWork{db: db, queryTimeout: queryTimeout, txTimeout: txTimeout}
+}
+
+// Do runs fn with repositories bound to a new transaction. The transaction
+// is rolled back if ctx is cancelled, for example when the client
+// disconnects.
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos repository.Repos) error) error {
+	ctx, cancel := context.WithTimeout(ctx, u.txTimeout)
+	defer cancel()
+
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(reposFor(conn{db: tx, timeout: u.queryTimeout}))
+	})
+}
+
+// notFound translates gorm.ErrRecordNotFound into repository.ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository.ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/user_feedback_service/internal/models"
+)
+
+// UserRepo reads users
+type UserRepo struct {
+	conn
+}
+
+// GetByUsername returns the user with the given username
+func (r *UserRepo) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	db, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var user models.User
+	err := db.Where("username = ?", username).First(&user).Error
+	return user, notFound(err)
+}
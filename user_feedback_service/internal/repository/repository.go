@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/user_feedback_service/internal/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("record not found")
+
+	// ErrDuplicate is returned when a user would rate an order they already
+	// rated
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+// UserRepo reads users
+type UserRepo interface {
+	GetByUsername(ctx context.Context, username string) (models.User, error)
+}
+
+// OrderRepo reads the order read model maintained from Kafka events
+type OrderRepo interface {
+	Get(ctx context.Context, id uint) (models.Order, error)
+}
+
+// MenuRepo reads the menu item status read model maintained from Kafka
+// events
+type MenuRepo interface {
+	// Availability returns the statuses of the food items and variants of a
+	// restaurant ordered by food item and variant
+	Availability(ctx context.Context, restaurantID uint) ([]models.MenuItemStatus, error)
+}
+
+// FeedbackRepo stores feedback
+type FeedbackRepo interface {
+	ListByUser(ctx context.Context, userID uint) ([]models.Feedback, error)
+	// GetForUser returns the feedback only if it belongs to userID
+	GetForUser(ctx context.Context, id, userID uint) (models.Feedback, error)
+	// ExistsForOrder reports whether the user already rated the order
+	ExistsForOrder(ctx context.Context, orderID, userID uint) (bool, error)
+	// Create inserts the feedback, returning ErrDuplicate if the user
+	// already rated the order
+	Create(ctx context.Context, feedback *models.Feedback) error
+	// Update saves the rating and comment of the feedback
+	Update(ctx context.Context, feedback *models.Feedback) error
+	Delete(ctx context.Context, id uint) error
+	// Stats summarizes the feedback on the orders of a restaurant, or all
+	// feedback when restaurantID is 0
+	Stats(ctx context.Context, restaurantID uint) (models.FeedbackStats, error)
+	// StatsByRestaurant summarizes the feedback of each restaurant that has
+	// any, ordered by restaurant ID
+	StatsByRestaurant(ctx context.Context) ([]models.RestaurantFeedbackStats, error)
+}
+
+// Repos groups the repositories that share a connection or transaction
+type Repos struct {
+	Users    UserRepo
+	Orders   OrderRepo
+	Menu     MenuRepo
+	Feedback FeedbackRepo
+}
+
+// UnitOfWork runs a function against repositories bound to a single
+// transaction. The transaction is committed if fn returns nil and rolled
+// back otherwise.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repos) error) error
+}